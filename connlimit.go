@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net"
+	"sync"
+)
+
+// limitListener returns a listener that accepts at most n simultaneous connections
+// Once the limit is reached Accept blocks until an accepted connection is closed,
+// so excess clients wait in the kernel's accept queue instead of being served
+func limitListener(ln net.Listener, n int) net.Listener {
+	return &connLimitListener{
+		Listener: ln,
+		sem:      make(chan struct{}, n),
+		done:     make(chan struct{}),
+	}
+}
+
+// connLimitListener caps open connections with a semaphore released when each connection closes
+type connLimitListener struct {
+	net.Listener
+	sem       chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *connLimitListener) Accept() (net.Conn, error) {
+	// Wait for a free slot, giving up once the listener is closed
+	select {
+	case l.sem <- struct{}{}:
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		<-l.sem
+		return nil, err
+	}
+	return &limitedConn{Conn: conn, release: func() { <-l.sem }}, nil
+}
+
+func (l *connLimitListener) Close() error {
+	err := l.Listener.Close()
+	l.closeOnce.Do(func() { close(l.done) })
+	return err
+}
+
+// limitedConn frees its listener slot the first time it is closed
+type limitedConn struct {
+	net.Conn
+	release     func()
+	releaseOnce sync.Once
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+	return err
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestLimitListener(t *testing.T) {
+	const limit = 2
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ln := limitListener(inner, limit)
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, limit+1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	// Open one connection more than the limit
+	for i := 0; i < limit+1; i++ {
+		client, err := net.Dial("tcp", inner.Addr().String())
+		if err != nil {
+			t.Fatalf("Failed to dial connection %d: %v", i+1, err)
+		}
+		defer client.Close()
+	}
+
+	var conns []net.Conn
+	for i := 0; i < limit; i++ {
+		select {
+		case conn := <-accepted:
+			conns = append(conns, conn)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected connection %d to be accepted", i+1)
+		}
+	}
+
+	select {
+	case <-accepted:
+		t.Fatalf("Expected at most %d connections to be accepted", limit)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Closing an accepted connection frees a slot for the waiting one
+	conns[0].Close()
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the waiting connection to be accepted after a slot was freed")
+	}
+	conns[1].Close()
+}
+
+func TestLimitListenerClose(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ln := limitListener(inner, 1)
+
+	client, err := net.Dial("tcp", inner.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("Failed to accept: %v", err)
+	}
+	defer conn.Close()
+
+	// An Accept waiting for a slot returns once the listener is closed
+	result := make(chan error, 1)
+	go func() {
+		_, err := ln.Accept()
+		result <- err
+	}()
+	ln.Close()
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Error("Expected Accept to fail after Close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Accept to return after Close")
+	}
+}
@@ -8,3 +8,5 @@ require (
 )
 
 require github.com/rs/cors v1.11.1
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,26 @@
+package config
+
+import "sync/atomic"
+
+// AtomicConfig holds the active configuration and allows it to be swapped at runtime
+// Readers always see a complete configuration; a Store never mutates a loaded value
+type AtomicConfig struct {
+	current atomic.Pointer[Config]
+}
+
+// NewAtomicConfig creates an AtomicConfig holding cfg
+func NewAtomicConfig(cfg *Config) *AtomicConfig {
+	a := &AtomicConfig{}
+	a.Store(cfg)
+	return a
+}
+
+// Load returns the active configuration
+func (a *AtomicConfig) Load() *Config {
+	return a.current.Load()
+}
+
+// Store replaces the active configuration
+func (a *AtomicConfig) Store(cfg *Config) {
+	a.current.Store(cfg)
+}
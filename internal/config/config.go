@@ -1,7 +1,12 @@
 package config
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"strconv"
 	"strings"
@@ -15,60 +20,291 @@ type Config struct {
 	Server ServerConfig `json:"server"`
 }
 
+// MergeStrategy controls how list values are combined by MergeConfigs
+type MergeStrategy string
+
+const (
+	// MergeReplace replaces the base list with the override list when it is non-empty
+	MergeReplace MergeStrategy = "replace"
+	// MergeUnion appends override entries that are not already present in the base list
+	MergeUnion MergeStrategy = "union"
+)
+
+// Shutdown phases that can be ordered through ServerConfig.ShutdownOrder
+const (
+	ShutdownPhaseStopAccepting = "stop_accepting" // Close listeners so no new connections are accepted
+	ShutdownPhaseDrain         = "drain"          // Wait for in-flight requests to finish
+	ShutdownPhaseHooks         = "hooks"          // Run registered shutdown hooks
+	ShutdownPhaseClose         = "close"          // Force close any remaining connections
+)
+
 // ServerConfig represents the HTTP server configuration
 type ServerConfig struct {
 	Port            int      `json:"port"`
-	ShutdownTimeout int      // Hardcoded timeout value, not configurable via JSON
-	ReadTimeout     int      // Hardcoded timeout value, not configurable via JSON
-	WriteTimeout    int      // Hardcoded timeout value, not configurable via JSON
+	ShutdownTimeout int      `json:"shutdown_timeout_seconds"` // Seconds graceful shutdown may take, 30 when unset
+	ReadTimeout     int      `json:"read_timeout_seconds"`     // Seconds allowed for reading a request, 10 when unset
+	WriteTimeout    int      `json:"write_timeout_seconds"`    // Seconds allowed for writing a response, 10 when unset
+	IdleTimeout     int      `json:"idle_timeout_seconds"`     // Seconds an idle keep-alive connection is kept open, 60 when unset
 	AllowedOrigins  []string `json:"allowed_origins"`
-	AllowedMethods  []string // Hardcoded HTTP methods, not configurable via JSON
+	AllowedMethods  []string // Hardcoded HTTP methods, only merged when MethodsMergeStrategy is set
 	EnableLogging   bool     `json:"enable_logging"`
+
+	TotalRequestBudget     int                      `json:"total_request_budget_seconds"` // Overall deadline in seconds across all middleware, 0 disables it
+	HandlerTimeout         int                      `json:"handler_timeout_seconds"`      // Deadline for producing a response, after which WriteTimeout only covers sending it, 0 keeps WriteTimeout covering both
+	MethodsMergeStrategy   MergeStrategy            `json:"methods_merge_strategy"`       // How override AllowedMethods are merged, empty keeps base methods
+	ServeFavicon           bool                     `json:"serve_favicon"`                // Serve the embedded /favicon.ico
+	EnableEventStream      bool                     `json:"enable_event_stream"`          // Stream the server time as server-sent events at /events
+	ServeRobotsTxt         bool                     `json:"serve_robots_txt"`             // Serve /robots.txt
+	RobotsTxt              string                   `json:"robots_txt"`                   // Body of /robots.txt, empty disallows all crawlers
+	ShutdownOrder          []string                 `json:"shutdown_order"`               // Order of shutdown phases, see ShutdownPhase constants, or named subsystems such as "websockets"
+	ShutdownPhaseTimeouts  map[string]int           `json:"shutdown_phase_timeouts"`      // Seconds each named phase may take, a slice of ShutdownTimeout; phases without one share the rest
+	RateLimitRequests      int                      `json:"rate_limit_requests"`          // Requests allowed per client per window, 0 disables rate limiting
+	RateLimitWindow        int                      `json:"rate_limit_window_seconds"`    // Length of the rate limit window in seconds
+	MaxMultipartParts      int                      `json:"max_multipart_parts"`          // Maximum parts accepted in a multipart form
+	MaxMultipartBytes      int64                    `json:"max_multipart_bytes"`          // Maximum size in bytes of a multipart form body
+	MaxValidatedBodyBytes  int64                    `json:"max_validated_body_bytes"`     // Largest declared body the contentlength middleware buffers, larger ones get 413
+	AdminToken             string                   `json:"admin_token"`                  // Bearer token for /admin endpoints, empty disables them
+	FeatureFlags           map[string]bool          `json:"feature_flags"`                // Initial state of runtime feature flags
+	EnableCompression      bool                     `json:"enable_compression"`           // Gzip-compress responses for clients that accept it
+	CompressionLevel       int                      `json:"compression_level"`            // Gzip level from 1 (fastest) to 9 (smallest), invalid values use the default
+	StaticDir              string                   `json:"static_dir"`                   // Directory served under /static/, empty disables static files
+	EnabledMiddleware      []string                 `json:"enabled_middleware"`           // Optional middleware enabled by name, e.g. "recover", "requestid", "compress"
+	TraceSampleRate        float64                  `json:"trace_sample_rate"`            // Fraction (0 to 1) of requests sampled by the "trace" middleware
+	ArtificialLatencyMs    int                      `json:"artificial_latency_ms"`        // Delay added to every request by the "latency" middleware, for development only
+	MinTLSVersion          string                   `json:"min_tls_version"`              // Minimum TLS version ("1.2" or "1.3"), invalid values use 1.2
+	MemoryLimitBytes       uint64                   `json:"memory_limit_bytes"`           // Heap usage above which requests are shed with 503, 0 disables it
+	HealthFailsOnShutdown  bool                     `json:"health_fails_on_shutdown"`     // Make /health return 503 once graceful shutdown begins
+	HealthCheckTimeoutMs   int                      `json:"health_check_timeout_ms"`      // Deadline shared by the dependency checks run by /health, slower checks are reported timed out, 0 uses 5 seconds
+	EnableRequestStats     bool                     `json:"enable_request_stats"`         // Track per-route latency and expose it at /debug/requests
+	EnableConnectionStats  bool                     `json:"enable_connection_stats"`      // Track open connections by state and expose them at /debug/connections
+	LatencyBucketsMs       []int                    `json:"latency_buckets_ms"`           // Upper bounds in milliseconds of the request duration histogram at /metrics, e.g. [10, 50, 100, 500, 1000], empty disables it
+	ReadOnly               bool                     `json:"read_only"`                    // Reject POST, PUT, PATCH and DELETE with 503 for maintenance, reloadable and toggled at /admin/readonly
+	WorkerPoolSize         int                      `json:"worker_pool_size"`             // Maximum concurrent jobs submitted to the handler worker pool, 0 is unbounded and -1 uses GOMAXPROCS
+	AccessLogFormat        string                   `json:"access_log_format"`            // Access log line with placeholders like %method %path %status %duration %ip, empty keeps the default format
+	OptionsStatus          int                      `json:"options_status"`               // Status (200 or 204) for OPTIONS requests without an Origin header, 0 passes them to routes
+	StartupBanner          bool                     `json:"startup_banner"`               // Log a summary of the effective settings at startup
+	RetryAfterSeconds      int                      `json:"retry_after_seconds"`          // Retry-After sent with 503s while shedding load or shutting down
+	RouteCORSOrigins       map[string][]string      `json:"route_cors_origins"`           // Allowed origins per route path, overriding AllowedOrigins for that route
+	PathRewrites           map[string]string        `json:"path_rewrites"`                // Old path prefix, or regular expression starting with "^", to its replacement, applied before routing
+	ProxyRoutes            map[string]string        `json:"proxy_routes"`                 // Route path to the upstream URL requests are forwarded to, gateway errors carry Retry-After
+	JSONBOM                bool                     `json:"json_bom"`                     // Prepend a UTF-8 BOM to JSON responses for legacy clients, off by default
+	JSONExplicitNulls      bool                     `json:"json_explicit_nulls"`          // Write empty response message and data as null instead of omitting them
+	JSONPretty             bool                     `json:"json_pretty"`                  // Pretty-print JSON responses, compact by default
+	JSONIndent             string                   `json:"json_indent"`                  // Indent per level when pretty-printing, e.g. "\t" or four spaces, empty uses two spaces
+	HealthMethods          []string                 `json:"health_methods"`               // Methods accepted by /health (HEAD is implied by GET), empty accepts all
+	GlobalAllowedMethods   []string                 `json:"global_allowed_methods"`       // Methods accepted on any route, others get 405 before routing (HEAD is implied by GET), empty accepts all
+	GlobalDeniedMethods    []string                 `json:"global_denied_methods"`        // Methods rejected with 405 on every route, e.g. TRACE or CONNECT
+	StripResponseHeaders   []string                 `json:"strip_response_headers"`       // Response headers removed before they are sent, e.g. X-Powered-By or Server
+	MaxConnections         int                      `json:"max_connections"`              // Maximum open client connections, further connections wait to be accepted, 0 is unlimited
+	MaxQueryParams         int                      `json:"max_query_params"`             // Maximum query parameters per request, requests with more get 400, 0 is unlimited
+	SlowStartSeconds       int                      `json:"slow_start_seconds"`           // Ramp connection acceptance up to unthrottled over this many seconds after startup, 0 disables it
+	SlowStartRate          int                      `json:"slow_start_rate"`              // Connections accepted per second when slow start begins, 0 uses a default
+	CanonicalHost          string                   `json:"canonical_host"`               // Host other hosts are redirected to with a 301, e.g. www.example.com, empty disables it
+	MinDrainSeconds        int                      `json:"min_drain_seconds"`            // Keep serving at least this long after a shutdown signal before stopping, 0 stops right away
+	DrainKeepAlives        bool                     `json:"drain_keep_alives"`            // Disable keep-alives once shutdown begins, closing idle connections and answering with Connection: close
+	MaxResponseHeaders     int                      `json:"max_response_headers"`         // Maximum response header fields, extra fields are dropped and logged, 0 is unlimited
+	MaxResponseHeaderBytes int                      `json:"max_response_header_bytes"`    // Maximum total size of response header names and values, 0 is unlimited
+	TrustForwardedHeaders  bool                     `json:"trust_forwarded_headers"`      // Build external URLs from X-Forwarded-Proto and X-Forwarded-Host, only enable behind a proxy that sets them
+	HTTPPort               int                      `json:"http_port"`                    // Port for plain HTTP when HTTPS is enabled, 0 uses Port
+	HTTPSPort              int                      `json:"https_port"`                   // Port for HTTPS alongside plain HTTP, 0 disables HTTPS
+	TLSCertFile            string                   `json:"tls_cert_file"`                // PEM certificate served on HTTPSPort, or on Port when HTTPSPort is 0
+	TLSKeyFile             string                   `json:"tls_key_file"`                 // PEM private key for TLSCertFile
+	RedirectHTTPToHTTPS    bool                     `json:"redirect_http_to_https"`       // Redirect plain HTTP requests to HTTPSPort instead of serving them
+	InstanceID             string                   `json:"instance_id"`                  // Instance name sent in X-Served-By by the "servedby" middleware, empty uses the hostname and a random suffix
+	Timezone               string                   `json:"timezone"`                     // IANA time zone for log and response timestamps, e.g. "Europe/Berlin", empty uses local time
+	JSONTimeLayout         string                   `json:"json_time_layout"`             // Layout for handlers.Time fields in request bodies, e.g. "2006-01-02 15:04", or "unix" for epoch seconds, empty uses RFC 3339
+	APIKeys                map[string]string        `json:"api_keys"`                     // API key to rate limit tier, sent in the X-API-Key header
+	RateLimitTiers         map[string]RateLimitTier `json:"rate_limit_tiers"`             // Rate limit for each API key tier
+}
+
+// RateLimitTier is the rate limit applied to every API key on a tier
+type RateLimitTier struct {
+	Requests      int `json:"requests"`       // Requests allowed per key per window, 0 disables limiting for the tier
+	WindowSeconds int `json:"window_seconds"` // Length of the window in seconds
 }
 
 // GetDefaultConfig returns the default configuration with sensible defaults
 func GetDefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:            8080,
-			ShutdownTimeout: 30,
-			ReadTimeout:     10,
-			WriteTimeout:    10,
-			AllowedOrigins:  []string{"*"},
-			AllowedMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			EnableLogging:   true,
+			Port:              8080,
+			ShutdownTimeout:   30,
+			ReadTimeout:       10,
+			WriteTimeout:      10,
+			IdleTimeout:       60,
+			AllowedOrigins:    []string{"*"},
+			AllowedMethods:    []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			EnableLogging:     true,
+			ServeFavicon:      true,
+			ServeRobotsTxt:    true,
+			StartupBanner:     true,
+			RetryAfterSeconds: 1,
+			MinTLSVersion:     "1.2",
+			ShutdownOrder: []string{
+				ShutdownPhaseStopAccepting,
+				ShutdownPhaseDrain,
+				ShutdownPhaseHooks,
+				ShutdownPhaseClose,
+			},
+			RateLimitWindow:       60,
+			MaxMultipartParts:     100,
+			MaxMultipartBytes:     10 << 20,
+			MaxValidatedBodyBytes: 10 << 20,
 		},
 	}
 }
 
-// LoadConfig loads configuration from a JSON file using goccy/go-json
+// LoadConfig loads configuration from a JSON or YAML file using goccy/go-json
+// The format follows the extension: .json, or .yaml and .yml, which use the same
+// field names; any other extension is an error. Gzipped files (a .gz extension or
+// gzip magic bytes) are decompressed first. JSON files are decoded as they are
+// read rather than loaded into memory up front.
+// Files with an older "version" are migrated to the current layout in memory,
+// logging each change so the file can be updated. Unknown fields are ignored
 func LoadConfig(path string) (*Config, error) {
+	return LoadConfigWithOptions(path, LoadOptions{})
+}
+
+// LoadOptions controls how LoadConfigWithOptions decodes a config file
+type LoadOptions struct {
+	DisallowUnknownFields bool // Reject keys that match no config field, so typos such as "prot" are caught
+}
+
+// LoadConfigWithOptions is LoadConfig with explicit decoding options
+func LoadConfigWithOptions(path string, opts LoadOptions) (*Config, error) {
+	format, err := configFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if file exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil, fmt.Errorf("config file does not exist: %s", path)
 	}
 
-	// Read the file
-	data, err := os.ReadFile(path)
+	file, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	defer file.Close()
+
+	// Decompress gzipped configs, detected by extension or magic bytes
+	buffered := bufio.NewReader(file)
+	source := &configReader{r: buffered, errPrefix: "failed to read config file"}
+	if magic, _ := buffered.Peek(len(gzipMagic)); strings.HasSuffix(path, ".gz") || bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress config file: %w", err)
+		}
+		defer gz.Close()
+		source = &configReader{r: gz, errPrefix: "failed to decompress config file", limit: maxConfigBytes}
+	}
+
+	// YAML is converted to JSON so both formats share the decoding below
+	var input io.Reader = source
+	if format == formatYAML {
+		data, err := yamlToJSON(source)
+		if err != nil {
+			if source.err != nil {
+				return nil, source.err
+			}
+			return nil, fmt.Errorf("failed to parse YAML config: %w", err)
+		}
+		input = bytes.NewReader(data)
+	}
 
-	// Parse JSON
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON config: %w", err)
+	// Parse on top of the defaults so omitted fields keep sensible values
+	decoder := json.NewDecoder(input)
+	if opts.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	doc, err := decodeConfigDocument(decoder, opts.DisallowUnknownFields)
+	if err != nil {
+		if source.err != nil {
+			return nil, source.err
+		}
+		return nil, fmt.Errorf("failed to parse %s config: %w", format, err)
+	}
+
+	// Reject anything but whitespace after the config object
+	var trailing json.RawMessage
+	if err := decoder.Decode(&trailing); err != io.EOF {
+		if source.err != nil {
+			return nil, source.err
+		}
+		return nil, fmt.Errorf("failed to parse %s config: unexpected trailing data", format)
 	}
 
-	return &config, nil
+	// Upgrade files written for an older schema version
+	from := doc.Version
+	changes, err := doc.migrate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate config file: %w", err)
+	}
+	if len(changes) > 0 {
+		log.Printf("Migrated config file %s from version %d to %d: %s", path, from, ConfigVersion, strings.Join(changes, "; "))
+	}
+
+	return doc.Config, nil
+}
+
+// gzipMagic is the header every gzip stream starts with
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// maxJSONIndent is the widest indent accepted for pretty-printed JSON responses
+const maxJSONIndent = 8
+
+// maxConfigBytes caps the decompressed size of a gzipped config to guard against gzip bombs
+const maxConfigBytes = 16 << 20
+
+// configReader reads a config stream, keeping the first read error so LoadConfig can
+// report it instead of the JSON error it causes. A non-zero limit caps the bytes read
+type configReader struct {
+	r         io.Reader
+	errPrefix string
+	limit     int64
+	read      int64
+	err       error
+}
+
+func (cr *configReader) Read(p []byte) (int, error) {
+	if cr.err != nil {
+		return 0, cr.err
+	}
+
+	n, err := cr.r.Read(p)
+	cr.read += int64(n)
+	if cr.limit > 0 && cr.read > cr.limit {
+		cr.err = fmt.Errorf("decompressed config file exceeds %d bytes", cr.limit)
+		return 0, cr.err
+	}
+	if err != nil && err != io.EOF {
+		cr.err = fmt.Errorf("%s: %w", cr.errPrefix, err)
+		return n, cr.err
+	}
+	return n, err
 }
 
-// WriteConfig writes configuration to a JSON file using goccy/go-json
+// WriteConfig writes configuration to a JSON file using goccy/go-json, or to a
+// YAML file when path ends in .yaml or .yml. Other extensions are an error
 func WriteConfig(path string, config *Config) error {
+	if strings.HasSuffix(path, ".gz") {
+		return fmt.Errorf("writing gzipped config files is not supported: %s", path)
+	}
+	format, err := configFormat(path)
+	if err != nil {
+		return err
+	}
+
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config to JSON: %w", err)
 	}
+	if format == formatYAML {
+		if data, err = jsonToYAML(data); err != nil {
+			return fmt.Errorf("failed to marshal config to YAML: %w", err)
+		}
+	}
 
 	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
@@ -78,15 +314,47 @@ func WriteConfig(path string, config *Config) error {
 }
 
 // LoadEnvConfig loads configuration from .env files using godotenv
+// A variable that is present but empty (e.g. "ENABLE_LOGGING=") is treated as unset
+// and keeps its default value; this applies to every supported variable. To disable
+// logging set ENABLE_LOGGING=false explicitly. Bare flags without "=" are not
+// supported, godotenv rejects such files and the defaults are used instead.
+// Any JSON-configurable field can also be set with a nested variable whose
+// segments are separated by "__", e.g. SERVER__STATIC_DIR=public
 func LoadEnvConfig() (*Config, error) {
+	return ApplyEnvConfig(GetDefaultConfig())
+}
+
+// ApplyEnvConfig returns a copy of base with the variables set in the .env file applied
+// Only variables present in the file are changed, so values loaded from earlier
+// sources such as a JSON file are preserved
+func ApplyEnvConfig(base *Config) (*Config, error) {
 	// Load .env file if it exists (ignore error if file doesn't exist)
 	envVars, err := godotenv.Read()
 	if err != nil {
-		// If .env file doesn't exist, return empty config (will use defaults)
-		return GetDefaultConfig(), nil
+		// If .env file doesn't exist, return the base config unchanged
+		config := *base
+		return &config, nil
 	}
 
-	config := GetDefaultConfig()
+	return applyEnvVars(base, envVars), nil
+}
+
+// ApplyProcessEnvConfig returns a copy of base with the supported variables from the
+// process environment applied. It accepts the same variables as the .env file
+func ApplyProcessEnvConfig(base *Config) *Config {
+	envVars := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if key, value, found := strings.Cut(entry, "="); found {
+			envVars[key] = value
+		}
+	}
+
+	return applyEnvVars(base, envVars)
+}
+
+// applyEnvVars returns a copy of base with the supported variables in envVars applied
+func applyEnvVars(base *Config, envVars map[string]string) *Config {
+	config := *base
 
 	// Parse PORT
 	if portStr, exists := envVars["PORT"]; exists && portStr != "" {
@@ -95,6 +363,34 @@ func LoadEnvConfig() (*Config, error) {
 		}
 	}
 
+	// Parse SHUTDOWN_TIMEOUT
+	if timeoutStr, exists := envVars["SHUTDOWN_TIMEOUT"]; exists && timeoutStr != "" {
+		if timeout, err := strconv.Atoi(timeoutStr); err == nil && timeout > 0 {
+			config.Server.ShutdownTimeout = timeout
+		}
+	}
+
+	// Parse READ_TIMEOUT
+	if timeoutStr, exists := envVars["READ_TIMEOUT"]; exists && timeoutStr != "" {
+		if timeout, err := strconv.Atoi(timeoutStr); err == nil && timeout >= 0 {
+			config.Server.ReadTimeout = timeout
+		}
+	}
+
+	// Parse WRITE_TIMEOUT
+	if timeoutStr, exists := envVars["WRITE_TIMEOUT"]; exists && timeoutStr != "" {
+		if timeout, err := strconv.Atoi(timeoutStr); err == nil && timeout >= 0 {
+			config.Server.WriteTimeout = timeout
+		}
+	}
+
+	// Parse IDLE_TIMEOUT
+	if timeoutStr, exists := envVars["IDLE_TIMEOUT"]; exists && timeoutStr != "" {
+		if timeout, err := strconv.Atoi(timeoutStr); err == nil && timeout >= 0 {
+			config.Server.IdleTimeout = timeout
+		}
+	}
+
 	// Parse ALLOWED_ORIGINS
 	if originsStr, exists := envVars["ALLOWED_ORIGINS"]; exists && originsStr != "" {
 		origins := strings.Split(originsStr, ",")
@@ -104,6 +400,25 @@ func LoadEnvConfig() (*Config, error) {
 		config.Server.AllowedOrigins = origins
 	}
 
+	// Parse TOTAL_REQUEST_BUDGET
+	if budgetStr, exists := envVars["TOTAL_REQUEST_BUDGET"]; exists && budgetStr != "" {
+		if budget, err := strconv.Atoi(budgetStr); err == nil && budget >= 0 {
+			config.Server.TotalRequestBudget = budget
+		}
+	}
+
+	// Parse HANDLER_TIMEOUT
+	if timeoutStr, exists := envVars["HANDLER_TIMEOUT"]; exists && timeoutStr != "" {
+		if timeout, err := strconv.Atoi(timeoutStr); err == nil && timeout >= 0 {
+			config.Server.HandlerTimeout = timeout
+		}
+	}
+
+	// Parse METHODS_MERGE_STRATEGY
+	if strategyStr, exists := envVars["METHODS_MERGE_STRATEGY"]; exists && strategyStr != "" {
+		config.Server.MethodsMergeStrategy = MergeStrategy(strings.ToLower(strings.TrimSpace(strategyStr)))
+	}
+
 	// Parse ENABLE_LOGGING
 	if loggingStr, exists := envVars["ENABLE_LOGGING"]; exists && loggingStr != "" {
 		if logging, err := strconv.ParseBool(loggingStr); err == nil {
@@ -111,11 +426,410 @@ func LoadEnvConfig() (*Config, error) {
 		}
 	}
 
-	return config, nil
+	// Parse ACCESS_LOG_FORMAT
+	if format, exists := envVars["ACCESS_LOG_FORMAT"]; exists && format != "" {
+		config.Server.AccessLogFormat = format
+	}
+
+	// Parse CANONICAL_HOST
+	if hostStr, exists := envVars["CANONICAL_HOST"]; exists && hostStr != "" {
+		config.Server.CanonicalHost = strings.TrimSpace(hostStr)
+	}
+
+	// Parse OPTIONS_STATUS
+	if statusStr, exists := envVars["OPTIONS_STATUS"]; exists && statusStr != "" {
+		if status, err := strconv.Atoi(statusStr); err == nil && status >= 0 {
+			config.Server.OptionsStatus = status
+		}
+	}
+
+	// Parse STARTUP_BANNER
+	if bannerStr, exists := envVars["STARTUP_BANNER"]; exists && bannerStr != "" {
+		if banner, err := strconv.ParseBool(bannerStr); err == nil {
+			config.Server.StartupBanner = banner
+		}
+	}
+
+	// Parse RETRY_AFTER_SECONDS
+	if retryStr, exists := envVars["RETRY_AFTER_SECONDS"]; exists && retryStr != "" {
+		if retry, err := strconv.Atoi(retryStr); err == nil && retry > 0 {
+			config.Server.RetryAfterSeconds = retry
+		}
+	}
+
+	// Parse TRUST_FORWARDED_HEADERS
+	if trustStr, exists := envVars["TRUST_FORWARDED_HEADERS"]; exists && trustStr != "" {
+		if trust, err := strconv.ParseBool(trustStr); err == nil {
+			config.Server.TrustForwardedHeaders = trust
+		}
+	}
+
+	// Parse JSON_EXPLICIT_NULLS
+	if nullsStr, exists := envVars["JSON_EXPLICIT_NULLS"]; exists && nullsStr != "" {
+		if nulls, err := strconv.ParseBool(nullsStr); err == nil {
+			config.Server.JSONExplicitNulls = nulls
+		}
+	}
+
+	// Parse JSON_PRETTY
+	if prettyStr, exists := envVars["JSON_PRETTY"]; exists && prettyStr != "" {
+		if pretty, err := strconv.ParseBool(prettyStr); err == nil {
+			config.Server.JSONPretty = pretty
+		}
+	}
+
+	// Parse JSON_INDENT, either "tab" or a number of spaces
+	if indentStr, exists := envVars["JSON_INDENT"]; exists && indentStr != "" {
+		if strings.EqualFold(strings.TrimSpace(indentStr), "tab") {
+			config.Server.JSONIndent = "\t"
+		} else if width, err := strconv.Atoi(indentStr); err == nil && width > 0 && width <= maxJSONIndent {
+			config.Server.JSONIndent = strings.Repeat(" ", width)
+		}
+	}
+
+	// Parse JSON_BOM
+	if bomStr, exists := envVars["JSON_BOM"]; exists && bomStr != "" {
+		if bom, err := strconv.ParseBool(bomStr); err == nil {
+			config.Server.JSONBOM = bom
+		}
+	}
+
+	// Parse HEALTH_METHODS
+	if methodsStr, exists := envVars["HEALTH_METHODS"]; exists && methodsStr != "" {
+		methods := strings.Split(methodsStr, ",")
+		for i, method := range methods {
+			methods[i] = strings.TrimSpace(method)
+		}
+		config.Server.HealthMethods = methods
+	}
+
+	// Parse GLOBAL_ALLOWED_METHODS
+	if methodsStr, exists := envVars["GLOBAL_ALLOWED_METHODS"]; exists && methodsStr != "" {
+		methods := strings.Split(methodsStr, ",")
+		for i, method := range methods {
+			methods[i] = strings.TrimSpace(method)
+		}
+		config.Server.GlobalAllowedMethods = methods
+	}
+
+	// Parse GLOBAL_DENIED_METHODS
+	if methodsStr, exists := envVars["GLOBAL_DENIED_METHODS"]; exists && methodsStr != "" {
+		methods := strings.Split(methodsStr, ",")
+		for i, method := range methods {
+			methods[i] = strings.TrimSpace(method)
+		}
+		config.Server.GlobalDeniedMethods = methods
+	}
+
+	// Parse STRIP_RESPONSE_HEADERS
+	if headersStr, exists := envVars["STRIP_RESPONSE_HEADERS"]; exists && headersStr != "" {
+		headers := strings.Split(headersStr, ",")
+		for i, header := range headers {
+			headers[i] = strings.TrimSpace(header)
+		}
+		config.Server.StripResponseHeaders = headers
+	}
+
+	// Parse ENABLE_EVENT_STREAM
+	if streamStr, exists := envVars["ENABLE_EVENT_STREAM"]; exists && streamStr != "" {
+		if enabled, err := strconv.ParseBool(streamStr); err == nil {
+			config.Server.EnableEventStream = enabled
+		}
+	}
+
+	// Parse SERVE_FAVICON
+	if faviconStr, exists := envVars["SERVE_FAVICON"]; exists && faviconStr != "" {
+		if serve, err := strconv.ParseBool(faviconStr); err == nil {
+			config.Server.ServeFavicon = serve
+		}
+	}
+
+	// Parse SERVE_ROBOTS_TXT
+	if robotsStr, exists := envVars["SERVE_ROBOTS_TXT"]; exists && robotsStr != "" {
+		if serve, err := strconv.ParseBool(robotsStr); err == nil {
+			config.Server.ServeRobotsTxt = serve
+		}
+	}
+
+	// Parse HEALTH_CHECK_TIMEOUT_MS
+	if timeoutStr, exists := envVars["HEALTH_CHECK_TIMEOUT_MS"]; exists && timeoutStr != "" {
+		if timeout, err := strconv.Atoi(timeoutStr); err == nil && timeout >= 0 {
+			config.Server.HealthCheckTimeoutMs = timeout
+		}
+	}
+
+	// Parse HEALTH_FAILS_ON_SHUTDOWN
+	if failStr, exists := envVars["HEALTH_FAILS_ON_SHUTDOWN"]; exists && failStr != "" {
+		if fail, err := strconv.ParseBool(failStr); err == nil {
+			config.Server.HealthFailsOnShutdown = fail
+		}
+	}
+
+	// Parse ENABLE_REQUEST_STATS
+	if statsStr, exists := envVars["ENABLE_REQUEST_STATS"]; exists && statsStr != "" {
+		if enabled, err := strconv.ParseBool(statsStr); err == nil {
+			config.Server.EnableRequestStats = enabled
+		}
+	}
+
+	// Parse LATENCY_BUCKETS_MS as a comma-separated list, ignoring invalid entries
+	if bucketsStr, exists := envVars["LATENCY_BUCKETS_MS"]; exists && bucketsStr != "" {
+		var buckets []int
+		for _, bucketStr := range strings.Split(bucketsStr, ",") {
+			if bucket, err := strconv.Atoi(strings.TrimSpace(bucketStr)); err == nil && bucket > 0 {
+				buckets = append(buckets, bucket)
+			}
+		}
+		config.Server.LatencyBucketsMs = buckets
+	}
+
+	// Parse ENABLE_CONNECTION_STATS
+	if statsStr, exists := envVars["ENABLE_CONNECTION_STATS"]; exists && statsStr != "" {
+		if enabled, err := strconv.ParseBool(statsStr); err == nil {
+			config.Server.EnableConnectionStats = enabled
+		}
+	}
+
+	// Parse READ_ONLY
+	if readOnlyStr, exists := envVars["READ_ONLY"]; exists && readOnlyStr != "" {
+		if enabled, err := strconv.ParseBool(readOnlyStr); err == nil {
+			config.Server.ReadOnly = enabled
+		}
+	}
+
+	// Parse WORKER_POOL_SIZE
+	if sizeStr, exists := envVars["WORKER_POOL_SIZE"]; exists && sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size >= -1 {
+			config.Server.WorkerPoolSize = size
+		}
+	}
+
+	// Parse MIN_DRAIN_SECONDS
+	if drainStr, exists := envVars["MIN_DRAIN_SECONDS"]; exists && drainStr != "" {
+		if drain, err := strconv.Atoi(drainStr); err == nil && drain >= 0 {
+			config.Server.MinDrainSeconds = drain
+		}
+	}
+
+	// Parse DRAIN_KEEP_ALIVES
+	if drainStr, exists := envVars["DRAIN_KEEP_ALIVES"]; exists && drainStr != "" {
+		if drain, err := strconv.ParseBool(drainStr); err == nil {
+			config.Server.DrainKeepAlives = drain
+		}
+	}
+
+	// Parse MAX_RESPONSE_HEADERS
+	if maxStr, exists := envVars["MAX_RESPONSE_HEADERS"]; exists && maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max >= 0 {
+			config.Server.MaxResponseHeaders = max
+		}
+	}
+
+	// Parse MAX_RESPONSE_HEADER_BYTES
+	if maxStr, exists := envVars["MAX_RESPONSE_HEADER_BYTES"]; exists && maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max >= 0 {
+			config.Server.MaxResponseHeaderBytes = max
+		}
+	}
+
+	// Parse INSTANCE_ID
+	if idStr, exists := envVars["INSTANCE_ID"]; exists && idStr != "" {
+		config.Server.InstanceID = strings.TrimSpace(idStr)
+	}
+
+	// Parse TIMEZONE
+	if zoneStr, exists := envVars["TIMEZONE"]; exists && zoneStr != "" {
+		config.Server.Timezone = strings.TrimSpace(zoneStr)
+	}
+
+	// Parse JSON_TIME_LAYOUT
+	if layoutStr, exists := envVars["JSON_TIME_LAYOUT"]; exists && layoutStr != "" {
+		config.Server.JSONTimeLayout = layoutStr
+	}
+
+	// Parse HTTP_PORT
+	if portStr, exists := envVars["HTTP_PORT"]; exists && portStr != "" {
+		if port, err := strconv.Atoi(portStr); err == nil {
+			config.Server.HTTPPort = port
+		}
+	}
+
+	// Parse HTTPS_PORT
+	if portStr, exists := envVars["HTTPS_PORT"]; exists && portStr != "" {
+		if port, err := strconv.Atoi(portStr); err == nil {
+			config.Server.HTTPSPort = port
+		}
+	}
+
+	// Parse TLS_CERT_FILE
+	if certStr, exists := envVars["TLS_CERT_FILE"]; exists && certStr != "" {
+		config.Server.TLSCertFile = strings.TrimSpace(certStr)
+	}
+
+	// Parse TLS_KEY_FILE
+	if keyStr, exists := envVars["TLS_KEY_FILE"]; exists && keyStr != "" {
+		config.Server.TLSKeyFile = strings.TrimSpace(keyStr)
+	}
+
+	// Parse REDIRECT_HTTP_TO_HTTPS
+	if redirectStr, exists := envVars["REDIRECT_HTTP_TO_HTTPS"]; exists && redirectStr != "" {
+		if redirect, err := strconv.ParseBool(redirectStr); err == nil {
+			config.Server.RedirectHTTPToHTTPS = redirect
+		}
+	}
+
+	// Parse MAX_CONNECTIONS
+	if maxStr, exists := envVars["MAX_CONNECTIONS"]; exists && maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max >= 0 {
+			config.Server.MaxConnections = max
+		}
+	}
+
+	// Parse MAX_QUERY_PARAMS
+	if maxStr, exists := envVars["MAX_QUERY_PARAMS"]; exists && maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max >= 0 {
+			config.Server.MaxQueryParams = max
+		}
+	}
+
+	// Parse SLOW_START_SECONDS
+	if slowStr, exists := envVars["SLOW_START_SECONDS"]; exists && slowStr != "" {
+		if seconds, err := strconv.Atoi(slowStr); err == nil && seconds >= 0 {
+			config.Server.SlowStartSeconds = seconds
+		}
+	}
+
+	// Parse SLOW_START_RATE
+	if rateStr, exists := envVars["SLOW_START_RATE"]; exists && rateStr != "" {
+		if rate, err := strconv.Atoi(rateStr); err == nil && rate >= 0 {
+			config.Server.SlowStartRate = rate
+		}
+	}
+
+	// Parse RATE_LIMIT_REQUESTS
+	if limitStr, exists := envVars["RATE_LIMIT_REQUESTS"]; exists && limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit >= 0 {
+			config.Server.RateLimitRequests = limit
+		}
+	}
+
+	// Parse RATE_LIMIT_WINDOW
+	if windowStr, exists := envVars["RATE_LIMIT_WINDOW"]; exists && windowStr != "" {
+		if window, err := strconv.Atoi(windowStr); err == nil && window > 0 {
+			config.Server.RateLimitWindow = window
+		}
+	}
+
+	// Parse MAX_MULTIPART_PARTS
+	if partsStr, exists := envVars["MAX_MULTIPART_PARTS"]; exists && partsStr != "" {
+		if parts, err := strconv.Atoi(partsStr); err == nil && parts >= 0 {
+			config.Server.MaxMultipartParts = parts
+		}
+	}
+
+	// Parse MAX_MULTIPART_BYTES
+	if bytesStr, exists := envVars["MAX_MULTIPART_BYTES"]; exists && bytesStr != "" {
+		if size, err := strconv.ParseInt(bytesStr, 10, 64); err == nil && size >= 0 {
+			config.Server.MaxMultipartBytes = size
+		}
+	}
+
+	// Parse MAX_VALIDATED_BODY_BYTES
+	if bytesStr, exists := envVars["MAX_VALIDATED_BODY_BYTES"]; exists && bytesStr != "" {
+		if size, err := strconv.ParseInt(bytesStr, 10, 64); err == nil && size > 0 {
+			config.Server.MaxValidatedBodyBytes = size
+		}
+	}
+
+	// Parse ADMIN_TOKEN
+	if token, exists := envVars["ADMIN_TOKEN"]; exists && token != "" {
+		config.Server.AdminToken = token
+	}
+
+	// Parse FEATURE_FLAGS as comma separated name=bool pairs
+	if flagsStr, exists := envVars["FEATURE_FLAGS"]; exists && flagsStr != "" {
+		flags := make(map[string]bool)
+		for _, pair := range strings.Split(flagsStr, ",") {
+			name, value, _ := strings.Cut(pair, "=")
+			enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+			if name = strings.TrimSpace(name); name != "" && err == nil {
+				flags[name] = enabled
+			}
+		}
+		config.Server.FeatureFlags = flags
+	}
+
+	// Parse ENABLE_COMPRESSION
+	if compressionStr, exists := envVars["ENABLE_COMPRESSION"]; exists && compressionStr != "" {
+		if compression, err := strconv.ParseBool(compressionStr); err == nil {
+			config.Server.EnableCompression = compression
+		}
+	}
+
+	// Parse COMPRESSION_LEVEL
+	if levelStr, exists := envVars["COMPRESSION_LEVEL"]; exists && levelStr != "" {
+		if level, err := strconv.Atoi(levelStr); err == nil {
+			config.Server.CompressionLevel = level
+		}
+	}
+
+	// Parse STATIC_DIR
+	if staticDir, exists := envVars["STATIC_DIR"]; exists && staticDir != "" {
+		config.Server.StaticDir = staticDir
+	}
+
+	// Parse ENABLED_MIDDLEWARE
+	if middlewareStr, exists := envVars["ENABLED_MIDDLEWARE"]; exists && middlewareStr != "" {
+		names := strings.Split(middlewareStr, ",")
+		for i, name := range names {
+			names[i] = strings.TrimSpace(name)
+		}
+		config.Server.EnabledMiddleware = names
+	}
+
+	// Parse TRACE_SAMPLE_RATE
+	if rateStr, exists := envVars["TRACE_SAMPLE_RATE"]; exists && rateStr != "" {
+		if rate, err := strconv.ParseFloat(rateStr, 64); err == nil && rate >= 0 && rate <= 1 {
+			config.Server.TraceSampleRate = rate
+		}
+	}
+
+	// Parse ARTIFICIAL_LATENCY_MS
+	if latencyStr, exists := envVars["ARTIFICIAL_LATENCY_MS"]; exists && latencyStr != "" {
+		if latency, err := strconv.Atoi(latencyStr); err == nil && latency >= 0 {
+			config.Server.ArtificialLatencyMs = latency
+		}
+	}
+
+	// Parse MIN_TLS_VERSION
+	if versionStr, exists := envVars["MIN_TLS_VERSION"]; exists && versionStr != "" {
+		config.Server.MinTLSVersion = strings.TrimSpace(versionStr)
+	}
+
+	// Parse MEMORY_LIMIT_BYTES
+	if limitStr, exists := envVars["MEMORY_LIMIT_BYTES"]; exists && limitStr != "" {
+		if limit, err := strconv.ParseUint(limitStr, 10, 64); err == nil {
+			config.Server.MemoryLimitBytes = limit
+		}
+	}
+
+	// Parse SHUTDOWN_ORDER
+	if orderStr, exists := envVars["SHUTDOWN_ORDER"]; exists && orderStr != "" {
+		phases := strings.Split(orderStr, ",")
+		for i, phase := range phases {
+			phases[i] = strings.TrimSpace(phase)
+		}
+		config.Server.ShutdownOrder = phases
+	}
+
+	// Apply nested variables such as SERVER__RATE_LIMIT_TIERS__gold__REQUESTS
+	applyEnvPaths(&config, envVars)
+
+	return &config
 }
 
 // MergeConfigs merges two configurations with the override config taking priority
-// Timeout and methods values are never overridden (always use base/hardcoded values)
+// AllowedMethods keep the base values unless the override sets MethodsMergeStrategy
 func MergeConfigs(base, override *Config) *Config {
 	if base == nil {
 		base = GetDefaultConfig()
@@ -127,32 +841,380 @@ func MergeConfigs(base, override *Config) *Config {
 	result := &Config{
 		Server: ServerConfig{
 			Port:            base.Server.Port,
-			ShutdownTimeout: base.Server.ShutdownTimeout, // Always use base (hardcoded) values
-			ReadTimeout:     base.Server.ReadTimeout,     // Always use base (hardcoded) values
-			WriteTimeout:    base.Server.WriteTimeout,    // Always use base (hardcoded) values
+			ShutdownTimeout: base.Server.ShutdownTimeout,
+			ReadTimeout:     base.Server.ReadTimeout,
+			WriteTimeout:    base.Server.WriteTimeout,
+			IdleTimeout:     base.Server.IdleTimeout,
 			AllowedOrigins:  make([]string, len(base.Server.AllowedOrigins)),
 			AllowedMethods:  make([]string, len(base.Server.AllowedMethods)), // Always use base (hardcoded) values
 			EnableLogging:   base.Server.EnableLogging,
+
+			TotalRequestBudget:     base.Server.TotalRequestBudget,
+			HandlerTimeout:         base.Server.HandlerTimeout,
+			MethodsMergeStrategy:   base.Server.MethodsMergeStrategy,
+			ServeFavicon:           base.Server.ServeFavicon,
+			EnableEventStream:      base.Server.EnableEventStream,
+			ServeRobotsTxt:         base.Server.ServeRobotsTxt,
+			RobotsTxt:              base.Server.RobotsTxt,
+			ShutdownOrder:          make([]string, len(base.Server.ShutdownOrder)),
+			ShutdownPhaseTimeouts:  copyIntMap(base.Server.ShutdownPhaseTimeouts),
+			RateLimitRequests:      base.Server.RateLimitRequests,
+			RateLimitWindow:        base.Server.RateLimitWindow,
+			MaxMultipartParts:      base.Server.MaxMultipartParts,
+			MaxMultipartBytes:      base.Server.MaxMultipartBytes,
+			MaxValidatedBodyBytes:  base.Server.MaxValidatedBodyBytes,
+			AdminToken:             base.Server.AdminToken,
+			FeatureFlags:           copyFlags(base.Server.FeatureFlags),
+			EnableCompression:      base.Server.EnableCompression,
+			CompressionLevel:       base.Server.CompressionLevel,
+			StaticDir:              base.Server.StaticDir,
+			EnabledMiddleware:      append([]string(nil), base.Server.EnabledMiddleware...),
+			TraceSampleRate:        base.Server.TraceSampleRate,
+			ArtificialLatencyMs:    base.Server.ArtificialLatencyMs,
+			MinTLSVersion:          base.Server.MinTLSVersion,
+			MemoryLimitBytes:       base.Server.MemoryLimitBytes,
+			HealthFailsOnShutdown:  base.Server.HealthFailsOnShutdown,
+			HealthCheckTimeoutMs:   base.Server.HealthCheckTimeoutMs,
+			EnableRequestStats:     base.Server.EnableRequestStats,
+			EnableConnectionStats:  base.Server.EnableConnectionStats,
+			LatencyBucketsMs:       append([]int(nil), base.Server.LatencyBucketsMs...),
+			ReadOnly:               base.Server.ReadOnly,
+			WorkerPoolSize:         base.Server.WorkerPoolSize,
+			AccessLogFormat:        base.Server.AccessLogFormat,
+			OptionsStatus:          base.Server.OptionsStatus,
+			StartupBanner:          base.Server.StartupBanner,
+			RetryAfterSeconds:      base.Server.RetryAfterSeconds,
+			RouteCORSOrigins:       copyRouteOrigins(base.Server.RouteCORSOrigins),
+			PathRewrites:           copyStringMap(base.Server.PathRewrites),
+			ProxyRoutes:            copyStringMap(base.Server.ProxyRoutes),
+			JSONBOM:                base.Server.JSONBOM,
+			JSONExplicitNulls:      base.Server.JSONExplicitNulls,
+			JSONPretty:             base.Server.JSONPretty,
+			JSONIndent:             base.Server.JSONIndent,
+			HealthMethods:          append([]string(nil), base.Server.HealthMethods...),
+			GlobalAllowedMethods:   append([]string(nil), base.Server.GlobalAllowedMethods...),
+			GlobalDeniedMethods:    append([]string(nil), base.Server.GlobalDeniedMethods...),
+			StripResponseHeaders:   append([]string(nil), base.Server.StripResponseHeaders...),
+			MaxConnections:         base.Server.MaxConnections,
+			MaxQueryParams:         base.Server.MaxQueryParams,
+			SlowStartSeconds:       base.Server.SlowStartSeconds,
+			SlowStartRate:          base.Server.SlowStartRate,
+			CanonicalHost:          base.Server.CanonicalHost,
+			MinDrainSeconds:        base.Server.MinDrainSeconds,
+			DrainKeepAlives:        base.Server.DrainKeepAlives,
+			MaxResponseHeaders:     base.Server.MaxResponseHeaders,
+			MaxResponseHeaderBytes: base.Server.MaxResponseHeaderBytes,
+			TrustForwardedHeaders:  base.Server.TrustForwardedHeaders,
+			HTTPPort:               base.Server.HTTPPort,
+			HTTPSPort:              base.Server.HTTPSPort,
+			TLSCertFile:            base.Server.TLSCertFile,
+			TLSKeyFile:             base.Server.TLSKeyFile,
+			RedirectHTTPToHTTPS:    base.Server.RedirectHTTPToHTTPS,
+			InstanceID:             base.Server.InstanceID,
+			Timezone:               base.Server.Timezone,
+			JSONTimeLayout:         base.Server.JSONTimeLayout,
+			APIKeys:                copyStringMap(base.Server.APIKeys),
+			RateLimitTiers:         copyTiers(base.Server.RateLimitTiers),
 		},
 	}
 
-	// Copy slices from base (timeout and methods are never overridden)
+	// Copy slices from base
 	copy(result.Server.AllowedOrigins, base.Server.AllowedOrigins)
 	copy(result.Server.AllowedMethods, base.Server.AllowedMethods)
+	copy(result.Server.ShutdownOrder, base.Server.ShutdownOrder)
 
-	// Override with non-zero values from override config (excluding timeout and methods)
+	// Override with non-zero values from override config (excluding methods)
 	if override.Server.Port != 0 {
 		result.Server.Port = override.Server.Port
 	}
-	// Timeout values are intentionally NOT overridden - they remain hardcoded
+	if override.Server.ShutdownTimeout != 0 {
+		result.Server.ShutdownTimeout = override.Server.ShutdownTimeout
+	}
+	if override.Server.ReadTimeout != 0 {
+		result.Server.ReadTimeout = override.Server.ReadTimeout
+	}
+	if override.Server.WriteTimeout != 0 {
+		result.Server.WriteTimeout = override.Server.WriteTimeout
+	}
+	if override.Server.IdleTimeout != 0 {
+		result.Server.IdleTimeout = override.Server.IdleTimeout
+	}
 	if len(override.Server.AllowedOrigins) > 0 {
 		result.Server.AllowedOrigins = make([]string, len(override.Server.AllowedOrigins))
 		copy(result.Server.AllowedOrigins, override.Server.AllowedOrigins)
 	}
-	// AllowedMethods are intentionally NOT overridden - they remain hardcoded
+	// AllowedMethods are only merged when the override opts in with a strategy
+	switch override.Server.MethodsMergeStrategy {
+	case MergeReplace:
+		if len(override.Server.AllowedMethods) > 0 {
+			result.Server.AllowedMethods = make([]string, len(override.Server.AllowedMethods))
+			copy(result.Server.AllowedMethods, override.Server.AllowedMethods)
+		}
+	case MergeUnion:
+		result.Server.AllowedMethods = unionMethods(result.Server.AllowedMethods, override.Server.AllowedMethods)
+	}
+	if override.Server.MethodsMergeStrategy != "" {
+		result.Server.MethodsMergeStrategy = override.Server.MethodsMergeStrategy
+	}
+	if override.Server.TotalRequestBudget != 0 {
+		result.Server.TotalRequestBudget = override.Server.TotalRequestBudget
+	}
+	if override.Server.HandlerTimeout != 0 {
+		result.Server.HandlerTimeout = override.Server.HandlerTimeout
+	}
 	// For boolean values, we need to check if they differ from the default
 	// Since we can't distinguish between false and unset, we'll always use the override value
 	result.Server.EnableLogging = override.Server.EnableLogging
+	result.Server.ServeFavicon = override.Server.ServeFavicon
+	result.Server.EnableEventStream = override.Server.EnableEventStream
+	result.Server.ServeRobotsTxt = override.Server.ServeRobotsTxt
+	result.Server.EnableCompression = override.Server.EnableCompression
+	result.Server.HealthFailsOnShutdown = override.Server.HealthFailsOnShutdown
+	result.Server.DrainKeepAlives = override.Server.DrainKeepAlives
+	result.Server.EnableRequestStats = override.Server.EnableRequestStats
+	result.Server.EnableConnectionStats = override.Server.EnableConnectionStats
+	result.Server.ReadOnly = override.Server.ReadOnly
+	result.Server.StartupBanner = override.Server.StartupBanner
+	result.Server.JSONBOM = override.Server.JSONBOM
+	result.Server.JSONExplicitNulls = override.Server.JSONExplicitNulls
+	result.Server.JSONPretty = override.Server.JSONPretty
+	if override.Server.JSONIndent != "" {
+		result.Server.JSONIndent = override.Server.JSONIndent
+	}
+	result.Server.TrustForwardedHeaders = override.Server.TrustForwardedHeaders
+	result.Server.RedirectHTTPToHTTPS = override.Server.RedirectHTTPToHTTPS
+	if override.Server.InstanceID != "" {
+		result.Server.InstanceID = override.Server.InstanceID
+	}
+	if override.Server.Timezone != "" {
+		result.Server.Timezone = override.Server.Timezone
+	}
+	if override.Server.JSONTimeLayout != "" {
+		result.Server.JSONTimeLayout = override.Server.JSONTimeLayout
+	}
+	if override.Server.HTTPPort != 0 {
+		result.Server.HTTPPort = override.Server.HTTPPort
+	}
+	if override.Server.HTTPSPort != 0 {
+		result.Server.HTTPSPort = override.Server.HTTPSPort
+	}
+	if override.Server.TLSCertFile != "" {
+		result.Server.TLSCertFile = override.Server.TLSCertFile
+	}
+	if override.Server.TLSKeyFile != "" {
+		result.Server.TLSKeyFile = override.Server.TLSKeyFile
+	}
+	if override.Server.CompressionLevel != 0 {
+		result.Server.CompressionLevel = override.Server.CompressionLevel
+	}
+	if override.Server.RobotsTxt != "" {
+		result.Server.RobotsTxt = override.Server.RobotsTxt
+	}
+	if override.Server.RateLimitRequests != 0 {
+		result.Server.RateLimitRequests = override.Server.RateLimitRequests
+	}
+	if override.Server.RateLimitWindow != 0 {
+		result.Server.RateLimitWindow = override.Server.RateLimitWindow
+	}
+	if override.Server.MaxMultipartParts != 0 {
+		result.Server.MaxMultipartParts = override.Server.MaxMultipartParts
+	}
+	if override.Server.MaxMultipartBytes != 0 {
+		result.Server.MaxMultipartBytes = override.Server.MaxMultipartBytes
+	}
+	if override.Server.MaxValidatedBodyBytes != 0 {
+		result.Server.MaxValidatedBodyBytes = override.Server.MaxValidatedBodyBytes
+	}
+	if override.Server.AdminToken != "" {
+		result.Server.AdminToken = override.Server.AdminToken
+	}
+	if override.Server.CanonicalHost != "" {
+		result.Server.CanonicalHost = override.Server.CanonicalHost
+	}
+	if len(override.Server.FeatureFlags) > 0 {
+		result.Server.FeatureFlags = copyFlags(override.Server.FeatureFlags)
+	}
+	if len(override.Server.APIKeys) > 0 {
+		result.Server.APIKeys = copyStringMap(override.Server.APIKeys)
+	}
+	if len(override.Server.RateLimitTiers) > 0 {
+		result.Server.RateLimitTiers = copyTiers(override.Server.RateLimitTiers)
+	}
+	if len(override.Server.HealthMethods) > 0 {
+		result.Server.HealthMethods = append([]string(nil), override.Server.HealthMethods...)
+	}
+	if len(override.Server.GlobalAllowedMethods) > 0 {
+		result.Server.GlobalAllowedMethods = append([]string(nil), override.Server.GlobalAllowedMethods...)
+	}
+	if len(override.Server.GlobalDeniedMethods) > 0 {
+		result.Server.GlobalDeniedMethods = append([]string(nil), override.Server.GlobalDeniedMethods...)
+	}
+	if len(override.Server.StripResponseHeaders) > 0 {
+		result.Server.StripResponseHeaders = append([]string(nil), override.Server.StripResponseHeaders...)
+	}
+	if len(override.Server.RouteCORSOrigins) > 0 {
+		result.Server.RouteCORSOrigins = copyRouteOrigins(override.Server.RouteCORSOrigins)
+	}
+	if len(override.Server.PathRewrites) > 0 {
+		result.Server.PathRewrites = copyStringMap(override.Server.PathRewrites)
+	}
+	if override.Server.HealthCheckTimeoutMs != 0 {
+		result.Server.HealthCheckTimeoutMs = override.Server.HealthCheckTimeoutMs
+	}
+	if len(override.Server.LatencyBucketsMs) > 0 {
+		result.Server.LatencyBucketsMs = append([]int(nil), override.Server.LatencyBucketsMs...)
+	}
+	if len(override.Server.ProxyRoutes) > 0 {
+		result.Server.ProxyRoutes = copyStringMap(override.Server.ProxyRoutes)
+	}
+	if override.Server.StaticDir != "" {
+		result.Server.StaticDir = override.Server.StaticDir
+	}
+	if override.Server.TraceSampleRate != 0 {
+		result.Server.TraceSampleRate = override.Server.TraceSampleRate
+	}
+	if override.Server.ArtificialLatencyMs != 0 {
+		result.Server.ArtificialLatencyMs = override.Server.ArtificialLatencyMs
+	}
+	if override.Server.MinTLSVersion != "" {
+		result.Server.MinTLSVersion = override.Server.MinTLSVersion
+	}
+	if override.Server.RetryAfterSeconds != 0 {
+		result.Server.RetryAfterSeconds = override.Server.RetryAfterSeconds
+	}
+	if override.Server.OptionsStatus != 0 {
+		result.Server.OptionsStatus = override.Server.OptionsStatus
+	}
+	if override.Server.AccessLogFormat != "" {
+		result.Server.AccessLogFormat = override.Server.AccessLogFormat
+	}
+	if override.Server.WorkerPoolSize != 0 {
+		result.Server.WorkerPoolSize = override.Server.WorkerPoolSize
+	}
+	if override.Server.MaxConnections != 0 {
+		result.Server.MaxConnections = override.Server.MaxConnections
+	}
+	if override.Server.MaxQueryParams != 0 {
+		result.Server.MaxQueryParams = override.Server.MaxQueryParams
+	}
+	if override.Server.SlowStartSeconds != 0 {
+		result.Server.SlowStartSeconds = override.Server.SlowStartSeconds
+	}
+	if override.Server.SlowStartRate != 0 {
+		result.Server.SlowStartRate = override.Server.SlowStartRate
+	}
+	if override.Server.MinDrainSeconds != 0 {
+		result.Server.MinDrainSeconds = override.Server.MinDrainSeconds
+	}
+	if override.Server.MaxResponseHeaders != 0 {
+		result.Server.MaxResponseHeaders = override.Server.MaxResponseHeaders
+	}
+	if override.Server.MaxResponseHeaderBytes != 0 {
+		result.Server.MaxResponseHeaderBytes = override.Server.MaxResponseHeaderBytes
+	}
+	if override.Server.MemoryLimitBytes != 0 {
+		result.Server.MemoryLimitBytes = override.Server.MemoryLimitBytes
+	}
+	if len(override.Server.EnabledMiddleware) > 0 {
+		result.Server.EnabledMiddleware = append([]string(nil), override.Server.EnabledMiddleware...)
+	}
+	if len(override.Server.ShutdownPhaseTimeouts) > 0 {
+		result.Server.ShutdownPhaseTimeouts = copyIntMap(override.Server.ShutdownPhaseTimeouts)
+	}
+	if len(override.Server.ShutdownOrder) > 0 {
+		result.Server.ShutdownOrder = make([]string, len(override.Server.ShutdownOrder))
+		copy(result.Server.ShutdownOrder, override.Server.ShutdownOrder)
+	}
 
 	return result
 }
+
+// copyFlags returns a copy of a feature flag map, or nil when flags is nil
+func copyFlags(flags map[string]bool) map[string]bool {
+	if flags == nil {
+		return nil
+	}
+	result := make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		result[name] = enabled
+	}
+	return result
+}
+
+// copyStringMap returns a copy of a string map such as the API keys, or nil when m is nil
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for key, value := range m {
+		result[key] = value
+	}
+	return result
+}
+
+// copyIntMap returns a copy of an int map such as the shutdown phase timeouts, or nil when m is nil
+func copyIntMap(m map[string]int) map[string]int {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]int, len(m))
+	for key, value := range m {
+		result[key] = value
+	}
+	return result
+}
+
+// copyTiers returns a copy of a rate limit tier map, or nil when tiers is nil
+func copyTiers(tiers map[string]RateLimitTier) map[string]RateLimitTier {
+	if tiers == nil {
+		return nil
+	}
+	result := make(map[string]RateLimitTier, len(tiers))
+	for name, tier := range tiers {
+		result[name] = tier
+	}
+	return result
+}
+
+// copyRouteOrigins returns a deep copy of per-route origin lists, or nil when routes is nil
+func copyRouteOrigins(routes map[string][]string) map[string][]string {
+	if routes == nil {
+		return nil
+	}
+	result := make(map[string][]string, len(routes))
+	for path, origins := range routes {
+		result[path] = append([]string(nil), origins...)
+	}
+	return result
+}
+
+// unionMethods returns base followed by any methods from extra that base does not already contain
+// Methods are compared case-insensitively and duplicates within extra are dropped
+func unionMethods(base, extra []string) []string {
+	seen := make(map[string]bool, len(base)+len(extra))
+	result := make([]string, 0, len(base)+len(extra))
+
+	for _, method := range base {
+		seen[strings.ToUpper(method)] = true
+		result = append(result, method)
+	}
+	for _, method := range extra {
+		upper := strings.ToUpper(strings.TrimSpace(method))
+		if upper == "" || seen[upper] {
+			continue
+		}
+		seen[upper] = true
+		result = append(result, upper)
+	}
+
+	return result
+}
+
+// HTTPListenPort returns the port plain HTTP is served on, HTTPPort when set and Port otherwise
+func (s ServerConfig) HTTPListenPort() int {
+	if s.HTTPPort != 0 {
+		return s.HTTPPort
+	}
+	return s.Port
+}
@@ -0,0 +1,383 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestMergeConfigsAllowedMethods(t *testing.T) {
+	base := GetDefaultConfig()
+
+	t.Run("no strategy keeps base methods", func(t *testing.T) {
+		override := &Config{
+			Server: ServerConfig{
+				AllowedMethods: []string{"PATCH"},
+			},
+		}
+
+		merged := MergeConfigs(base, override)
+
+		if !reflect.DeepEqual(merged.Server.AllowedMethods, base.Server.AllowedMethods) {
+			t.Errorf("Expected base methods %v, got %v", base.Server.AllowedMethods, merged.Server.AllowedMethods)
+		}
+	})
+
+	t.Run("replace strategy", func(t *testing.T) {
+		override := &Config{
+			Server: ServerConfig{
+				AllowedMethods:       []string{"GET", "PATCH"},
+				MethodsMergeStrategy: MergeReplace,
+			},
+		}
+
+		merged := MergeConfigs(base, override)
+
+		expected := []string{"GET", "PATCH"}
+		if !reflect.DeepEqual(merged.Server.AllowedMethods, expected) {
+			t.Errorf("Expected methods %v, got %v", expected, merged.Server.AllowedMethods)
+		}
+	})
+
+	t.Run("replace strategy with empty override keeps base", func(t *testing.T) {
+		override := &Config{
+			Server: ServerConfig{
+				MethodsMergeStrategy: MergeReplace,
+			},
+		}
+
+		merged := MergeConfigs(base, override)
+
+		if !reflect.DeepEqual(merged.Server.AllowedMethods, base.Server.AllowedMethods) {
+			t.Errorf("Expected base methods %v, got %v", base.Server.AllowedMethods, merged.Server.AllowedMethods)
+		}
+	})
+
+	t.Run("union strategy", func(t *testing.T) {
+		override := &Config{
+			Server: ServerConfig{
+				AllowedMethods:       []string{"patch", "GET", "PATCH"},
+				MethodsMergeStrategy: MergeUnion,
+			},
+		}
+
+		merged := MergeConfigs(base, override)
+
+		expected := []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"}
+		if !reflect.DeepEqual(merged.Server.AllowedMethods, expected) {
+			t.Errorf("Expected methods %v, got %v", expected, merged.Server.AllowedMethods)
+		}
+	})
+
+	t.Run("merge does not mutate base", func(t *testing.T) {
+		override := &Config{
+			Server: ServerConfig{
+				AllowedMethods:       []string{"PATCH"},
+				MethodsMergeStrategy: MergeUnion,
+			},
+		}
+
+		MergeConfigs(base, override)
+
+		if len(base.Server.AllowedMethods) != 5 {
+			t.Errorf("Expected base methods to be unchanged, got %v", base.Server.AllowedMethods)
+		}
+	})
+}
+
+func TestMergeConfigsTimeouts(t *testing.T) {
+	base := GetDefaultConfig()
+	override := &Config{
+		Server: ServerConfig{
+			ShutdownTimeout: 45,
+			ReadTimeout:     20,
+			IdleTimeout:     120,
+		},
+	}
+
+	merged := MergeConfigs(base, override)
+
+	if merged.Server.ShutdownTimeout != 45 || merged.Server.ReadTimeout != 20 {
+		t.Errorf("Expected override timeouts 45 and 20, got %d and %d", merged.Server.ShutdownTimeout, merged.Server.ReadTimeout)
+	}
+	if merged.Server.IdleTimeout != 120 {
+		t.Errorf("Expected override idle timeout 120, got %d", merged.Server.IdleTimeout)
+	}
+	if merged.Server.WriteTimeout != base.Server.WriteTimeout {
+		t.Errorf("Expected unset write timeout to keep base %d, got %d", base.Server.WriteTimeout, merged.Server.WriteTimeout)
+	}
+}
+
+func TestLoadConfigTimeouts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"server": {"read_timeout_seconds": 25}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Server.ReadTimeout != 25 {
+		t.Errorf("Expected read timeout 25, got %d", cfg.Server.ReadTimeout)
+	}
+	if cfg.Server.WriteTimeout != 10 || cfg.Server.ShutdownTimeout != 30 {
+		t.Errorf("Expected default write and shutdown timeouts 10 and 30, got %d and %d", cfg.Server.WriteTimeout, cfg.Server.ShutdownTimeout)
+	}
+}
+
+// writeEnvFile writes a .env file into a temporary directory and changes into it
+func writeEnvFile(t *testing.T, content string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write .env file: %v", err)
+	}
+	t.Chdir(dir)
+}
+
+func TestLoadEnvConfigEmptyValues(t *testing.T) {
+	defaults := GetDefaultConfig()
+
+	t.Run("empty ENABLE_LOGGING keeps default", func(t *testing.T) {
+		writeEnvFile(t, "ENABLE_LOGGING=\n")
+
+		cfg, err := LoadEnvConfig()
+		if err != nil {
+			t.Fatalf("Failed to load env config: %v", err)
+		}
+
+		if cfg.Server.EnableLogging != defaults.Server.EnableLogging {
+			t.Errorf("Expected default logging %v, got %v", defaults.Server.EnableLogging, cfg.Server.EnableLogging)
+		}
+	})
+
+	t.Run("explicit false disables logging", func(t *testing.T) {
+		writeEnvFile(t, "ENABLE_LOGGING=false\n")
+
+		cfg, err := LoadEnvConfig()
+		if err != nil {
+			t.Fatalf("Failed to load env config: %v", err)
+		}
+
+		if cfg.Server.EnableLogging {
+			t.Error("Expected logging to be disabled")
+		}
+	})
+
+	t.Run("empty values keep defaults for other variables", func(t *testing.T) {
+		writeEnvFile(t, "PORT=\nALLOWED_ORIGINS=\nTOTAL_REQUEST_BUDGET=\nMETHODS_MERGE_STRATEGY=\n")
+
+		cfg, err := LoadEnvConfig()
+		if err != nil {
+			t.Fatalf("Failed to load env config: %v", err)
+		}
+
+		if !reflect.DeepEqual(cfg, defaults) {
+			t.Errorf("Expected default config %+v, got %+v", defaults.Server, cfg.Server)
+		}
+	})
+
+	t.Run("bare flag falls back to defaults", func(t *testing.T) {
+		writeEnvFile(t, "ENABLE_LOGGING\nPORT=9000\n")
+
+		cfg, err := LoadEnvConfig()
+		if err != nil {
+			t.Fatalf("Failed to load env config: %v", err)
+		}
+
+		if !reflect.DeepEqual(cfg, defaults) {
+			t.Errorf("Expected default config %+v, got %+v", defaults.Server, cfg.Server)
+		}
+	})
+}
+
+func TestLoadConfigTrailingData(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid", `{"server": {"port": 9090}}`, false},
+		{"trailing whitespace", "{\"server\": {\"port\": 9090}}\n\n  ", false},
+		{"trailing junk", `{"server": {"port": 9090}} garbage`, true},
+		{"second object", `{"server": {"port": 9090}}{"server": {}}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "unexpected trailing data") {
+					t.Errorf("Expected unexpected trailing data error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if cfg.Server.Port != 9090 {
+				t.Errorf("Expected port 9090, got %d", cfg.Server.Port)
+			}
+		})
+	}
+}
+
+func TestLoadConfigGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(`{"server": {"port": 9191, "allowed_origins": ["https://example.com"]}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Detected by extension and, without one, by the gzip magic bytes
+	for _, name := range []string{"config.json.gz", "config.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("Failed to load gzipped config: %v", err)
+			}
+			if cfg.Server.Port != 9191 {
+				t.Errorf("Expected port 9191, got %d", cfg.Server.Port)
+			}
+			if !reflect.DeepEqual(cfg.Server.AllowedOrigins, []string{"https://example.com"}) {
+				t.Errorf("Expected allowed origins from gzipped config, got %v", cfg.Server.AllowedOrigins)
+			}
+		})
+	}
+
+	t.Run("corrupt", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json.gz")
+		if err := os.WriteFile(path, []byte(`{"server": {}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "decompress") {
+			t.Errorf("Expected decompression error, got %v", err)
+		}
+	})
+}
+
+// writeLargeConfig writes a config with the given number of per-route CORS entries
+func writeLargeConfig(tb testing.TB, routes int) string {
+	tb.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"server": {"port": 9292, "route_cors_origins": {`)
+	for i := 0; i < routes; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, `"/api/route-%d": ["https://app-%d.example.com", "https://admin-%d.example.com"]`, i, i, i)
+	}
+	buf.WriteString(`}}}`)
+
+	path := filepath.Join(tb.TempDir(), "config.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigLarge(t *testing.T) {
+	const routes = 50000
+	cfg, err := LoadConfig(writeLargeConfig(t, routes))
+	if err != nil {
+		t.Fatalf("Failed to load large config: %v", err)
+	}
+
+	if cfg.Server.Port != 9292 {
+		t.Errorf("Expected port 9292, got %d", cfg.Server.Port)
+	}
+	if len(cfg.Server.RouteCORSOrigins) != routes {
+		t.Errorf("Expected %d routes, got %d", routes, len(cfg.Server.RouteCORSOrigins))
+	}
+	if origins := cfg.Server.RouteCORSOrigins["/api/route-49999"]; !reflect.DeepEqual(origins, []string{"https://app-49999.example.com", "https://admin-49999.example.com"}) {
+		t.Errorf("Expected origins for the last route, got %v", origins)
+	}
+}
+
+func BenchmarkLoadConfigLarge(b *testing.B) {
+	path := writeLargeConfig(b, 50000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadConfig(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkLoadConfigLargeReadFile is the previous approach of reading the whole file first, for comparison
+func BenchmarkLoadConfigLargeReadFile(b *testing.B) {
+	path := writeLargeConfig(b, 50000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		config := GetDefaultConfig()
+		if err := json.NewDecoder(bytes.NewReader(data)).Decode(config); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLoadConfigUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"server": {"prot": 9090}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lenient by default, keeping the default port
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Expected unknown fields to be ignored by default, got %v", err)
+	}
+	if cfg.Server.Port != GetDefaultConfig().Server.Port {
+		t.Errorf("Expected default port, got %d", cfg.Server.Port)
+	}
+
+	// Strict decoding reports the typo
+	if _, err := LoadConfigWithOptions(path, LoadOptions{DisallowUnknownFields: true}); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Errorf("Expected unknown field error mentioning prot, got %v", err)
+	}
+
+	// Known fields, including the schema version, still load in strict mode
+	if err := os.WriteFile(path, []byte(`{"version": 2, "server": {"port": 9090}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfigWithOptions(path, LoadOptions{DisallowUnknownFields: true})
+	if err != nil {
+		t.Fatalf("Expected a valid config to load in strict mode, got %v", err)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Errorf("Expected port 9090, got %d", cfg.Server.Port)
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPathSeparator separates the segments of a nested env var such as
+// SERVER__RATE_LIMIT_TIERS__gold__REQUESTS
+const EnvPathSeparator = "__"
+
+// applyEnvPaths sets config fields named by nested env vars
+// Each segment matches a field's JSON name case-insensitively, or a key of a map
+// field exactly as written, since keys such as API keys are case-sensitive.
+// Fields without a JSON tag are not configurable.
+// Unknown paths and unparsable values are ignored like the flat variables
+func applyEnvPaths(config *Config, envVars map[string]string) {
+	keys := make([]string, 0, len(envVars))
+	for key, value := range envVars {
+		if strings.Contains(key, EnvPathSeparator) && value != "" {
+			keys = append(keys, key)
+		}
+	}
+	// Apply in a stable order so overlapping paths resolve the same way every time
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := strings.Split(key, EnvPathSeparator)
+		setEnvPath(reflect.ValueOf(config).Elem(), path, envVars[key])
+	}
+}
+
+// setEnvPath parses value into the field of v named by path, reporting whether it was set
+// Maps are copied before they are written so the base config is never mutated
+func setEnvPath(v reflect.Value, path []string, value string) bool {
+	if len(path) == 0 {
+		return setEnvValue(v, value)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		field, ok := envPathField(v, path[0])
+		if !ok {
+			return false
+		}
+		return setEnvPath(field, path[1:], value)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return false
+		}
+		key := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if !setEnvPath(elem, path[1:], value) {
+			return false
+		}
+
+		updated := reflect.MakeMapWithSize(v.Type(), v.Len()+1)
+		iter := v.MapRange()
+		for iter.Next() {
+			updated.SetMapIndex(iter.Key(), iter.Value())
+		}
+		updated.SetMapIndex(key, elem)
+		v.Set(updated)
+		return true
+	}
+	return false
+}
+
+// envPathField returns the field of struct v whose JSON name matches segment
+func envPathField(v reflect.Value, segment string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if strings.EqualFold(name, segment) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setEnvValue parses value into a scalar field, or a slice of scalars written as a
+// comma-separated list such as 10,50,100
+func setEnvValue(v reflect.Value, value string) bool {
+	value = strings.TrimSpace(value)
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		v.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return false
+		}
+		v.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return false
+		}
+		v.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return false
+		}
+		v.SetFloat(parsed)
+	case reflect.Slice:
+		if kind := v.Type().Elem().Kind(); kind == reflect.Slice || kind == reflect.Map || kind == reflect.Struct {
+			return false
+		}
+		parts := strings.Split(value, ",")
+		items := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if !setEnvValue(items.Index(i), part) {
+				return false
+			}
+		}
+		v.Set(items)
+	default:
+		return false
+	}
+	return true
+}
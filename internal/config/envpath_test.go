@@ -0,0 +1,84 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyEnvVarsNestedPaths(t *testing.T) {
+	base := GetDefaultConfig()
+	base.Server.RateLimitTiers = map[string]RateLimitTier{"free": {Requests: 10, WindowSeconds: 60}}
+
+	cfg := applyEnvVars(base, map[string]string{
+		"SERVER__STATIC_DIR":                       "public",
+		"SERVER__TRACE_SAMPLE_RATE":                "0.5",
+		"SERVER__ALLOWED_ORIGINS":                  "https://a.example, https://b.example",
+		"SERVER__FEATURE_FLAGS__beta":              "true",
+		"SERVER__RATE_LIMIT_TIERS__gold__REQUESTS": "100",
+		"SERVER__RATE_LIMIT_TIERS__free__REQUESTS": "5",
+		"SERVER__API_KEYS__AbC123":                 "free",
+		"SERVER__LATENCY_BUCKETS_MS":               "10, 50",
+	})
+
+	if cfg.Server.StaticDir != "public" {
+		t.Errorf("Expected static dir %q, got %q", "public", cfg.Server.StaticDir)
+	}
+	if cfg.Server.TraceSampleRate != 0.5 {
+		t.Errorf("Expected trace sample rate 0.5, got %v", cfg.Server.TraceSampleRate)
+	}
+	if expected := []string{"https://a.example", "https://b.example"}; !reflect.DeepEqual(cfg.Server.AllowedOrigins, expected) {
+		t.Errorf("Expected origins %v, got %v", expected, cfg.Server.AllowedOrigins)
+	}
+	if !cfg.Server.FeatureFlags["beta"] {
+		t.Errorf("Expected feature flag beta to be set, got %v", cfg.Server.FeatureFlags)
+	}
+
+	if expected := map[string]string{"AbC123": "free"}; !reflect.DeepEqual(cfg.Server.APIKeys, expected) {
+		t.Errorf("Expected map keys to keep their case %v, got %v", expected, cfg.Server.APIKeys)
+	}
+	if expected := []int{10, 50}; !reflect.DeepEqual(cfg.Server.LatencyBucketsMs, expected) {
+		t.Errorf("Expected latency buckets %v, got %v", expected, cfg.Server.LatencyBucketsMs)
+	}
+
+	expectedTiers := map[string]RateLimitTier{
+		"free": {Requests: 5, WindowSeconds: 60},
+		"gold": {Requests: 100},
+	}
+	if !reflect.DeepEqual(cfg.Server.RateLimitTiers, expectedTiers) {
+		t.Errorf("Expected tiers %v, got %v", expectedTiers, cfg.Server.RateLimitTiers)
+	}
+	if base.Server.RateLimitTiers["free"].Requests != 10 || len(base.Server.RateLimitTiers) != 1 {
+		t.Errorf("Expected base tiers to be unchanged, got %v", base.Server.RateLimitTiers)
+	}
+}
+
+func TestApplyEnvVarsNestedPathsIgnored(t *testing.T) {
+	defaults := GetDefaultConfig()
+
+	cfg := applyEnvVars(defaults, map[string]string{
+		"SERVER__UNKNOWN_FIELD":      "1",
+		"SERVER__PORT":               "not-a-number",
+		"SERVER__SHUTDOWNTIMEOUT":    "99",
+		"SERVER__ENABLE_LOGGING":     "",
+		"OTHER__PORT":                "9000",
+		"SERVER__PORT__EXTRA":        "9000",
+		"SERVER__LATENCY_BUCKETS_MS": "10,fast",
+	})
+
+	if !reflect.DeepEqual(cfg, defaults) {
+		t.Errorf("Expected default config %+v, got %+v", defaults.Server, cfg.Server)
+	}
+}
+
+func TestLoadEnvConfigNestedPath(t *testing.T) {
+	writeEnvFile(t, "SERVER__RATE_LIMIT_WINDOW_SECONDS=30\n")
+
+	cfg, err := LoadEnvConfig()
+	if err != nil {
+		t.Fatalf("Failed to load env config: %v", err)
+	}
+
+	if cfg.Server.RateLimitWindow != 30 {
+		t.Errorf("Expected rate limit window 30, got %d", cfg.Server.RateLimitWindow)
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// ConfigVersion is the schema version of the current config file layout
+// Files without a "version" field are read as the current layout
+const ConfigVersion = 2
+
+// configDocument is the layout of a config file across every schema version
+// Files for an older version keep their settings in the legacy fields below, which
+// the migrations then move into Config
+type configDocument struct {
+	Version int
+	*Config
+
+	// Version 1 kept the server settings at the top level, with the timing
+	// fields below named without their unit
+	*ServerConfig
+	V1TotalRequestBudget *int
+	V1RateLimitWindow    *int
+}
+
+// newConfigDocument returns a document that decodes on top of the defaults
+func newConfigDocument() *configDocument {
+	legacy := GetDefaultConfig().Server
+	return &configDocument{
+		Config:       GetDefaultConfig(),
+		ServerConfig: &legacy,
+	}
+}
+
+// decodeConfigDocument reads a config document from decoder in a single pass
+// "version" and "server" are decoded in place. Any other top-level key is a
+// version 1 setting: it is kept raw and decoded only when the file declares an
+// older version, so a current file with stale legacy keys is rejected in strict mode
+func decodeConfigDocument(decoder *json.Decoder, strict bool) (*configDocument, error) {
+	doc := newConfigDocument()
+	if tok, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object, got %v", tok)
+	}
+
+	legacy := make(map[string]json.RawMessage)
+	var legacyKeys []string
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		switch {
+		case strings.EqualFold(key, "version"):
+			err = decoder.Decode(&doc.Version)
+		case strings.EqualFold(key, "server"):
+			err = decoder.Decode(&doc.Config.Server)
+		default:
+			var raw json.RawMessage
+			err = decoder.Decode(&raw)
+			legacy[key] = raw
+			legacyKeys = append(legacyKeys, key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	if len(legacyKeys) == 0 || doc.Version < 0 || doc.Version > ConfigVersion {
+		return doc, nil
+	}
+	if doc.Version == 0 || doc.Version == ConfigVersion {
+		if strict {
+			return nil, fmt.Errorf("json: unknown field %q", legacyKeys[0])
+		}
+		return doc, nil
+	}
+
+	// Only files for an older version pay for decoding the legacy settings again
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		return nil, err
+	}
+	legacyDecoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		legacyDecoder.DisallowUnknownFields()
+	}
+	v1 := struct {
+		*ServerConfig
+		V1TotalRequestBudget *int `json:"total_request_budget"`
+		V1RateLimitWindow    *int `json:"rate_limit_window"`
+	}{ServerConfig: doc.ServerConfig}
+	if err := legacyDecoder.Decode(&v1); err != nil {
+		return nil, err
+	}
+	doc.V1TotalRequestBudget = v1.V1TotalRequestBudget
+	doc.V1RateLimitWindow = v1.V1RateLimitWindow
+	return doc, nil
+}
+
+// migration upgrades a document by one schema version, describing each change it made
+type migration func(doc *configDocument) []string
+
+// migrations[i] upgrades a document from version i+1 to version i+2
+var migrations = []migration{
+	migrateV1,
+}
+
+// migrate upgrades doc to ConfigVersion and returns a description of every change
+func (doc *configDocument) migrate() ([]string, error) {
+	if doc.Version == 0 || doc.Version == ConfigVersion {
+		return nil, nil
+	}
+	if doc.Version < 0 || doc.Version > ConfigVersion {
+		return nil, fmt.Errorf("unsupported config version %d, expected at most %d", doc.Version, ConfigVersion)
+	}
+
+	var changes []string
+	for version := doc.Version; version < ConfigVersion; version++ {
+		changes = append(changes, migrations[version-1](doc)...)
+	}
+	doc.Version = ConfigVersion
+	return changes, nil
+}
+
+// migrateV1 moves the top-level version 1 settings under "server" and maps the
+// renamed timing fields to their current names
+func migrateV1(doc *configDocument) []string {
+	changes := []string{`moved top-level settings under "server"`}
+	doc.Config.Server = *doc.ServerConfig
+
+	if doc.V1TotalRequestBudget != nil {
+		doc.Config.Server.TotalRequestBudget = *doc.V1TotalRequestBudget
+		changes = append(changes, "renamed total_request_budget to total_request_budget_seconds")
+	}
+	if doc.V1RateLimitWindow != nil {
+		doc.Config.Server.RateLimitWindow = *doc.V1RateLimitWindow
+		changes = append(changes, "renamed rate_limit_window to rate_limit_window_seconds")
+	}
+	return changes
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigMigratesV1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+		"version": 1,
+		"port": 9393,
+		"allowed_origins": ["https://example.com"],
+		"enable_logging": false,
+		"total_request_budget": 20,
+		"rate_limit_window": 30
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load version 1 config: %v", err)
+	}
+
+	if cfg.Server.Port != 9393 || cfg.Server.EnableLogging {
+		t.Errorf("Expected top-level settings to move under server, got port %d logging %t", cfg.Server.Port, cfg.Server.EnableLogging)
+	}
+	if !reflect.DeepEqual(cfg.Server.AllowedOrigins, []string{"https://example.com"}) {
+		t.Errorf("Expected allowed origins to be migrated, got %v", cfg.Server.AllowedOrigins)
+	}
+	if cfg.Server.TotalRequestBudget != 20 || cfg.Server.RateLimitWindow != 30 {
+		t.Errorf("Expected renamed fields to be migrated, got budget %d window %d", cfg.Server.TotalRequestBudget, cfg.Server.RateLimitWindow)
+	}
+	if cfg.Server.MaxMultipartParts != GetDefaultConfig().Server.MaxMultipartParts {
+		t.Errorf("Expected omitted fields to keep their defaults, got %d", cfg.Server.MaxMultipartParts)
+	}
+}
+
+func TestLoadConfigVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantPort int
+		wantErr  string
+	}{
+		{"unversioned", `{"port": 1111, "server": {"port": 9090}}`, 9090, ""},
+		{"current", `{"version": 2, "server": {"port": 9090}}`, 9090, ""},
+		{"newer", `{"version": 3, "server": {"port": 9090}}`, 0, "unsupported config version 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error mentioning %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if cfg.Server.Port != tt.wantPort {
+				t.Errorf("Expected port %d, got %d", tt.wantPort, cfg.Server.Port)
+			}
+		})
+	}
+}
+
+func TestLoadConfigV2RejectsLegacyKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"version": 2, "port": 1111, "server": {"port": 9090}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Strict decoding rejects the stale top-level key
+	if _, err := LoadConfigWithOptions(path, LoadOptions{DisallowUnknownFields: true}); err == nil || !strings.Contains(err.Error(), "port") {
+		t.Errorf("Expected unknown field error mentioning port, got %v", err)
+	}
+
+	// Lenient decoding ignores it rather than merging it into the server settings
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Errorf("Expected the server port to win over the legacy key, got %d", cfg.Server.Port)
+	}
+}
+
+func TestLoadConfigV1Strict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"port": 9393, "rate_limit_window": 30, "version": 1}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Legacy keys are accepted in strict mode when the file declares version 1,
+	// even when the version comes after them
+	cfg, err := LoadConfigWithOptions(path, LoadOptions{DisallowUnknownFields: true})
+	if err != nil {
+		t.Fatalf("Failed to load version 1 config: %v", err)
+	}
+	if cfg.Server.Port != 9393 || cfg.Server.RateLimitWindow != 30 {
+		t.Errorf("Expected legacy settings to be migrated, got port %d window %d", cfg.Server.Port, cfg.Server.RateLimitWindow)
+	}
+
+	content = `{"version": 1, "prot": 9393}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfigWithOptions(path, LoadOptions{DisallowUnknownFields: true}); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Errorf("Expected unknown field error mentioning prot, got %v", err)
+	}
+}
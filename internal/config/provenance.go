@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Configuration sources reported by Provenance
+const (
+	SourceDefault = "default"
+	SourceJSON    = "json"
+	SourceDotEnv  = ".env"
+	SourceEnv     = "env"
+)
+
+// Provenance records which configuration source set each server field
+// Keys are "server.<name>" using the field's JSON name, or its Go name when the
+// field has no JSON tag
+type Provenance map[string]string
+
+// NewProvenance creates a Provenance attributing every field to the defaults
+func NewProvenance() Provenance {
+	p := make(Provenance)
+	forEachServerField(func(name string, _ int) {
+		p[name] = SourceDefault
+	})
+	return p
+}
+
+// Record attributes every field that differs between before and after to source
+func (p Provenance) Record(before, after *Config, source string) {
+	beforeValue := reflect.ValueOf(before.Server)
+	afterValue := reflect.ValueOf(after.Server)
+
+	forEachServerField(func(name string, index int) {
+		if !reflect.DeepEqual(beforeValue.Field(index).Interface(), afterValue.Field(index).Interface()) {
+			p[name] = source
+		}
+	})
+}
+
+// Overridden returns "field=source" entries for fields not set by the defaults, sorted by field
+func (p Provenance) Overridden() []string {
+	var entries []string
+	for name, source := range p {
+		if source != SourceDefault {
+			entries = append(entries, name+"="+source)
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// forEachServerField calls fn with the provenance key and index of every ServerConfig field
+func forEachServerField(fn func(name string, index int)) {
+	serverType := reflect.TypeOf(ServerConfig{})
+	for i := 0; i < serverType.NumField(); i++ {
+		field := serverType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		fn("server."+name, i)
+	}
+}
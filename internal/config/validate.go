@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ValidateTLSFiles reports an error when only one of TLSCertFile and TLSKeyFile is set
+func (s ServerConfig) ValidateTLSFiles() error {
+	if (s.TLSCertFile == "") != (s.TLSKeyFile == "") {
+		return errors.New("tls_cert_file and tls_key_file must be set together")
+	}
+	return nil
+}
+
+// ValidateRoutePath reports an error unless path is an absolute path that
+// http.ServeMux accepts as a pattern, so a configured route cannot make
+// registration panic or quietly turn into a host-specific pattern
+func ValidateRoutePath(path string) (err error) {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("route path %q must start with /", path)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("route path %q is not a valid pattern: %v", path, p)
+		}
+	}()
+	http.NewServeMux().Handle(path, http.NotFoundHandler())
+	return nil
+}
+
+// Validate checks that the configuration values are usable
+// Every problem found is reported, joined into a single error
+func (c *Config) Validate() error {
+	s := c.Server
+	var errs []error
+
+	if s.Port < 1 || s.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port %d is out of range 1-65535", s.Port))
+	}
+	if s.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("shutdown_timeout_seconds must be positive, got %d", s.ShutdownTimeout))
+	}
+	if s.ReadTimeout < 0 || s.WriteTimeout < 0 {
+		errs = append(errs, fmt.Errorf("read_timeout_seconds and write_timeout_seconds must not be negative, got %d and %d", s.ReadTimeout, s.WriteTimeout))
+	}
+	if s.IdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("idle_timeout_seconds must not be negative, got %d", s.IdleTimeout))
+	}
+	if s.TotalRequestBudget < 0 {
+		errs = append(errs, fmt.Errorf("total_request_budget_seconds must not be negative, got %d", s.TotalRequestBudget))
+	}
+	if s.HandlerTimeout < 0 {
+		errs = append(errs, fmt.Errorf("handler_timeout_seconds must not be negative, got %d", s.HandlerTimeout))
+	}
+	switch s.MethodsMergeStrategy {
+	case "", MergeReplace, MergeUnion:
+	default:
+		errs = append(errs, fmt.Errorf("unknown methods_merge_strategy %q", s.MethodsMergeStrategy))
+	}
+	if s.RateLimitRequests < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit_requests must not be negative, got %d", s.RateLimitRequests))
+	}
+	if s.RateLimitRequests > 0 && s.RateLimitWindow <= 0 {
+		errs = append(errs, fmt.Errorf("rate_limit_window_seconds must be positive when rate limiting is enabled, got %d", s.RateLimitWindow))
+	}
+	if s.MaxMultipartParts < 0 || s.MaxMultipartBytes < 0 {
+		errs = append(errs, errors.New("multipart limits must not be negative"))
+	}
+	if s.MaxValidatedBodyBytes < 0 {
+		errs = append(errs, fmt.Errorf("max_validated_body_bytes must not be negative, got %d", s.MaxValidatedBodyBytes))
+	}
+	if s.TraceSampleRate < 0 || s.TraceSampleRate > 1 {
+		errs = append(errs, fmt.Errorf("trace_sample_rate must be between 0 and 1, got %g", s.TraceSampleRate))
+	}
+	if s.ArtificialLatencyMs < 0 {
+		errs = append(errs, fmt.Errorf("artificial_latency_ms must not be negative, got %d", s.ArtificialLatencyMs))
+	}
+	if s.WorkerPoolSize < -1 {
+		errs = append(errs, fmt.Errorf("worker_pool_size must be -1 (GOMAXPROCS), 0 (unbounded) or positive, got %d", s.WorkerPoolSize))
+	}
+	if strings.ContainsAny(s.CanonicalHost, "/?#") {
+		errs = append(errs, fmt.Errorf("canonical_host must be a host without scheme or path, got %q", s.CanonicalHost))
+	}
+	if s.MinDrainSeconds < 0 {
+		errs = append(errs, fmt.Errorf("min_drain_seconds must not be negative, got %d", s.MinDrainSeconds))
+	} else if s.ShutdownTimeout > 0 && s.MinDrainSeconds >= s.ShutdownTimeout {
+		errs = append(errs, fmt.Errorf("min_drain_seconds must be less than shutdown_timeout (%d), got %d", s.ShutdownTimeout, s.MinDrainSeconds))
+	}
+	phaseTotal := 0
+	for phase, seconds := range s.ShutdownPhaseTimeouts {
+		if seconds <= 0 {
+			errs = append(errs, fmt.Errorf("shutdown_phase_timeouts for %q must be positive, got %d", phase, seconds))
+		}
+		phaseTotal += seconds
+	}
+	if s.ShutdownTimeout > 0 && phaseTotal > s.ShutdownTimeout {
+		errs = append(errs, fmt.Errorf("shutdown_phase_timeouts add up to %d seconds, more than shutdown_timeout (%d)", phaseTotal, s.ShutdownTimeout))
+	}
+	if s.MaxResponseHeaders < 0 || s.MaxResponseHeaderBytes < 0 {
+		errs = append(errs, fmt.Errorf("max_response_headers and max_response_header_bytes must not be negative, got %d and %d", s.MaxResponseHeaders, s.MaxResponseHeaderBytes))
+	}
+	if len(s.JSONIndent) > maxJSONIndent || strings.Trim(s.JSONIndent, " \t") != "" {
+		errs = append(errs, fmt.Errorf("json_indent must be at most %d spaces or tabs, got %q", maxJSONIndent, s.JSONIndent))
+	}
+	if s.HTTPPort < 0 || s.HTTPPort > 65535 {
+		errs = append(errs, fmt.Errorf("http_port must be between 0 and 65535, got %d", s.HTTPPort))
+	}
+	if s.HTTPSPort < 0 || s.HTTPSPort > 65535 {
+		errs = append(errs, fmt.Errorf("https_port must be between 0 and 65535, got %d", s.HTTPSPort))
+	}
+	if s.HTTPSPort > 0 && (s.TLSCertFile == "" || s.TLSKeyFile == "") {
+		errs = append(errs, errors.New("https_port requires tls_cert_file and tls_key_file"))
+	} else if err := s.ValidateTLSFiles(); err != nil {
+		errs = append(errs, err)
+	}
+	if s.HTTPSPort > 0 && s.HTTPSPort == s.HTTPListenPort() {
+		errs = append(errs, fmt.Errorf("https_port must differ from the HTTP port %d", s.HTTPSPort))
+	}
+	if s.RedirectHTTPToHTTPS && s.HTTPSPort == 0 {
+		errs = append(errs, errors.New("redirect_http_to_https requires https_port"))
+	}
+	for from := range s.PathRewrites {
+		if !strings.HasPrefix(from, "^") {
+			continue
+		}
+		if _, err := regexp.Compile(from); err != nil {
+			errs = append(errs, fmt.Errorf("path_rewrites has an invalid expression %q: %w", from, err))
+		}
+	}
+	for path, upstream := range s.ProxyRoutes {
+		if err := ValidateRoutePath(path); err != nil {
+			errs = append(errs, fmt.Errorf("proxy_routes: %w", err))
+		}
+		target, err := url.Parse(upstream)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			errs = append(errs, fmt.Errorf("proxy_routes upstream for %s must be an absolute http or https URL, got %q", path, upstream))
+		}
+	}
+	if s.MaxConnections < 0 {
+		errs = append(errs, fmt.Errorf("max_connections must not be negative, got %d", s.MaxConnections))
+	}
+	for _, bucket := range s.LatencyBucketsMs {
+		if bucket <= 0 {
+			errs = append(errs, fmt.Errorf("latency_buckets_ms must be positive, got %d", bucket))
+			break
+		}
+	}
+	if s.HealthCheckTimeoutMs < 0 {
+		errs = append(errs, fmt.Errorf("health_check_timeout_ms must not be negative, got %d", s.HealthCheckTimeoutMs))
+	}
+	if s.MaxQueryParams < 0 {
+		errs = append(errs, fmt.Errorf("max_query_params must not be negative, got %d", s.MaxQueryParams))
+	}
+	if s.SlowStartSeconds < 0 || s.SlowStartRate < 0 {
+		errs = append(errs, fmt.Errorf("slow_start_seconds and slow_start_rate must not be negative, got %d and %d", s.SlowStartSeconds, s.SlowStartRate))
+	}
+	switch s.OptionsStatus {
+	case 0, http.StatusOK, http.StatusNoContent:
+	default:
+		errs = append(errs, fmt.Errorf("options_status must be 0, 200 or 204, got %d", s.OptionsStatus))
+	}
+	if s.RetryAfterSeconds < 0 {
+		errs = append(errs, fmt.Errorf("retry_after_seconds must not be negative, got %d", s.RetryAfterSeconds))
+	}
+
+	for name, tier := range s.RateLimitTiers {
+		if tier.Requests < 0 || (tier.Requests > 0 && tier.WindowSeconds <= 0) {
+			errs = append(errs, fmt.Errorf("rate limit tier %q needs non-negative requests and a positive window", name))
+		}
+	}
+	for _, tier := range s.APIKeys {
+		if _, ok := s.RateLimitTiers[tier]; !ok {
+			errs = append(errs, fmt.Errorf("api key assigned to undefined tier %q", tier))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := GetDefaultConfig().Validate(); err != nil {
+		t.Fatalf("Expected default config to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(s *ServerConfig)
+		expected string
+	}{
+		{"port", func(s *ServerConfig) { s.Port = 0 }, "port"},
+		{"shutdown timeout", func(s *ServerConfig) { s.ShutdownTimeout = -1 }, "shutdown_timeout"},
+		{"idle timeout", func(s *ServerConfig) { s.IdleTimeout = -1 }, "idle_timeout_seconds"},
+		{"handler timeout", func(s *ServerConfig) { s.HandlerTimeout = -1 }, "handler_timeout_seconds"},
+		{"merge strategy", func(s *ServerConfig) { s.MethodsMergeStrategy = "bogus" }, "methods_merge_strategy"},
+		{"rate limit window", func(s *ServerConfig) { s.RateLimitRequests = 5; s.RateLimitWindow = 0 }, "rate_limit_window_seconds"},
+		{"trace sample rate", func(s *ServerConfig) { s.TraceSampleRate = 2 }, "trace_sample_rate"},
+		{"options status", func(s *ServerConfig) { s.OptionsStatus = 404 }, "options_status"},
+		{"max connections", func(s *ServerConfig) { s.MaxConnections = -1 }, "max_connections"},
+		{"slow start", func(s *ServerConfig) { s.SlowStartSeconds = -1 }, "slow_start_seconds"},
+		{"path rewrite expression", func(s *ServerConfig) { s.PathRewrites = map[string]string{"^/(": "/"} }, "path_rewrites"},
+		{"proxy upstream", func(s *ServerConfig) { s.ProxyRoutes = map[string]string{"/api": "localhost:9000"} }, "proxy_routes"},
+		{"proxy path without slash", func(s *ServerConfig) { s.ProxyRoutes = map[string]string{"api": "http://localhost:9000"} }, "must start with /"},
+		{"proxy path pattern", func(s *ServerConfig) { s.ProxyRoutes = map[string]string{"/x/{bad": "http://localhost:9000"} }, "not a valid pattern"},
+		{"TLS key without certificate", func(s *ServerConfig) { s.TLSKeyFile = "key.pem" }, "tls_cert_file and tls_key_file"},
+		{"https without certificate", func(s *ServerConfig) { s.HTTPSPort = 8443 }, "tls_cert_file"},
+		{"json indent", func(s *ServerConfig) { s.JSONIndent = "--" }, "json_indent"},
+		{"shutdown phase timeouts", func(s *ServerConfig) { s.ShutdownPhaseTimeouts = map[string]int{"drain": 20, "database": 20} }, "shutdown_phase_timeouts"},
+		{"min drain time", func(s *ServerConfig) { s.MinDrainSeconds = 30 }, "min_drain_seconds"},
+		{"worker pool size", func(s *ServerConfig) { s.WorkerPoolSize = -2 }, "worker_pool_size"},
+		{"canonical host", func(s *ServerConfig) { s.CanonicalHost = "https://example.com/" }, "canonical_host"},
+		{"undefined tier", func(s *ServerConfig) { s.APIKeys = map[string]string{"key": "gold"} }, "undefined tier"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := GetDefaultConfig()
+			tt.mutate(&cfg.Server)
+
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error mentioning %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"gopkg.in/yaml.v3"
+)
+
+// Config file formats, chosen by file extension
+const (
+	formatJSON = "JSON"
+	formatYAML = "YAML"
+)
+
+// configFormat returns the format of the config file at path from its extension
+// A trailing .gz is ignored, so config.yaml.gz is YAML. Anything other than
+// .json, .yaml or .yml is an error rather than being read as JSON
+func configFormat(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(path, ".gz")))
+	switch ext {
+	case ".json":
+		return formatJSON, nil
+	case ".yaml", ".yml":
+		return formatYAML, nil
+	default:
+		return "", fmt.Errorf("unsupported config file extension %q in %s, expected .json, .yaml or .yml", ext, path)
+	}
+}
+
+// yamlToJSON converts a single YAML document to JSON
+// Config fields are only tagged for JSON, so YAML is decoded through the same
+// JSON path and keys match the JSON field names, e.g. allowed_origins
+func yamlToJSON(r io.Reader) ([]byte, error) {
+	decoder := yaml.NewDecoder(r)
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		if err == io.EOF {
+			return nil, errors.New("config file is empty")
+		}
+		return nil, err
+	}
+
+	var extra interface{}
+	if err := decoder.Decode(&extra); err != io.EOF {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("unexpected additional YAML document")
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("config must be a mapping with string keys: %w", err)
+	}
+	return data, nil
+}
+
+// jsonToYAML converts a JSON document to block-style YAML, keeping the key order
+func jsonToYAML(data []byte) ([]byte, error) {
+	// JSON is valid YAML, so parsing it as a node keeps the struct's field order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	clearStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// clearStyle drops the flow and quoting styles parsed from JSON so the encoder
+// writes block collections and only quotes strings that need it
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const yamlConfig = `# Server settings
+server:
+  port: 9393
+  enable_logging: false
+  allowed_origins:
+    - https://example.com
+    - https://admin.example.com
+  rate_limit_tiers:
+    pro:
+      requests: 100
+      window_seconds: 60
+`
+
+func TestLoadConfigYAML(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	if _, err := gz.Write([]byte(yamlConfig)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"config.yaml":    []byte(yamlConfig),
+		"config.yml":     []byte(yamlConfig),
+		"CONFIG.YAML":    []byte(yamlConfig),
+		"config.yaml.gz": gzipped.Bytes(),
+	}
+	for name, data := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("Failed to load YAML config: %v", err)
+			}
+			if cfg.Server.Port != 9393 {
+				t.Errorf("Expected port 9393, got %d", cfg.Server.Port)
+			}
+			if cfg.Server.EnableLogging {
+				t.Error("Expected enable_logging false from YAML")
+			}
+			if !reflect.DeepEqual(cfg.Server.AllowedOrigins, []string{"https://example.com", "https://admin.example.com"}) {
+				t.Errorf("Expected allowed origins from YAML, got %v", cfg.Server.AllowedOrigins)
+			}
+			if tier := cfg.Server.RateLimitTiers["pro"]; tier.Requests != 100 || tier.WindowSeconds != 60 {
+				t.Errorf("Expected pro tier from YAML, got %+v", tier)
+			}
+			if cfg.Server.ShutdownTimeout != GetDefaultConfig().Server.ShutdownTimeout {
+				t.Errorf("Expected omitted fields to keep their defaults, got shutdown timeout %d", cfg.Server.ShutdownTimeout)
+			}
+		})
+	}
+}
+
+func TestLoadConfigYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		opts     LoadOptions
+		expected string
+	}{
+		{"unknown extension", "config.toml", "port = 1", LoadOptions{}, "unsupported config file extension"},
+		{"no extension", "config", "{}", LoadOptions{}, "unsupported config file extension"},
+		{"invalid YAML", "config.yaml", "server: [port", LoadOptions{}, "failed to parse YAML config"},
+		{"wrong type", "config.yaml", "server:\n  port: high\n", LoadOptions{}, "failed to parse YAML config"},
+		{"empty", "config.yaml", "", LoadOptions{}, "config file is empty"},
+		{"several documents", "config.yaml", "server:\n  port: 1\n---\nserver:\n  port: 2\n", LoadOptions{}, "additional YAML document"},
+		{"unknown field when disallowed", "config.yaml", "server:\n  prot: 9090\n", LoadOptions{DisallowUnknownFields: true}, "prot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := LoadConfigWithOptions(path, tt.opts); err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestWriteConfigYAML(t *testing.T) {
+	cfg := GetDefaultConfig()
+	cfg.Server.Port = 9494
+	cfg.Server.RobotsTxt = "User-agent: *\nDisallow: /admin\n"
+	cfg.Server.APIKeys = map[string]string{"key-1": "pro"}
+
+	for _, name := range []string{"config.yaml", "config.yml", "config.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := WriteConfig(path, cfg); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			isYAML := !strings.HasSuffix(name, ".json")
+			if got := strings.HasPrefix(string(data), "server:\n"); got != isYAML {
+				t.Errorf("Expected YAML output %t, got:\n%s", isYAML, data)
+			}
+
+			loaded, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("Failed to load written config: %v", err)
+			}
+			if !reflect.DeepEqual(loaded, cfg) {
+				t.Errorf("Expected written config to round-trip, got %+v", loaded.Server)
+			}
+		})
+	}
+
+	for _, name := range []string{"config.toml", "config.yaml.gz"} {
+		if err := WriteConfig(filepath.Join(t.TempDir(), name), cfg); err == nil {
+			t.Errorf("Expected writing %s to fail", name)
+		}
+	}
+}
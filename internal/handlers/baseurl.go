@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SetTrustForwardedHeaders controls whether X-Forwarded-Proto and X-Forwarded-Host are
+// used to build external URLs. Only enable it behind a proxy that sets or strips them,
+// otherwise clients can choose the host their redirects point to
+func (h *Handler) SetTrustForwardedHeaders(trusted bool) {
+	h.trustForwarded = trusted
+}
+
+// BaseURL returns the external scheme and host the client used to reach the server,
+// e.g. "https://api.example.com". Behind a trusted proxy the forwarded headers take
+// precedence over the connection's TLS state and the Host header
+func (h *Handler) BaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+
+	if h.trustForwarded {
+		if proto := firstForwardedValue(r.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwardedHost := firstForwardedValue(r.Header.Get("X-Forwarded-Host")); validForwardedHost(forwardedHost) {
+			host = forwardedHost
+		}
+	}
+
+	return scheme + "://" + host
+}
+
+// absoluteURL resolves a path-absolute url such as "/login" against the external base
+// URL when forwarded headers are trusted. Without a proxy the client resolves relative
+// urls against the host it used, so they and absolute urls are returned unchanged
+func (h *Handler) absoluteURL(r *http.Request, url string) string {
+	if !h.trustForwarded || !strings.HasPrefix(url, "/") || strings.HasPrefix(url, "//") {
+		return url
+	}
+	return h.BaseURL(r) + url
+}
+
+// firstForwardedValue returns the first entry of a comma-separated forwarded header,
+// which is the value set by the proxy closest to the client
+func firstForwardedValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.ToLower(strings.TrimSpace(first))
+}
+
+// validForwardedHost reports whether host is a non-empty host[:port] without
+// characters that could change the meaning of the URL it is placed in
+func validForwardedHost(host string) bool {
+	return host != "" && !strings.ContainsAny(host, "/\\?#@ \t")
+}
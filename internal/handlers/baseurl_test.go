@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_BaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		trusted  bool
+		tls      bool
+		headers  map[string]string
+		expected string
+	}{
+		{"direct request", false, false, nil, "http://internal:8080"},
+		{"direct TLS request", false, true, nil, "https://internal:8080"},
+		{"forwarded headers ignored when untrusted", false, false, map[string]string{
+			"X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.example.com",
+		}, "http://internal:8080"},
+		{"forwarded headers trusted", true, false, map[string]string{
+			"X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.example.com",
+		}, "https://api.example.com"},
+		{"first of several proxies", true, false, map[string]string{
+			"X-Forwarded-Proto": "HTTPS, http", "X-Forwarded-Host": "api.example.com, lb.internal",
+		}, "https://api.example.com"},
+		{"invalid forwarded values fall back", true, false, map[string]string{
+			"X-Forwarded-Proto": "javascript", "X-Forwarded-Host": "evil.example.com/path",
+		}, "http://internal:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler()
+			handler.SetTrustForwardedHeaders(tt.trusted)
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Host = "internal:8080"
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			if got := handler.BaseURL(req); got != tt.expected {
+				t.Errorf("handler returned wrong base URL: got %v want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandler_RedirectForwarded(t *testing.T) {
+	handler := NewHandler()
+	handler.SetTrustForwardedHeaders(true)
+
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{"path is made absolute", "/login", "https://www.example.com/login"},
+		{"absolute url unchanged", "https://other.example.com/", "https://other.example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/old", nil)
+			req.Host = "10.0.0.5:8080"
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "www.example.com")
+			rr := httptest.NewRecorder()
+
+			handler.Redirect(rr, req, tt.url, false)
+
+			if status := rr.Code; status != http.StatusFound {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusFound)
+			}
+			if location := rr.Header().Get("Location"); location != tt.expected {
+				t.Errorf("handler returned wrong Location: got %v want %v", location, tt.expected)
+			}
+		})
+	}
+}
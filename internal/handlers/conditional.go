@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// writeWithLastModified writes data as a JSON response with a Last-Modified header
+// GET and HEAD requests whose If-Modified-Since is not older than modTime get a
+// 304 Not Modified without a body. A zero modTime writes data unconditionally
+func (h *Handler) writeWithLastModified(w http.ResponseWriter, r *http.Request, modTime time.Time, data interface{}) {
+	if modTime.IsZero() || modTime.Equal(time.Unix(0, 0)) {
+		h.writeJSONResponse(w, r, http.StatusOK, data)
+		return
+	}
+
+	// HTTP dates have one-second resolution, so compare at that precision
+	modTime = modTime.Truncate(time.Second)
+	w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+
+	if notModifiedSince(r, modTime) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.writeJSONResponse(w, r, http.StatusOK, data)
+}
+
+// notModifiedSince reports whether a GET or HEAD request's If-Modified-Since is
+// not older than modTime. Missing or malformed dates are treated as modified
+func notModifiedSince(r *http.Request, modTime time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modTime.After(since)
+}
+
+// WeakETag returns the weak entity tag W/"version" for a content version
+// A version containing anything but the characters allowed in an entity tag,
+// such as quotes, spaces or control characters, is replaced by its SHA-256 hash
+// so the tag stays valid and distinct versions keep distinct tags
+func WeakETag(version string) string {
+	for i := 0; i < len(version); i++ {
+		if !isETagChar(version[i]) {
+			sum := sha256.Sum256([]byte(version))
+			version = hex.EncodeToString(sum[:16])
+			break
+		}
+	}
+	return `W/"` + version + `"`
+}
+
+// isETagChar reports whether c may appear inside the quotes of an entity tag (RFC 9110 etagc)
+func isETagChar(c byte) bool {
+	return c == 0x21 || (c >= 0x23 && c <= 0x7e) || c >= 0x80
+}
+
+// writeWithETag writes data as a JSON response with a weak ETag derived from version
+// Weak tags suit content that is equivalent within a version without being byte
+// identical. GET and HEAD requests whose If-None-Match weakly matches the tag get a
+// 304 Not Modified without a body. An empty version writes data unconditionally
+func (h *Handler) writeWithETag(w http.ResponseWriter, r *http.Request, version string, data interface{}) {
+	if version == "" {
+		h.writeJSONResponse(w, r, http.StatusOK, data)
+		return
+	}
+
+	etag := WeakETag(version)
+	w.Header().Set("ETag", etag)
+
+	if (r.Method == http.MethodGet || r.Method == http.MethodHead) && weakMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.writeJSONResponse(w, r, http.StatusOK, data)
+}
+
+// weakMatch reports whether an If-None-Match header matches etag using weak
+// comparison, which ignores the W/ prefix on either side. "*" matches any tag.
+// The header is read as a list of quoted tags, so a comma inside a tag does not
+// split it. Parsing stops at the first malformed entry
+func weakMatch(ifNoneMatch, etag string) bool {
+	opaque := strings.TrimPrefix(etag, "W/")
+	rest := ifNoneMatch
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if rest == "" {
+			return false
+		}
+		if rest[0] == '*' {
+			return true
+		}
+
+		rest = strings.TrimPrefix(rest, "W/")
+		if !strings.HasPrefix(rest, `"`) {
+			return false
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return false
+		}
+		if rest[:end+2] == opaque {
+			return true
+		}
+		rest = rest[end+2:]
+	}
+}
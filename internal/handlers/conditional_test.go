@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_WriteWithLastModified(t *testing.T) {
+	handler := NewHandler()
+	modTime := time.Date(2024, time.March, 1, 12, 0, 0, 500, time.UTC)
+	lastModified := "Fri, 01 Mar 2024 12:00:00 GMT"
+
+	tests := []struct {
+		name            string
+		method          string
+		ifModifiedSince string
+		expectedStatus  int
+	}{
+		{"no If-Modified-Since", "GET", "", http.StatusOK},
+		{"same time", "GET", lastModified, http.StatusNotModified},
+		{"newer than modification", "GET", "Sat, 02 Mar 2024 12:00:00 GMT", http.StatusNotModified},
+		{"older than modification", "GET", "Thu, 29 Feb 2024 12:00:00 GMT", http.StatusOK},
+		{"malformed date", "GET", "yesterday", http.StatusOK},
+		{"HEAD not modified", "HEAD", lastModified, http.StatusNotModified},
+		{"POST ignores condition", "POST", lastModified, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/resource", nil)
+			if tt.ifModifiedSince != "" {
+				req.Header.Set("If-Modified-Since", tt.ifModifiedSince)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.writeWithLastModified(rr, req, modTime, Response{Status: "success"})
+
+			if status := rr.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+			}
+			if got := rr.Header().Get("Last-Modified"); got != lastModified {
+				t.Errorf("handler returned wrong Last-Modified: got %q want %q", got, lastModified)
+			}
+			if tt.expectedStatus == http.StatusNotModified && rr.Body.Len() != 0 {
+				t.Errorf("expected empty body for 304, got %q", rr.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK && tt.method == "GET" && rr.Body.Len() == 0 {
+				t.Error("expected a body for 200")
+			}
+		})
+	}
+}
+
+func TestHandler_WriteWithLastModifiedZeroTime(t *testing.T) {
+	handler := NewHandler()
+
+	req := httptest.NewRequest("GET", "/resource", nil)
+	req.Header.Set("If-Modified-Since", "Fri, 01 Mar 2024 12:00:00 GMT")
+	rr := httptest.NewRecorder()
+
+	handler.writeWithLastModified(rr, req, time.Time{}, Response{Status: "success"})
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	if got := rr.Header().Get("Last-Modified"); got != "" {
+		t.Errorf("expected no Last-Modified for an unknown modification time, got %q", got)
+	}
+}
+
+func TestWeakETag(t *testing.T) {
+	if got := WeakETag("v42"); got != `W/"v42"` {
+		t.Errorf("expected W/\"v42\", got %q", got)
+	}
+	for _, version := range []string{`a"b\c`, "v1, v2", "with space", "tab\there"} {
+		got := WeakETag(version)
+		if !strings.HasPrefix(got, `W/"`) || !strings.HasSuffix(got, `"`) || len(got) != len(`W/""`)+32 {
+			t.Errorf("expected %q to be hashed into a valid tag, got %q", version, got)
+		}
+		if !weakMatch(got, got) {
+			t.Errorf("expected the tag for %q to match itself, got %q", version, got)
+		}
+	}
+	if WeakETag("a b") == WeakETag("a c") {
+		t.Error("expected distinct versions to keep distinct tags")
+	}
+}
+
+func TestHandler_WriteWithETag(t *testing.T) {
+	handler := NewHandler()
+
+	tests := []struct {
+		name           string
+		method         string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{"no If-None-Match", "GET", "", http.StatusOK},
+		{"weak match", "GET", `W/"v7"`, http.StatusNotModified},
+		{"strong tag matches weakly", "GET", `"v7"`, http.StatusNotModified},
+		{"match in list", "GET", `W/"v6", W/"v7"`, http.StatusNotModified},
+		{"comma inside another tag", "GET", `"a,b",W/"v7"`, http.StatusNotModified},
+		{"comma inside tag does not split", "GET", `"x,W/"v7"`, http.StatusOK},
+		{"wildcard", "GET", "*", http.StatusNotModified},
+		{"other version", "GET", `W/"v6"`, http.StatusOK},
+		{"HEAD not modified", "HEAD", `W/"v7"`, http.StatusNotModified},
+		{"POST ignores condition", "POST", `W/"v7"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/resource", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.writeWithETag(rr, req, "v7", Response{Status: "success"})
+
+			if status := rr.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+			}
+			if got := rr.Header().Get("ETag"); got != `W/"v7"` {
+				t.Errorf("handler returned wrong ETag: got %q want %q", got, `W/"v7"`)
+			}
+			if tt.expectedStatus == http.StatusNotModified && rr.Body.Len() != 0 {
+				t.Errorf("expected empty body for 304, got %q", rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"phantom-server/internal/middleware"
+)
+
+// RequestStats returns a handler for the "/debug/requests" endpoint
+// It lists per-route request counts with max and p95 latency, slowest first
+func (h *Handler) RequestStats(stats *middleware.LatencyStats) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.writeJSONResponse(w, r, http.StatusOK, Response{
+			Status: "success",
+			Data: map[string]interface{}{
+				"routes": stats.Snapshot(),
+			},
+		})
+	}
+}
+
+// ConnectionStats returns a handler for the "/debug/connections" endpoint
+// It reports open connections by state along with totals since startup
+func (h *Handler) ConnectionStats(stats *middleware.ConnStats) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.writeJSONResponse(w, r, http.StatusOK, Response{
+			Status: "success",
+			Data:   stats.Snapshot(),
+		})
+	}
+}
+
+// MetricsContentType is the Prometheus text exposition format served at /metrics
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metrics returns a handler for the "/metrics" endpoint
+// It writes the request duration histogram in the Prometheus text format, with
+// cumulative buckets in seconds so percentiles can be estimated downstream
+func (h *Handler) Metrics(histogram *middleware.LatencyHistogram) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshot := histogram.Snapshot()
+
+		var body strings.Builder
+		body.WriteString("# HELP http_request_duration_seconds Duration of HTTP requests.\n")
+		body.WriteString("# TYPE http_request_duration_seconds histogram\n")
+		for _, bucket := range snapshot.Buckets {
+			fmt.Fprintf(&body, "http_request_duration_seconds_bucket{le=\"%s\"} %d\n",
+				strconv.FormatFloat(bucket.UpperBound.Seconds(), 'g', -1, 64), bucket.Count)
+		}
+		fmt.Fprintf(&body, "http_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", snapshot.Count)
+		fmt.Fprintf(&body, "http_request_duration_seconds_sum %s\n", strconv.FormatFloat(snapshot.Sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(&body, "http_request_duration_seconds_count %d\n", snapshot.Count)
+
+		w.Header().Set("Content-Type", MetricsContentType)
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method == http.MethodHead {
+			return
+		}
+		if _, err := io.WriteString(w, body.String()); err != nil {
+			log.Printf("Failed to write metrics to %s: %v", r.RemoteAddr, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+)
+
+// WriteFile writes data as a file download named filename
+// An empty contentType is guessed from the file extension, then from the data itself
+func (h *Handler) WriteFile(w http.ResponseWriter, r *http.Request, filename, contentType string, data []byte) {
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	setDownloadHeaders(w, filename, contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write(data); err != nil {
+		log.Printf("Failed to write download to %s %s: %v", r.Method, r.URL.Path, err)
+	}
+}
+
+// StreamFile streams src to the client as a file download named filename, without
+// buffering it in memory. An empty contentType is guessed from the file extension,
+// falling back to application/octet-stream. It returns an error if copying fails,
+// after the headers have already been sent
+func (h *Handler) StreamFile(w http.ResponseWriter, r *http.Request, filename, contentType string, src io.Reader) error {
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	setDownloadHeaders(w, filename, contentType)
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		return fmt.Errorf("failed to stream %s: %w", filename, err)
+	}
+	return nil
+}
+
+// setDownloadHeaders sets the headers that make a browser save the response as filename
+// Non-ASCII names are encoded as RFC 2231 extended parameters by mime.FormatMediaType
+func setDownloadHeaders(w http.ResponseWriter, filename, contentType string) {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(filename)})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_WriteFile(t *testing.T) {
+	handler := NewHandler()
+	data := []byte("id,name\n1,alice\n")
+
+	rr := httptest.NewRecorder()
+	handler.WriteFile(rr, httptest.NewRequest("GET", "/export", nil), "report.csv", "", data)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected Content-Type from the extension, got %q", ct)
+	}
+	if cd := rr.Header().Get("Content-Disposition"); cd != `attachment; filename=report.csv` {
+		t.Errorf("expected attachment disposition, got %q", cd)
+	}
+	if cl := rr.Header().Get("Content-Length"); cl != "16" {
+		t.Errorf("expected Content-Length 16, got %q", cl)
+	}
+	if rr.Body.String() != string(data) {
+		t.Errorf("expected file body, got %q", rr.Body.String())
+	}
+
+	// HEAD sends the headers without the body
+	rr = httptest.NewRecorder()
+	handler.WriteFile(rr, httptest.NewRequest("HEAD", "/export", nil), "report.csv", "text/csv", data)
+	if rr.Body.Len() != 0 || rr.Header().Get("Content-Length") != "16" {
+		t.Errorf("expected headers without body for HEAD, got %q %v", rr.Body.String(), rr.Header())
+	}
+}
+
+func TestHandler_StreamFile(t *testing.T) {
+	handler := NewHandler()
+
+	rr := httptest.NewRecorder()
+	err := handler.StreamFile(rr, httptest.NewRequest("GET", "/download", nil), "résumé.bin", "", strings.NewReader("\x00\x01binary"))
+	if err != nil {
+		t.Fatalf("StreamFile failed: %v", err)
+	}
+
+	if ct := rr.Header().Get("Content-Type"); ct != "application/octet-stream" {
+		t.Errorf("expected application/octet-stream, got %q", ct)
+	}
+	if cd := rr.Header().Get("Content-Disposition"); cd != `attachment; filename*=utf-8''r%C3%A9sum%C3%A9.bin` {
+		t.Errorf("expected encoded filename in disposition, got %q", cd)
+	}
+	if rr.Body.String() != "\x00\x01binary" {
+		t.Errorf("expected streamed body, got %q", rr.Body.String())
+	}
+}
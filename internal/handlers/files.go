@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"phantom-server/internal/middleware"
+)
+
+// StaticFiles returns a handler serving files from root for request paths under prefix
+// When the client accepts gzip and a precompressed "<file>.gz" exists alongside the
+// requested file, the precompressed file is served with Content-Encoding: gzip
+func (h *Handler) StaticFiles(prefix, root string) http.Handler {
+	dir := http.Dir(root)
+	fileServer := http.StripPrefix(prefix, http.FileServer(dir))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + strings.TrimPrefix(r.URL.Path, prefix))
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if middleware.AcceptsGzip(r) && h.servePrecompressed(w, r, dir, name) {
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// servePrecompressed serves name+".gz" from dir if it exists, reporting whether it did
+func (h *Handler) servePrecompressed(w http.ResponseWriter, r *http.Request, dir http.Dir, name string) bool {
+	file, err := dir.Open(name + ".gz")
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	// Content type comes from the original file name, not the .gz extension
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Encoding", "gzip")
+
+	http.ServeContent(w, r, name, info.ModTime(), file)
+	return true
+}
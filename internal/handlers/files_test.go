@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeStaticFixtures creates app.js with a precompressed app.js.gz and a plain style.css
+func writeStaticFixtures(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.js"), []byte("console.log('plain')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "style.css"), []byte("body {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte("console.log('precompressed')"))
+	gz.Close()
+	if err := os.WriteFile(filepath.Join(root, "app.js.gz"), compressed.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+func TestHandler_StaticFiles(t *testing.T) {
+	handler := NewHandler()
+	static := handler.StaticFiles("/static/", writeStaticFixtures(t))
+
+	t.Run("precompressed asset present", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/static/app.js", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+		static.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+		if ce := rr.Header().Get("Content-Encoding"); ce != "gzip" {
+			t.Errorf("expected Content-Encoding gzip, got %q", ce)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "text/javascript; charset=utf-8" {
+			t.Errorf("expected JavaScript content type, got %q", ct)
+		}
+
+		reader, err := gzip.NewReader(rr.Body)
+		if err != nil {
+			t.Fatalf("expected gzip body: %v", err)
+		}
+		body, _ := io.ReadAll(reader)
+		if string(body) != "console.log('precompressed')" {
+			t.Errorf("expected precompressed content, got %q", body)
+		}
+	})
+
+	t.Run("client without gzip gets plain file", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/static/app.js", nil)
+		rr := httptest.NewRecorder()
+		static.ServeHTTP(rr, req)
+
+		if ce := rr.Header().Get("Content-Encoding"); ce != "" {
+			t.Errorf("expected no Content-Encoding, got %q", ce)
+		}
+		if rr.Body.String() != "console.log('plain')" {
+			t.Errorf("expected plain content, got %q", rr.Body.String())
+		}
+	})
+
+	t.Run("precompressed asset absent", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/static/style.css", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+		static.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+		if ce := rr.Header().Get("Content-Encoding"); ce != "" {
+			t.Errorf("expected no Content-Encoding, got %q", ce)
+		}
+		if rr.Body.String() != "body {}" {
+			t.Errorf("expected plain content, got %q", rr.Body.String())
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/static/missing.js", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+		static.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+// flagUpdate is the request body for toggling a feature flag
+type flagUpdate struct {
+	Name    string `json:"name" validate:"required"`
+	Enabled *bool  `json:"enabled" validate:"required"`
+}
+
+// LoadFlags replaces the current feature flags with a copy of flags
+func (h *Handler) LoadFlags(flags map[string]bool) {
+	loaded := make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		loaded[name] = enabled
+	}
+
+	h.flagsMu.Lock()
+	h.flags = loaded
+	h.flagsMu.Unlock()
+}
+
+// FlagEnabled reports whether the named feature flag is enabled
+// Unknown flags are treated as disabled
+func (h *Handler) FlagEnabled(name string) bool {
+	h.flagsMu.RLock()
+	defer h.flagsMu.RUnlock()
+	return h.flags[name]
+}
+
+// SetFlag enables or disables the named feature flag
+func (h *Handler) SetFlag(name string, enabled bool) {
+	h.flagsMu.Lock()
+	h.flags[name] = enabled
+	h.flagsMu.Unlock()
+}
+
+// snapshotFlags returns a copy of the current feature flags
+func (h *Handler) snapshotFlags() map[string]bool {
+	h.flagsMu.RLock()
+	defer h.flagsMu.RUnlock()
+
+	snapshot := make(map[string]bool, len(h.flags))
+	for name, enabled := range h.flags {
+		snapshot[name] = enabled
+	}
+	return snapshot
+}
+
+// Flags handles the "/admin/flags" endpoint
+// GET lists every flag and POST toggles one with a {"name", "enabled"} body
+func (h *Handler) Flags(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.writeJSONResponse(w, r, http.StatusOK, Response{
+			Status: "success",
+			Data:   h.snapshotFlags(),
+		})
+	case http.MethodPost:
+		var update flagUpdate
+		if !h.DecodeJSON(w, r, &update) {
+			return
+		}
+		h.SetFlag(update.Name, *update.Enabled)
+
+		h.writeJSONResponse(w, r, http.StatusOK, Response{
+			Status:  "success",
+			Message: "Feature flag updated",
+			Data:    h.snapshotFlags(),
+		})
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		h.writeJSONResponse(w, r, http.StatusMethodNotAllowed, Response{
+			Status:  "error",
+			Message: "Method not allowed",
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_FlagEnabled(t *testing.T) {
+	handler := NewHandler()
+	handler.LoadFlags(map[string]bool{"new-ui": true, "beta": false})
+
+	if !handler.FlagEnabled("new-ui") {
+		t.Error("expected new-ui to be enabled")
+	}
+	if handler.FlagEnabled("beta") {
+		t.Error("expected beta to be disabled")
+	}
+	if handler.FlagEnabled("unknown") {
+		t.Error("expected unknown flag to be disabled")
+	}
+}
+
+func TestHandler_Flags(t *testing.T) {
+	handler := NewHandler()
+	handler.LoadFlags(map[string]bool{"beta": false})
+
+	// Toggle the flag through the endpoint
+	req := httptest.NewRequest("POST", "/admin/flags", strings.NewReader(`{"name":"beta","enabled":true}`))
+	rr := httptest.NewRecorder()
+	handler.Flags(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusOK, rr.Body.String())
+	}
+	if !handler.FlagEnabled("beta") {
+		t.Error("expected beta to be enabled after toggle")
+	}
+
+	// Read the flags back
+	req = httptest.NewRequest("GET", "/admin/flags", nil)
+	rr = httptest.NewRecorder()
+	handler.Flags(rr, req)
+
+	var response struct {
+		Status string          `json:"status"`
+		Data   map[string]bool `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not parse response JSON: %v", err)
+	}
+	if !response.Data["beta"] {
+		t.Errorf("expected beta to be reported as enabled, got %v", response.Data)
+	}
+
+	// A missing enabled field is rejected
+	req = httptest.NewRequest("POST", "/admin/flags", strings.NewReader(`{"name":"beta"}`))
+	rr = httptest.NewRecorder()
+	handler.Flags(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+
+	// Unsupported methods are rejected
+	req = httptest.NewRequest("DELETE", "/admin/flags", nil)
+	rr = httptest.NewRecorder()
+	handler.Flags(rr, req)
+
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldErrors is returned by DecodeForm when individual fields are invalid
+type FieldErrors []FieldError
+
+func (fe FieldErrors) Error() string {
+	parts := make([]string, len(fe))
+	for i, e := range fe {
+		parts[i] = e.Field + ": " + e.Reason
+	}
+	return "invalid fields: " + strings.Join(parts, ", ")
+}
+
+// DecodeForm parses a form-urlencoded request body into the struct pointed to by dst
+// Fields are matched by their `form` tag, falling back to the json name. Missing
+// values leave the field untouched and values without a matching field are ignored.
+// Conversion and `validate:"required"` failures are returned as FieldErrors
+func (h *Handler) DecodeForm(r *http.Request, dst interface{}) error {
+	value := reflect.ValueOf(dst)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("destination must be a pointer to a struct")
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return fmt.Errorf("failed to parse form: %w", err)
+	}
+
+	var fieldErrors FieldErrors
+	value = value.Elem()
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := formFieldName(field)
+		values, ok := r.PostForm[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		if err := setFormValue(value.Field(i), values); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Reason: err.Error()})
+		}
+	}
+
+	for _, e := range ValidateStruct(dst) {
+		e.Field = formFieldName(fieldByJSONName(valueType, e.Field))
+		fieldErrors = append(fieldErrors, e)
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	return nil
+}
+
+// formFieldName returns the name a struct field is known by in a form body
+func formFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("form"), ",")[0]
+	if name == "" || name == "-" {
+		return jsonFieldName(field)
+	}
+	return name
+}
+
+// fieldByJSONName finds the struct field ValidateStruct reported by its json name
+func fieldByJSONName(valueType reflect.Type, name string) reflect.StructField {
+	for i := 0; i < valueType.NumField(); i++ {
+		if field := valueType.Field(i); jsonFieldName(field) == name {
+			return field
+		}
+	}
+	return reflect.StructField{Name: name}
+}
+
+// setFormValue converts form values into a field of a supported kind
+// Slices receive every value, other kinds use the first one
+func setFormValue(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setScalarValue(slice.Index(i), v); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return setScalarValue(field, values[0])
+}
+
+// setScalarValue parses a single form value into a string, bool or numeric field
+func setScalarValue(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("expected bool")
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("expected " + field.Type().String())
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("expected " + field.Type().String())
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return errors.New("expected " + field.Type().String())
+		}
+		field.SetFloat(f)
+	default:
+		return errors.New("unsupported field type " + field.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type signupForm struct {
+	Name   string   `form:"name" validate:"required"`
+	Email  string   `json:"email" validate:"required"`
+	Age    int      `form:"age"`
+	Agree  bool     `form:"agree"`
+	Topics []string `form:"topic"`
+}
+
+func TestHandler_DecodeForm(t *testing.T) {
+	handler := NewHandler()
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest("POST", "/signup", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	t.Run("valid body", func(t *testing.T) {
+		var form signupForm
+		req := newRequest("name=Ada&email=ada%40example.com&age=36&agree=true&topic=go&topic=http")
+		if err := handler.DecodeForm(req, &form); err != nil {
+			t.Fatalf("expected form to decode, got %v", err)
+		}
+
+		if form.Name != "Ada" || form.Email != "ada@example.com" || form.Age != 36 || !form.Agree {
+			t.Errorf("unexpected decoded form: %+v", form)
+		}
+		if len(form.Topics) != 2 || form.Topics[0] != "go" || form.Topics[1] != "http" {
+			t.Errorf("expected both topics, got %v", form.Topics)
+		}
+	})
+
+	t.Run("extra fields are ignored", func(t *testing.T) {
+		var form signupForm
+		req := newRequest("name=Ada&email=ada%40example.com&unknown=value")
+		if err := handler.DecodeForm(req, &form); err != nil {
+			t.Fatalf("expected extra fields to be ignored, got %v", err)
+		}
+	})
+
+	t.Run("missing required field", func(t *testing.T) {
+		var form signupForm
+		err := handler.DecodeForm(newRequest("name=Ada"), &form)
+
+		var fieldErrors FieldErrors
+		if !errors.As(err, &fieldErrors) {
+			t.Fatalf("expected FieldErrors, got %v", err)
+		}
+		if len(fieldErrors) != 1 || fieldErrors[0].Field != "email" || fieldErrors[0].Reason != "required" {
+			t.Errorf("unexpected field errors: %+v", fieldErrors)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var form signupForm
+		err := handler.DecodeForm(newRequest("name=Ada&email=ada%40example.com&age=old"), &form)
+
+		var fieldErrors FieldErrors
+		if !errors.As(err, &fieldErrors) {
+			t.Fatalf("expected FieldErrors, got %v", err)
+		}
+		if len(fieldErrors) != 1 || fieldErrors[0].Field != "age" || fieldErrors[0].Reason != "expected int" {
+			t.Errorf("unexpected field errors: %+v", fieldErrors)
+		}
+	})
+
+	t.Run("non-struct destination", func(t *testing.T) {
+		var s string
+		if err := handler.DecodeForm(newRequest("name=Ada"), &s); err == nil {
+			t.Error("expected error for non-struct destination")
+		}
+	})
+}
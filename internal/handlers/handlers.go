@@ -1,21 +1,111 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	gojson "github.com/goccy/go-json"
+	"phantom-server/internal/middleware"
+	"phantom-server/internal/websocket"
 )
 
 // Handler contains HTTP request handlers for different endpoints
 type Handler struct {
 	// Can include dependencies like database connections, services, etc.
-	// For now, this is a simple struct that can be extended later
+	flagsMu    sync.RWMutex
+	flags      map[string]bool
+	checksMu   sync.RWMutex
+	checks     map[string]HealthCheck
+	websockets *websocket.Registry
+	pool       *WorkerPool
+
+	healthFailsOnShutdown atomic.Bool
+	shuttingDown          atomic.Bool
+	shutdownRetryAfter    time.Duration
+	jsonBOM               bool
+	explicitNulls         bool
+	trustForwarded        bool
+	jsonIndent            string
+	sseHeartbeat          time.Duration
+	healthCheckTimeout    time.Duration
+	multipartLimits       MultipartLimits
+	location              *time.Location
+	startedAt             time.Time
 }
 
 // NewHandler creates a new Handler instance
 func NewHandler() *Handler {
-	return &Handler{}
+	return &Handler{
+		flags:      make(map[string]bool),
+		checks:     make(map[string]HealthCheck),
+		websockets: websocket.NewRegistry(),
+		pool:       NewWorkerPool(0),
+
+		shutdownRetryAfter: middleware.DefaultRetryAfter,
+		multipartLimits:    DefaultMultipartLimits,
+		location:           time.Local,
+		startedAt:          time.Now(),
+	}
+}
+
+// SetLocation sets the time zone timestamps in responses are rendered in, local time by default
+func (h *Handler) SetLocation(loc *time.Location) {
+	h.location = loc
+}
+
+// formatTime renders t as RFC 3339 in the configured time zone
+func (h *Handler) formatTime(t time.Time) string {
+	return t.In(h.location).Format(time.RFC3339)
+}
+
+// WebSockets returns the registry of hijacked WebSocket connections
+// WebSocket endpoints should hijack through it so shutdown can close them gracefully
+func (h *Handler) WebSockets() *websocket.Registry {
+	return h.websockets
+}
+
+// SetHealthFailsOnShutdown controls whether /health reports 503 once shutdown begins
+func (h *Handler) SetHealthFailsOnShutdown(enabled bool) {
+	h.healthFailsOnShutdown.Store(enabled)
+}
+
+// SetShutdownRetryAfter sets the Retry-After sent when /health fails during shutdown
+func (h *Handler) SetShutdownRetryAfter(d time.Duration) {
+	h.shutdownRetryAfter = d
+}
+
+// SetJSONBOM controls whether JSON responses start with a UTF-8 byte order mark
+// Only legacy clients that mis-decode UTF-8 without one need it
+func (h *Handler) SetJSONBOM(enabled bool) {
+	h.jsonBOM = enabled
+}
+
+// DefaultJSONIndent is the indent used for pretty-printed JSON, matching config.WriteConfig
+const DefaultJSONIndent = "  "
+
+// SetJSONIndent pretty-prints JSON responses using indent for each nesting level,
+// e.g. DefaultJSONIndent or "\t". An empty indent writes compact JSON, the default
+func (h *Handler) SetJSONIndent(indent string) {
+	h.jsonIndent = indent
+}
+
+// SetExplicitNulls controls whether empty Response fields are written as null
+// By default an empty message or nil data is omitted from the body entirely
+func (h *Handler) SetExplicitNulls(enabled bool) {
+	h.explicitNulls = enabled
+}
+
+// BeginShutdown marks the server as shutting down
+// It should be called as soon as graceful shutdown starts, before listeners are closed
+func (h *Handler) BeginShutdown() {
+	h.shuttingDown.Store(true)
 }
 
 // Response represents a standard HTTP response structure
@@ -25,6 +115,36 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// explicitResponse mirrors Response without omitempty, so empty fields are written as null
+type explicitResponse struct {
+	Status  string      `json:"status"`
+	Message interface{} `json:"message"`
+	Data    interface{} `json:"data"`
+}
+
+// withExplicitNulls converts a Response so its empty fields encode as null
+// Any other value is returned unchanged
+func withExplicitNulls(data interface{}) interface{} {
+	var response Response
+	switch v := data.(type) {
+	case Response:
+		response = v
+	case *Response:
+		if v == nil {
+			return data
+		}
+		response = *v
+	default:
+		return data
+	}
+
+	explicit := explicitResponse{Status: response.Status, Data: response.Data}
+	if response.Message != "" {
+		explicit.Message = response.Message
+	}
+	return explicit
+}
+
 // Home handles the "/" endpoint and returns a welcome message
 func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
 	response := Response{
@@ -36,24 +156,56 @@ func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	h.writeJSONResponse(w, http.StatusOK, response)
+	h.writeJSONResponse(w, r, http.StatusOK, response)
 }
 
 // Health handles the "/health" endpoint and returns health status
+// When HealthFailsOnShutdown is set it returns 503 once shutdown has begun,
+// so probes stop routing traffic to an instance that is draining.
+// Registered health checks are run and reported under "checks", and any failing
+// check makes the endpoint return 503
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	if h.healthFailsOnShutdown.Load() && h.shuttingDown.Load() {
+		response := Response{
+			Status:  "error",
+			Message: "Server is shutting down",
+			Data: map[string]interface{}{
+				"status": "shutting_down",
+			},
+		}
+		middleware.SetRetryAfter(w, h.shutdownRetryAfter)
+		h.writeJSONResponse(w, r, http.StatusServiceUnavailable, response)
+		return
+	}
+
+	checks := map[string]string{
+		"server": CheckHealthy,
+	}
+	statusCode := http.StatusOK
+	for _, dependency := range h.runHealthChecks(r.Context()) {
+		checks[dependency.Name] = dependency.Status
+		if dependency.Status != CheckHealthy {
+			statusCode = http.StatusServiceUnavailable
+		}
+	}
+
 	response := Response{
 		Status:  "healthy",
 		Message: "Server is running",
 		Data: map[string]interface{}{
-			"uptime": "running",
-			"status": "ok",
-			"checks": map[string]string{
-				"server": "healthy",
-			},
+			"uptime":     "running",
+			"status":     "ok",
+			"started_at": h.formatTime(h.startedAt),
+			"time":       h.formatTime(time.Now()),
+			"checks":     checks,
 		},
 	}
+	if statusCode != http.StatusOK {
+		response.Status = CheckUnhealthy
+		response.Message = "A dependency is unhealthy"
+	}
 
-	h.writeJSONResponse(w, http.StatusOK, response)
+	h.writeJSONResponse(w, r, statusCode, response)
 }
 
 // NotFound handles undefined routes and returns a 404 error response
@@ -67,19 +219,97 @@ func (h *Handler) NotFound(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	h.writeJSONResponse(w, http.StatusNotFound, response)
+	h.writeJSONResponse(w, r, http.StatusNotFound, response)
+}
+
+// UnsupportedVersion returns a 406 error response listing the API versions a route supports
+func (h *Handler) UnsupportedVersion(w http.ResponseWriter, r *http.Request, version string, supported []string) {
+	response := Response{
+		Status:  "error",
+		Message: "The requested API version is not supported",
+		Data: map[string]interface{}{
+			"path":      r.URL.Path,
+			"version":   version,
+			"supported": supported,
+		},
+	}
+
+	h.writeJSONResponse(w, r, http.StatusNotAcceptable, response)
+}
+
+// Redirect redirects the request to url with 301 Moved Permanently when permanent
+// is true and 302 Found otherwise. Behind a trusted proxy path-absolute urls are made
+// absolute against the external base URL, so redirects point at the public host
+func (h *Handler) Redirect(w http.ResponseWriter, r *http.Request, url string, permanent bool) {
+	statusCode := http.StatusFound
+	if permanent {
+		statusCode = http.StatusMovedPermanently
+	}
+
+	http.Redirect(w, r, h.absoluteURL(r, url), statusCode)
+}
+
+// RedirectTo returns a handler that redirects every request to url, for registering redirect routes
+func (h *Handler) RedirectTo(url string, permanent bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.Redirect(w, r, url, permanent)
+	}
 }
 
+// utf8BOM is the UTF-8 byte order mark optionally prepended to JSON responses
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
 // writeJSONResponse writes a JSON response using goccy/go-json
-func (h *Handler) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// The body is encoded before anything is written so an encoding failure can still fall
+// back to an error body. If the client has already gone away, or writing the body fails,
+// the response is abandoned with a log line instead of retrying on a dead connection.
+// Once a server-side deadline such as the handler timeout has passed the response is
+// also abandoned, leaving the timeout middleware to answer
+func (h *Handler) writeJSONResponse(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	if err := r.Context().Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Printf("Deadline exceeded before response to %s %s was written", r.Method, r.URL.Path)
+		} else {
+			log.Printf("Client disconnected before response to %s %s was written: %v", r.Method, r.URL.Path, err)
+		}
+		return
+	}
 
-	if err := gojson.NewEncoder(w).Encode(data); err != nil {
+	if h.explicitNulls {
+		data = withExplicitNulls(data)
+	}
+
+	var body []byte
+	var err error
+	if h.jsonIndent != "" {
+		body, err = gojson.MarshalIndent(data, "", h.jsonIndent)
+	} else {
+		body, err = gojson.Marshal(data)
+	}
+	if err != nil {
 		// Fallback to standard library if goccy/go-json fails
-		json.NewEncoder(w).Encode(map[string]string{
+		body, _ = json.Marshal(map[string]string{
 			"status":  "error",
 			"message": "Failed to encode response",
 		})
 	}
+
+	if h.jsonBOM {
+		body = append(utf8BOM[:len(utf8BOM):len(utf8BOM)], body...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	// HEAD responses carry the headers of the equivalent GET without its body
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)+1))
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		log.Printf("Failed to write response to %s %s: %v", r.Method, r.URL.Path, err)
+	}
 }
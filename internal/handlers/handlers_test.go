@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestHandler_Home(t *testing.T) {
@@ -156,3 +163,279 @@ func TestHandler_NotFound(t *testing.T) {
 		t.Error("expected data field to be present")
 	}
 }
+
+func TestHandler_Redirect(t *testing.T) {
+	handler := NewHandler()
+
+	tests := []struct {
+		name      string
+		permanent bool
+		expected  int
+	}{
+		{"permanent", true, http.StatusMovedPermanently},
+		{"temporary", false, http.StatusFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/old", nil)
+			rr := httptest.NewRecorder()
+
+			handler.Redirect(rr, req, "/new", tt.permanent)
+
+			if status := rr.Code; status != tt.expected {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expected)
+			}
+
+			if location := rr.Header().Get("Location"); location != "/new" {
+				t.Errorf("handler returned wrong Location: got %v want %v", location, "/new")
+			}
+		})
+	}
+
+	t.Run("RedirectTo", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/old", nil)
+		rr := httptest.NewRecorder()
+
+		handler.RedirectTo("https://example.com/new", true)(rr, req)
+
+		if status := rr.Code; status != http.StatusMovedPermanently {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusMovedPermanently)
+		}
+
+		if location := rr.Header().Get("Location"); location != "https://example.com/new" {
+			t.Errorf("handler returned wrong Location: got %v", location)
+		}
+	})
+}
+
+// failingWriter is a ResponseWriter whose writes fail like a broken pipe
+type failingWriter struct {
+	header http.Header
+	writes int
+}
+
+func (fw *failingWriter) Header() http.Header { return fw.header }
+
+func (fw *failingWriter) WriteHeader(statusCode int) {}
+
+func (fw *failingWriter) Write(b []byte) (int, error) {
+	fw.writes++
+	return 0, errors.New("write: broken pipe")
+}
+
+func TestHandler_WriteJSONResponseClientGone(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	handler := NewHandler()
+
+	t.Run("canceled request context", func(t *testing.T) {
+		buf.Reset()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		rr := httptest.NewRecorder()
+		handler.Home(rr, req)
+
+		if rr.Body.Len() != 0 {
+			t.Errorf("expected no body to be written, got %s", rr.Body.String())
+		}
+		if !strings.Contains(buf.String(), "Client disconnected") {
+			t.Errorf("expected disconnect to be logged, got: %s", buf.String())
+		}
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		buf.Reset()
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+
+		req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		rr := httptest.NewRecorder()
+		handler.Home(rr, req)
+
+		if rr.Body.Len() != 0 {
+			t.Errorf("expected no body to be written, got %s", rr.Body.String())
+		}
+		if strings.Contains(buf.String(), "Client disconnected") || !strings.Contains(buf.String(), "Deadline exceeded") {
+			t.Errorf("expected a server-side deadline to be logged, got: %s", buf.String())
+		}
+	})
+
+	t.Run("failed write is not retried", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest("GET", "/", nil)
+		fw := &failingWriter{header: http.Header{}}
+		handler.Home(fw, req)
+
+		if fw.writes != 1 {
+			t.Errorf("expected exactly one write attempt, got %d", fw.writes)
+		}
+		if !strings.Contains(buf.String(), "broken pipe") {
+			t.Errorf("expected write failure to be logged, got: %s", buf.String())
+		}
+	})
+}
+
+func TestHandler_HealthFailsOnShutdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected int
+	}{
+		{"enabled", true, http.StatusServiceUnavailable},
+		{"disabled", false, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler()
+			handler.SetHealthFailsOnShutdown(tt.enabled)
+
+			rr := httptest.NewRecorder()
+			handler.Health(rr, httptest.NewRequest("GET", "/health", nil))
+			if status := rr.Code; status != http.StatusOK {
+				t.Errorf("handler returned wrong status code before shutdown: got %v want %v", status, http.StatusOK)
+			}
+
+			handler.BeginShutdown()
+
+			rr = httptest.NewRecorder()
+			handler.Health(rr, httptest.NewRequest("GET", "/health", nil))
+			if status := rr.Code; status != tt.expected {
+				t.Errorf("handler returned wrong status code after shutdown: got %v want %v", status, tt.expected)
+			}
+			if tt.enabled {
+				if retryAfter := rr.Header().Get("Retry-After"); retryAfter != "1" {
+					t.Errorf("handler returned wrong Retry-After: got %q want %q", retryAfter, "1")
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_JSONBOM(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{"disabled", false},
+		{"enabled", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler()
+			handler.SetJSONBOM(tt.enabled)
+
+			rr := httptest.NewRecorder()
+			handler.Home(rr, httptest.NewRequest("GET", "/", nil))
+
+			body := rr.Body.Bytes()
+			hasBOM := bytes.HasPrefix(body, []byte{0xEF, 0xBB, 0xBF})
+			if hasBOM != tt.enabled {
+				t.Errorf("expected BOM present to be %v, got body %q", tt.enabled, body)
+			}
+
+			var response Response
+			if err := json.Unmarshal(bytes.TrimPrefix(body, []byte{0xEF, 0xBB, 0xBF}), &response); err != nil {
+				t.Errorf("could not parse response JSON: %v", err)
+			}
+		})
+	}
+}
+
+func TestHandler_ExplicitNulls(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected string
+	}{
+		{"omitted by default", false, `{"status":"success"}`},
+		{"explicit nulls", true, `{"status":"success","message":null,"data":null}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler()
+			handler.SetExplicitNulls(tt.enabled)
+
+			rr := httptest.NewRecorder()
+			handler.writeJSONResponse(rr, httptest.NewRequest("GET", "/", nil), http.StatusOK, Response{Status: "success"})
+
+			if body := strings.TrimSpace(rr.Body.String()); body != tt.expected {
+				t.Errorf("handler returned wrong body: got %s want %s", body, tt.expected)
+			}
+		})
+	}
+
+	t.Run("populated fields are unchanged", func(t *testing.T) {
+		handler := NewHandler()
+		handler.SetExplicitNulls(true)
+
+		rr := httptest.NewRecorder()
+		handler.Home(rr, httptest.NewRequest("GET", "/", nil))
+
+		var response Response
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("could not parse response JSON: %v", err)
+		}
+		if response.Message != "Welcome to the HTTP server!" || response.Data == nil {
+			t.Errorf("expected message and data to be kept, got %+v", response)
+		}
+	})
+}
+
+func TestHandler_JSONIndent(t *testing.T) {
+	tests := []struct {
+		name     string
+		indent   string
+		expected string
+	}{
+		{"compact by default", "", "{\"status\":\"success\",\"data\":{\"id\":1}}\n"},
+		{"two spaces", DefaultJSONIndent, "{\n  \"status\": \"success\",\n  \"data\": {\n    \"id\": 1\n  }\n}\n"},
+		{"tabs", "\t", "{\n\t\"status\": \"success\",\n\t\"data\": {\n\t\t\"id\": 1\n\t}\n}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler()
+			handler.SetJSONIndent(tt.indent)
+
+			rr := httptest.NewRecorder()
+			handler.writeJSONResponse(rr, httptest.NewRequest("GET", "/", nil), http.StatusOK,
+				Response{Status: "success", Data: map[string]int{"id": 1}})
+
+			if body := rr.Body.String(); body != tt.expected {
+				t.Errorf("handler returned wrong body: got %q want %q", body, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandler_HealthTimezone(t *testing.T) {
+	handler := NewHandler()
+	handler.SetLocation(time.FixedZone("UTC+5:30", 5*60*60+30*60))
+
+	rr := httptest.NewRecorder()
+	handler.Health(rr, httptest.NewRequest("GET", "/health", nil))
+
+	var response struct {
+		Data struct {
+			StartedAt string `json:"started_at"`
+			Time      string `json:"time"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not parse response JSON: %v", err)
+	}
+
+	for name, value := range map[string]string{"started_at": response.Data.StartedAt, "time": response.Data.Time} {
+		if !strings.HasSuffix(value, "+05:30") {
+			t.Errorf("expected %s rendered in the configured zone, got %q", name, value)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Health check statuses reported by /health and /debug/dependencies
+const (
+	CheckHealthy   = "healthy"
+	CheckUnhealthy = "unhealthy"
+	CheckTimedOut  = "timed_out"
+)
+
+// DefaultHealthCheckTimeout bounds how long a health check run may take
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// SetHealthCheckTimeout sets the deadline shared by all checks in one run
+// Zero or less uses DefaultHealthCheckTimeout
+func (h *Handler) SetHealthCheckTimeout(d time.Duration) {
+	h.healthCheckTimeout = d
+}
+
+// HealthCheck reports whether a dependency such as a database is reachable
+// It returns nil when the dependency is healthy and should honour ctx cancellation
+type HealthCheck func(ctx context.Context) error
+
+// DependencyStatus is the result of running one registered health check
+type DependencyStatus struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// RegisterHealthCheck adds a named dependency check run by /health and listed by
+// /debug/dependencies. Registering a name again replaces its check
+func (h *Handler) RegisterHealthCheck(name string, check HealthCheck) {
+	h.checksMu.Lock()
+	h.checks[name] = check
+	h.checksMu.Unlock()
+}
+
+// runHealthChecks runs every registered check concurrently and returns their results
+// sorted by name. The checks share one deadline, and a check still running when it
+// passes is reported as timed out without waiting for it to return
+func (h *Handler) runHealthChecks(ctx context.Context) []DependencyStatus {
+	h.checksMu.RLock()
+	names := make([]string, 0, len(h.checks))
+	checks := make(map[string]HealthCheck, len(h.checks))
+	for name, check := range h.checks {
+		names = append(names, name)
+		checks[name] = check
+	}
+	h.checksMu.RUnlock()
+	sort.Strings(names)
+
+	timeout := h.healthCheckTimeout
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Buffered so checks finishing after the deadline never block
+	start := time.Now()
+	done := make(chan DependencyStatus, len(names))
+	for _, name := range names {
+		go func(name string, check HealthCheck) {
+			checkStart := time.Now()
+			err := runHealthCheck(ctx, name, check)
+
+			result := DependencyStatus{Name: name, Status: CheckHealthy}
+			if err != nil {
+				result.Status = CheckUnhealthy
+				result.Error = err.Error()
+			}
+			result.Duration = time.Since(checkStart).String()
+			done <- result
+		}(name, checks[name])
+	}
+
+	collected := make(map[string]DependencyStatus, len(names))
+	for len(collected) < len(names) {
+		select {
+		case result := <-done:
+			collected[result.Name] = result
+		case <-ctx.Done():
+			for _, name := range names {
+				if _, finished := collected[name]; !finished {
+					collected[name] = DependencyStatus{
+						Name:     name,
+						Status:   CheckTimedOut,
+						Error:    ctx.Err().Error(),
+						Duration: time.Since(start).String(),
+					}
+				}
+			}
+		}
+	}
+
+	results := make([]DependencyStatus, 0, len(names))
+	for _, name := range names {
+		results = append(results, collected[name])
+	}
+	return results
+}
+
+// runHealthCheck runs check, reporting a panic as its error
+// Checks run on their own goroutines, where an unrecovered panic would crash the server
+func runHealthCheck(ctx context.Context, name string, check HealthCheck) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("Health check %s panicked: %v", name, p)
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return check(ctx)
+}
+
+// Dependencies handles the "/debug/dependencies" endpoint
+// It runs every registered health check and lists each dependency with its current status
+func (h *Handler) Dependencies(w http.ResponseWriter, r *http.Request) {
+	dependencies := h.runHealthChecks(r.Context())
+
+	status := CheckHealthy
+	for _, dependency := range dependencies {
+		if dependency.Status != CheckHealthy {
+			status = CheckUnhealthy
+		}
+	}
+
+	h.writeJSONResponse(w, r, http.StatusOK, Response{
+		Status: "success",
+		Data: map[string]interface{}{
+			"status":       status,
+			"dependencies": dependencies,
+		},
+	})
+}
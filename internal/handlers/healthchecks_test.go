@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_Dependencies(t *testing.T) {
+	handler := NewHandler()
+	handler.RegisterHealthCheck("database", func(ctx context.Context) error { return nil })
+	handler.RegisterHealthCheck("cache", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	rr := httptest.NewRecorder()
+	handler.Dependencies(rr, httptest.NewRequest("GET", "/debug/dependencies", nil))
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response struct {
+		Data struct {
+			Status       string             `json:"status"`
+			Dependencies []DependencyStatus `json:"dependencies"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not parse response JSON: %v", err)
+	}
+
+	if response.Data.Status != CheckUnhealthy {
+		t.Errorf("expected overall status %q, got %q", CheckUnhealthy, response.Data.Status)
+	}
+	if len(response.Data.Dependencies) != 2 {
+		t.Fatalf("expected 2 dependencies, got %+v", response.Data.Dependencies)
+	}
+
+	cache, database := response.Data.Dependencies[0], response.Data.Dependencies[1]
+	if cache.Name != "cache" || cache.Status != CheckUnhealthy || cache.Error != "connection refused" {
+		t.Errorf("expected cache to be unhealthy with its error, got %+v", cache)
+	}
+	if database.Name != "database" || database.Status != CheckHealthy || database.Error != "" {
+		t.Errorf("expected database to be healthy, got %+v", database)
+	}
+}
+
+func TestHandler_HealthCheckPanic(t *testing.T) {
+	handler := NewHandler()
+	handler.RegisterHealthCheck("flaky", func(ctx context.Context) error { panic("nil driver") })
+	handler.RegisterHealthCheck("database", func(ctx context.Context) error { return nil })
+
+	results := handler.runHealthChecks(context.Background())
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if flaky := results[1]; flaky.Name != "flaky" || flaky.Status != CheckUnhealthy || flaky.Error != "panic: nil driver" {
+		t.Errorf("expected the panicking check to be unhealthy with the panic value, got %+v", flaky)
+	}
+	if database := results[0]; database.Status != CheckHealthy {
+		t.Errorf("expected other checks to still run, got %+v", database)
+	}
+}
+
+func TestHandler_HealthRunsChecks(t *testing.T) {
+	handler := NewHandler()
+	handler.RegisterHealthCheck("database", func(ctx context.Context) error { return nil })
+
+	rr := httptest.NewRecorder()
+	handler.Health(rr, httptest.NewRequest("GET", "/health", nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	// A failing check makes the endpoint unhealthy
+	handler.RegisterHealthCheck("database", func(ctx context.Context) error { return errors.New("down") })
+	rr = httptest.NewRecorder()
+	handler.Health(rr, httptest.NewRequest("GET", "/health", nil))
+	if status := rr.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusServiceUnavailable)
+	}
+
+	var response struct {
+		Status string `json:"status"`
+		Data   struct {
+			Checks map[string]string `json:"checks"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not parse response JSON: %v", err)
+	}
+	if response.Status != CheckUnhealthy || response.Data.Checks["database"] != CheckUnhealthy {
+		t.Errorf("expected database to be reported unhealthy, got %+v", response)
+	}
+}
+
+func TestHandler_HealthCheckTimeout(t *testing.T) {
+	handler := NewHandler()
+	handler.SetHealthCheckTimeout(50 * time.Millisecond)
+
+	// The hung check ignores ctx, so only the shared deadline can end the run
+	release := make(chan struct{})
+	defer close(release)
+	handler.RegisterHealthCheck("hung", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	handler.RegisterHealthCheck("database", func(ctx context.Context) error { return nil })
+
+	start := time.Now()
+	rr := httptest.NewRecorder()
+	handler.Health(rr, httptest.NewRequest("GET", "/health", nil))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected /health to respond around the deadline, took %v", elapsed)
+	}
+
+	if status := rr.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusServiceUnavailable)
+	}
+
+	var response struct {
+		Data struct {
+			Checks map[string]string `json:"checks"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not parse response JSON: %v", err)
+	}
+	if got := response.Data.Checks["hung"]; got != CheckTimedOut {
+		t.Errorf("expected hung check to be %q, got %q", CheckTimedOut, got)
+	}
+	if got := response.Data.Checks["database"]; got != CheckHealthy {
+		t.Errorf("expected database check to be %q, got %q", CheckHealthy, got)
+	}
+}
+
+func TestHandler_HealthChecksRunConcurrently(t *testing.T) {
+	handler := NewHandler()
+	for _, name := range []string{"a", "b", "c"} {
+		handler.RegisterHealthCheck(name, func(ctx context.Context) error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		})
+	}
+
+	start := time.Now()
+	results := handler.runHealthChecks(context.Background())
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("expected checks to run concurrently, took %v", elapsed)
+	}
+	for i, name := range []string{"a", "b", "c"} {
+		if results[i].Name != name || results[i].Status != CheckHealthy {
+			t.Errorf("expected %s to be healthy in name order, got %+v", name, results[i])
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// TimeLayoutUnix selects Unix epoch seconds instead of a layout for decoded Time fields
+const TimeLayoutUnix = "unix"
+
+// errInvalidTime is wrapped by every Time decoding error so DecodeJSON can report it
+var errInvalidTime = errors.New("invalid time")
+
+// jsonTimeFormat is how Time fields in request bodies are parsed
+type jsonTimeFormat struct {
+	layout   string
+	location *time.Location
+}
+
+// timeFormat holds the process-wide format set by SetTimeLayout
+var timeFormat atomic.Pointer[jsonTimeFormat]
+
+// SetTimeLayout sets how Time fields are decoded from JSON request bodies: a
+// time.Parse layout, or TimeLayoutUnix for epoch seconds. Empty restores RFC 3339.
+// Layouts without a zone offset are read in loc, UTC when loc is nil. The setting
+// applies to every decode in the process, so it should be set once at startup
+func SetTimeLayout(layout string, loc *time.Location) {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	timeFormat.Store(&jsonTimeFormat{layout: layout, location: loc})
+}
+
+// currentTimeFormat returns the format set by SetTimeLayout, RFC 3339 in UTC by default
+func currentTimeFormat() jsonTimeFormat {
+	if format := timeFormat.Load(); format != nil {
+		return *format
+	}
+	return jsonTimeFormat{layout: time.RFC3339, location: time.UTC}
+}
+
+// Time is a time.Time decoded with the layout set by SetTimeLayout
+// Use it instead of time.Time in request bodies whose clients send epoch seconds
+// or a custom layout. It encodes as RFC 3339 like time.Time
+type Time struct {
+	time.Time
+}
+
+// UnmarshalJSON decodes epoch seconds or a string in the configured layout
+// null leaves t unchanged
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	format := currentTimeFormat()
+	if format.layout == TimeLayoutUnix {
+		seconds, err := strconv.ParseFloat(strings.Trim(string(data), `"`), 64)
+		if err != nil {
+			return fmt.Errorf("%w: expected Unix epoch seconds, got %s", errInvalidTime, data)
+		}
+		whole := int64(seconds)
+		t.Time = time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second))).In(format.location)
+		return nil
+	}
+
+	// Decode as a JSON string, whose escapes such as \/ differ from Go's
+	var value string
+	if err := gojson.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("%w: expected a time string, got %s", errInvalidTime, data)
+	}
+	parsed, err := time.ParseInLocation(format.layout, value, format.location)
+	if err != nil {
+		return fmt.Errorf("%w: expected layout %q: %v", errInvalidTime, format.layout, err)
+	}
+	t.Time = parsed
+	return nil
+}
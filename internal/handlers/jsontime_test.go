@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type timedRequest struct {
+	At Time `json:"at" validate:"required"`
+}
+
+func TestDecodeJSONTimeLayouts(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		layout   string
+		location *time.Location
+		body     string
+		expected time.Time
+	}{
+		{"RFC 3339 by default", "", nil, `{"at": "2024-03-01T12:30:00+02:00"}`, time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{"epoch seconds", TimeLayoutUnix, nil, `{"at": 1709296200}`, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"fractional epoch seconds", TimeLayoutUnix, nil, `{"at": 1709296200.5}`, time.Date(2024, 3, 1, 12, 30, 0, int(500*time.Millisecond), time.UTC)},
+		{"epoch seconds as string", TimeLayoutUnix, nil, `{"at": "1709296200"}`, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"JSON escapes", "2006-01-02T15:04:05Z/", nil, `{"at": "2024-03-01T12:30:00Z\/"}`, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"custom layout", "2006-01-02 15:04", nil, `{"at": "2024-03-01 12:30"}`, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"custom layout in time zone", "02/01/2006 15:04", berlin, `{"at": "01/03/2024 12:30"}`, time.Date(2024, 3, 1, 11, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTimeLayout(tt.layout, tt.location)
+			defer SetTimeLayout("", nil)
+
+			handler := NewHandler()
+			req := httptest.NewRequest("POST", "/events", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			var dst timedRequest
+			if !handler.DecodeJSON(rr, req, &dst) {
+				t.Fatalf("expected body to decode, got %d: %s", rr.Code, rr.Body.String())
+			}
+			if !dst.At.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, dst.At.Time)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONInvalidTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		body   string
+	}{
+		{"not RFC 3339", "", `{"at": "yesterday"}`},
+		{"not epoch seconds", TimeLayoutUnix, `{"at": "2024-03-01T12:30:00Z"}`},
+		{"Go-only escape", "2006-01-02 15:04", `{"at": "2024-03-01\x2012:30"}`},
+		{"wrong custom layout", "2006-01-02 15:04", `{"at": "2024-03-01T12:30:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTimeLayout(tt.layout, nil)
+			defer SetTimeLayout("", nil)
+
+			handler := NewHandler()
+			req := httptest.NewRequest("POST", "/events", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			var dst timedRequest
+			if handler.DecodeJSON(rr, req, &dst) {
+				t.Fatal("expected invalid time to be rejected")
+			}
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), "invalid time") {
+				t.Errorf("expected an invalid time message, got %s", rr.Body.String())
+			}
+		})
+	}
+}
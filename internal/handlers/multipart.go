@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/textproto"
+)
+
+// MultipartLimits bounds how much of a multipart form is accepted
+type MultipartLimits struct {
+	MaxParts int   // Maximum number of parts, 0 uses the handler's limit
+	MaxBytes int64 // Maximum size of the request body in bytes, 0 uses the handler's limit
+}
+
+// DefaultMultipartLimits are the handler's multipart limits until SetMultipartLimits is called
+var DefaultMultipartLimits = MultipartLimits{MaxParts: 100, MaxBytes: 10 << 20}
+
+// SetMultipartLimits sets the limits ParseMultipartForm applies when a caller gives
+// none. A zero field here leaves that limit unenforced
+// It should be called before the server starts handling requests
+func (h *Handler) SetMultipartLimits(limits MultipartLimits) {
+	h.multipartLimits = limits
+}
+
+// UploadedFile is a file part read from a multipart form
+type UploadedFile struct {
+	Filename string
+	Header   textproto.MIMEHeader
+	Content  []byte
+}
+
+// MultipartForm holds the values and files parsed from a multipart form
+type MultipartForm struct {
+	Values map[string][]string
+	Files  map[string][]UploadedFile
+}
+
+// errTooManyParts is returned when a form has more parts than allowed
+var errTooManyParts = errors.New("too many multipart parts")
+
+// ParseMultipartForm parses a multipart/form-data body within the given limits
+// A zero limit falls back to the one set with SetMultipartLimits. Bodies exceeding the part count or byte limit receive a 413 response and malformed
+// forms a 400; in both cases it returns false and callers should simply return
+func (h *Handler) ParseMultipartForm(w http.ResponseWriter, r *http.Request, limits MultipartLimits) (*MultipartForm, bool) {
+	if limits.MaxParts <= 0 {
+		limits.MaxParts = h.multipartLimits.MaxParts
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = h.multipartLimits.MaxBytes
+	}
+	if limits.MaxBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBytes)
+	}
+
+	form, err := readMultipartForm(r, limits.MaxParts)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.Is(err, errTooManyParts) || errors.As(err, &maxBytesErr) {
+			h.writeJSONResponse(w, r, http.StatusRequestEntityTooLarge, Response{
+				Status:  "error",
+				Message: "Multipart form exceeds the allowed limits",
+			})
+			return nil, false
+		}
+		h.writeJSONResponse(w, r, http.StatusBadRequest, Response{
+			Status:  "error",
+			Message: "Request body must be a valid multipart form",
+		})
+		return nil, false
+	}
+
+	return form, true
+}
+
+// readMultipartForm reads every part into memory, failing once maxParts is exceeded
+// Memory use is bounded by the body size limit applied by the caller
+func readMultipartForm(r *http.Request, maxParts int) (*MultipartForm, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	form := &MultipartForm{
+		Values: make(map[string][]string),
+		Files:  make(map[string][]UploadedFile),
+	}
+
+	for parts := 0; ; parts++ {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if maxParts > 0 && parts >= maxParts {
+			part.Close()
+			return nil, errTooManyParts
+		}
+
+		content, err := io.ReadAll(part)
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		name := part.FormName()
+		if part.FileName() != "" {
+			form.Files[name] = append(form.Files[name], UploadedFile{
+				Filename: part.FileName(),
+				Header:   part.Header,
+				Content:  content,
+			})
+		} else {
+			form.Values[name] = append(form.Values[name], string(content))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newMultipartRequest builds a POST request with the given number of text fields and one file
+func newMultipartRequest(t *testing.T, fields int) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for i := 0; i < fields; i++ {
+		if err := writer.WriteField("field"+strconv.Itoa(i), "value"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := writer.CreateFormFile("upload", "notes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte("file contents"))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestHandler_ParseMultipartForm(t *testing.T) {
+	handler := NewHandler()
+
+	t.Run("under the part limit", func(t *testing.T) {
+		req := newMultipartRequest(t, 2)
+		rr := httptest.NewRecorder()
+
+		form, ok := handler.ParseMultipartForm(rr, req, MultipartLimits{MaxParts: 3, MaxBytes: 1 << 20})
+		if !ok {
+			t.Fatalf("expected form to be accepted, got status %d: %s", rr.Code, rr.Body.String())
+		}
+
+		if len(form.Values) != 2 || form.Values["field0"][0] != "value" {
+			t.Errorf("unexpected form values: %v", form.Values)
+		}
+		files := form.Files["upload"]
+		if len(files) != 1 || files[0].Filename != "notes.txt" || string(files[0].Content) != "file contents" {
+			t.Errorf("unexpected form files: %+v", form.Files)
+		}
+	})
+
+	t.Run("over the part limit", func(t *testing.T) {
+		req := newMultipartRequest(t, 5)
+		rr := httptest.NewRecorder()
+
+		if _, ok := handler.ParseMultipartForm(rr, req, MultipartLimits{MaxParts: 3}); ok {
+			t.Fatal("expected form to be rejected")
+		}
+
+		if status := rr.Code; status != http.StatusRequestEntityTooLarge {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusRequestEntityTooLarge)
+		}
+	})
+
+	t.Run("over the byte limit", func(t *testing.T) {
+		req := newMultipartRequest(t, 2)
+		rr := httptest.NewRecorder()
+
+		if _, ok := handler.ParseMultipartForm(rr, req, MultipartLimits{MaxBytes: 64}); ok {
+			t.Fatal("expected form to be rejected")
+		}
+
+		if status := rr.Code; status != http.StatusRequestEntityTooLarge {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusRequestEntityTooLarge)
+		}
+	})
+
+	t.Run("configured limits apply when none are given", func(t *testing.T) {
+		limited := NewHandler()
+		limited.SetMultipartLimits(MultipartLimits{MaxParts: 2, MaxBytes: 1 << 20})
+		req := newMultipartRequest(t, 5)
+		rr := httptest.NewRecorder()
+
+		if _, ok := limited.ParseMultipartForm(rr, req, MultipartLimits{}); ok {
+			t.Fatal("expected form to be rejected by the configured part limit")
+		}
+		if status := rr.Code; status != http.StatusRequestEntityTooLarge {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusRequestEntityTooLarge)
+		}
+	})
+
+	t.Run("not a multipart body", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/upload", bytes.NewBufferString(`{"a":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		if _, ok := handler.ParseMultipartForm(rr, req, MultipartLimits{}); ok {
+			t.Fatal("expected form to be rejected")
+		}
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import "net/http"
+
+// Pagination describes the page of results included in a list response
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// PaginatedData is the data envelope for paginated list responses
+type PaginatedData struct {
+	Items      interface{} `json:"items"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// newPagination computes pagination metadata, rounding the page count up
+func newPagination(page, pageSize, total int) Pagination {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+
+	return Pagination{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// writePaginated writes a list response wrapping items with pagination metadata
+func (h *Handler) writePaginated(w http.ResponseWriter, r *http.Request, items interface{}, page, pageSize, total int) {
+	response := Response{
+		Status: "success",
+		Data: PaginatedData{
+			Items:      items,
+			Pagination: newPagination(page, pageSize, total),
+		},
+	}
+
+	h.writeJSONResponse(w, r, http.StatusOK, response)
+}
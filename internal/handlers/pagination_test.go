@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_WritePaginated(t *testing.T) {
+	handler := NewHandler()
+
+	tests := []struct {
+		name       string
+		page       int
+		pageSize   int
+		total      int
+		totalPages int
+	}{
+		{"exact division", 1, 10, 30, 3},
+		{"rounds up", 2, 10, 31, 4},
+		{"fewer than a page", 1, 10, 3, 1},
+		{"empty list", 1, 10, 0, 0},
+		{"invalid page size", 1, 0, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/items", nil)
+			rr := httptest.NewRecorder()
+
+			handler.writePaginated(rr, req, []string{"a", "b", "c"}, tt.page, tt.pageSize, tt.total)
+
+			if status := rr.Code; status != http.StatusOK {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+			}
+
+			var response struct {
+				Status string `json:"status"`
+				Data   struct {
+					Items      []string   `json:"items"`
+					Pagination Pagination `json:"pagination"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("could not parse response JSON: %v", err)
+			}
+
+			expected := Pagination{Page: tt.page, PageSize: tt.pageSize, Total: tt.total, TotalPages: tt.totalPages}
+			if response.Data.Pagination != expected {
+				t.Errorf("expected pagination %+v, got %+v", expected, response.Data.Pagination)
+			}
+			if len(response.Data.Items) != 3 {
+				t.Errorf("expected 3 items, got %v", response.Data.Items)
+			}
+		})
+	}
+}
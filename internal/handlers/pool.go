@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"runtime"
+)
+
+// WorkerPoolSizeGOMAXPROCS is the pool size that opts in to runtime.GOMAXPROCS(0)
+// slots, one job per usable CPU, which suits CPU-bound work
+const WorkerPoolSizeGOMAXPROCS = -1
+
+// WorkerPool caps how many expensive jobs run at once
+// A nil pool, or one created with a size of 0, runs every job without limit
+type WorkerPool struct {
+	slots chan struct{}
+}
+
+// NewWorkerPool creates a pool running at most size jobs concurrently
+// A size of 0 means unbounded, and WorkerPoolSizeGOMAXPROCS sizes the pool to
+// runtime.GOMAXPROCS(0)
+func NewWorkerPool(size int) *WorkerPool {
+	if size == WorkerPoolSizeGOMAXPROCS {
+		size = runtime.GOMAXPROCS(0)
+	}
+	if size <= 0 {
+		return &WorkerPool{}
+	}
+	return &WorkerPool{slots: make(chan struct{}, size)}
+}
+
+// Size returns the number of jobs the pool runs concurrently, or 0 when unbounded
+func (p *WorkerPool) Size() int {
+	if p == nil {
+		return 0
+	}
+	return cap(p.slots)
+}
+
+// Do runs fn once a slot is free and waits for its result
+// If ctx is done first, Do returns ctx.Err(). A job that already started keeps
+// its slot until fn returns, so fn should watch ctx to stop early
+func (p *WorkerPool) Do(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if p == nil || p.slots == nil {
+		return fn(ctx)
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	type result struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-p.slots }()
+		value, err := fn(ctx)
+		done <- result{value, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SetWorkerPoolSize replaces the pool used by Submit with one of the given size
+// It should be called before the server starts handling requests
+func (h *Handler) SetWorkerPoolSize(size int) {
+	h.pool = NewWorkerPool(size)
+}
+
+// Submit runs CPU-bound work on the handler's worker pool and waits for the result
+func (h *Handler) Submit(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return h.pool.Do(ctx, fn)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolCapsConcurrency(t *testing.T) {
+	handler := NewHandler()
+	handler.SetWorkerPoolSize(2)
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := handler.Submit(context.Background(), func(ctx context.Context) (interface{}, error) {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+				return nil, nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > 2 {
+		t.Errorf("expected at most 2 concurrent jobs, got %d", p)
+	}
+}
+
+func TestWorkerPoolContextCancellation(t *testing.T) {
+	pool := NewWorkerPool(1)
+
+	// Occupy the only slot
+	release := make(chan struct{})
+	go pool.Do(context.Background(), func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	time.Sleep(5 * time.Millisecond)
+	if _, err := pool.Do(ctx, func(ctx context.Context) (interface{}, error) {
+		t.Error("expected job not to run while the pool is full")
+		return nil, nil
+	}); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWorkerPoolSizeGOMAXPROCS(t *testing.T) {
+	if size := NewWorkerPool(WorkerPoolSizeGOMAXPROCS).Size(); size != runtime.GOMAXPROCS(0) {
+		t.Errorf("expected size GOMAXPROCS %d, got %d", runtime.GOMAXPROCS(0), size)
+	}
+}
+
+func TestWorkerPoolUnbounded(t *testing.T) {
+	if size := NewWorkerPool(0).Size(); size != 0 {
+		t.Errorf("expected a size of 0 to be unbounded, got %d", size)
+	}
+
+	handler := NewHandler()
+	if size := handler.pool.Size(); size != 0 {
+		t.Errorf("expected the default handler pool to be unbounded, got %d", size)
+	}
+
+	value, err := handler.Submit(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return 42, nil
+	})
+	if err != nil || value != 42 {
+		t.Errorf("expected 42, got %v (err %v)", value, err)
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"time"
+
+	"phantom-server/internal/middleware"
+)
+
+// UpstreamStatusHeader reports the upstream's status on gateway errors from Proxy:
+// its status code when it answered with a 5xx, or "timeout" or "error" when it did not
+const UpstreamStatusHeader = "X-Upstream-Status"
+
+// Proxy returns a handler forwarding requests to target
+// Upstream 502, 503 and 504 responses are passed on with X-Upstream-Status and a
+// Retry-After of retryAfter unless the upstream sent one. When the upstream cannot
+// be reached the client gets a 502, or a 504 if it timed out, carrying the same headers.
+// Set the returned proxy's Transport to bound how long the upstream may take
+func (h *Handler) Proxy(target *url.URL, retryAfter time.Duration) *httputil.ReverseProxy {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			resp.Header.Set(UpstreamStatusHeader, strconv.Itoa(resp.StatusCode))
+			if resp.Header.Get("Retry-After") == "" {
+				resp.Header.Set("Retry-After", middleware.RetryAfterValue(retryAfter))
+			}
+		}
+		return nil
+	}
+
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		statusCode, upstreamStatus := http.StatusBadGateway, "error"
+		if isTimeout(err) {
+			statusCode, upstreamStatus = http.StatusGatewayTimeout, "timeout"
+		}
+		log.Printf("Proxy to %s failed for %s %s: %v", target.Host, r.Method, r.URL.Path, err)
+
+		w.Header().Set(UpstreamStatusHeader, upstreamStatus)
+		middleware.SetRetryAfter(w, retryAfter)
+		h.writeJSONResponse(w, r, statusCode, Response{
+			Status:  "error",
+			Message: "The upstream server is unavailable",
+		})
+	}
+
+	return proxy
+}
+
+// isTimeout reports whether err means the upstream did not answer in time
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestHandler_ProxyUnreachableUpstream(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	target, _ := url.Parse(upstream.URL)
+	upstream.Close()
+
+	proxy := NewHandler().Proxy(target, 7*time.Second)
+	req := httptest.NewRequest("GET", "/api/items", nil)
+	rr := httptest.NewRecorder()
+	proxy.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadGateway {
+		t.Errorf("expected status %d, got %d", http.StatusBadGateway, rr.Code)
+	}
+	if got := rr.Header().Get(UpstreamStatusHeader); got != "error" {
+		t.Errorf("expected %s error, got %q", UpstreamStatusHeader, got)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "7" {
+		t.Errorf("expected Retry-After 7, got %q", got)
+	}
+}
+
+func TestHandler_ProxyUpstreamTimeout(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+	target, _ := url.Parse(upstream.URL)
+
+	proxy := NewHandler().Proxy(target, time.Second)
+	proxy.Transport = &http.Transport{ResponseHeaderTimeout: 50 * time.Millisecond}
+	req := httptest.NewRequest("GET", "/api/items", nil)
+	rr := httptest.NewRecorder()
+	proxy.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected status %d, got %d", http.StatusGatewayTimeout, rr.Code)
+	}
+	if got := rr.Header().Get(UpstreamStatusHeader); got != "timeout" {
+		t.Errorf("expected %s timeout, got %q", UpstreamStatusHeader, got)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After 1, got %q", got)
+	}
+}
+
+func TestHandler_ProxyUpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             int
+		upstreamRetryAfter string
+		expectedUpstream   string
+		expectedRetryAfter string
+	}{
+		{"bad gateway", http.StatusBadGateway, "", "502", "3"},
+		{"unavailable keeps upstream Retry-After", http.StatusServiceUnavailable, "30", "503", "30"},
+		{"gateway timeout", http.StatusGatewayTimeout, "", "504", "3"},
+		{"server error", http.StatusInternalServerError, "", "", ""},
+		{"success", http.StatusOK, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.upstreamRetryAfter != "" {
+					w.Header().Set("Retry-After", tt.upstreamRetryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer upstream.Close()
+			target, _ := url.Parse(upstream.URL)
+
+			proxy := NewHandler().Proxy(target, 3*time.Second)
+			rr := httptest.NewRecorder()
+			proxy.ServeHTTP(rr, httptest.NewRequest("GET", "/api/items", nil))
+
+			if rr.Code != tt.status {
+				t.Errorf("expected upstream status %d to be passed on, got %d", tt.status, rr.Code)
+			}
+			if got := rr.Header().Get(UpstreamStatusHeader); got != tt.expectedUpstream {
+				t.Errorf("expected %s %q, got %q", UpstreamStatusHeader, tt.expectedUpstream, got)
+			}
+			if got := rr.Header().Get("Retry-After"); got != tt.expectedRetryAfter {
+				t.Errorf("expected Retry-After %q, got %q", tt.expectedRetryAfter, got)
+			}
+		})
+	}
+}
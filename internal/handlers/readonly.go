@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+// readOnlyUpdate is the request body for toggling read-only mode
+type readOnlyUpdate struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// ReadOnlyMode handles the "/admin/readonly" endpoint
+// GET reports whether the server is read-only and POST switches it with an
+// {"enabled"} body. get and set read and change the current mode
+func (h *Handler) ReadOnlyMode(get func() bool, set func(bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.writeJSONResponse(w, r, http.StatusOK, Response{
+				Status: "success",
+				Data:   map[string]bool{"read_only": get()},
+			})
+		case http.MethodPost:
+			var update readOnlyUpdate
+			if !h.DecodeJSON(w, r, &update) {
+				return
+			}
+			set(*update.Enabled)
+
+			h.writeJSONResponse(w, r, http.StatusOK, Response{
+				Status:  "success",
+				Message: "Read-only mode updated",
+				Data:    map[string]bool{"read_only": get()},
+			})
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			h.writeJSONResponse(w, r, http.StatusMethodNotAllowed, Response{
+				Status:  "error",
+				Message: "Method not allowed",
+			})
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+// RouteInfo describes a registered route for introspection
+type RouteInfo struct {
+	Path        string   `json:"path"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// RouteList returns a handler for the "/debug/routes" endpoint
+// routes is called on every request so the listing reflects the current route table
+func (h *Handler) RouteList(routes func() []RouteInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.writeJSONResponse(w, r, http.StatusOK, Response{
+			Status: "success",
+			Data: map[string]interface{}{
+				"routes": routes(),
+			},
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// SSEContentType is the content type for server-sent event streams
+const SSEContentType = "text/event-stream"
+
+// DefaultSSEHeartbeat is how often StreamSSE writes a comment to keep idle streams open
+const DefaultSSEHeartbeat = 15 * time.Second
+
+// SetSSEHeartbeat sets the interval between heartbeats on idle SSE streams
+// Zero or less uses DefaultSSEHeartbeat
+func (h *Handler) SetSSEHeartbeat(d time.Duration) {
+	h.sseHeartbeat = d
+}
+
+// StreamSSE streams each value received from events as a server-sent event
+// Strings are sent as-is and other values as JSON, one data: frame per event,
+// flushed so clients see events as they arrive. A heartbeat comment is written
+// whenever the stream has been idle for the heartbeat interval, keeping proxies
+// from closing it. The write deadline is cleared since the stream is long-lived.
+// It returns nil once events is closed, or the context error if the client disconnects
+func (h *Handler) StreamSSE(w http.ResponseWriter, r *http.Request, events <-chan interface{}) error {
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return fmt.Errorf("failed to clear write deadline for SSE stream: %w", err)
+	}
+
+	w.Header().Set("Content-Type", SSEContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := controller.Flush(); err != nil {
+		return fmt.Errorf("failed to flush SSE headers: %w", err)
+	}
+
+	interval := h.sseHeartbeat
+	if interval <= 0 {
+		interval = DefaultSSEHeartbeat
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return r.Context().Err()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return fmt.Errorf("failed to write SSE heartbeat: %w", err)
+			}
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			frame, err := sseFrame(event)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(frame); err != nil {
+				return fmt.Errorf("failed to write SSE event: %w", err)
+			}
+			heartbeat.Reset(interval)
+		}
+		if err := controller.Flush(); err != nil {
+			return fmt.Errorf("failed to flush SSE event: %w", err)
+		}
+	}
+}
+
+// sseFrame encodes event as data: lines terminated by a blank line
+// Multi-line strings are split over several data: lines, which clients join with newlines
+func sseFrame(event interface{}) ([]byte, error) {
+	data, ok := event.(string)
+	if !ok {
+		encoded, err := gojson.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode SSE event: %w", err)
+		}
+		data = string(encoded)
+	}
+
+	var frame strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		frame.WriteString("data: ")
+		frame.WriteString(line)
+		frame.WriteString("\n")
+	}
+	frame.WriteString("\n")
+	return []byte(frame.String()), nil
+}
+
+// ClockEvents handles the "/events" endpoint
+// It streams the server time as an SSE event every second until the client disconnects
+func (h *Handler) ClockEvents(w http.ResponseWriter, r *http.Request) {
+	events := make(chan interface{})
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case now := <-ticker.C:
+				select {
+				case events <- map[string]string{"time": h.formatTime(now)}:
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+	}()
+
+	if err := h.StreamSSE(w, r, events); err != nil && r.Context().Err() == nil {
+		log.Printf("SSE stream to %s ended: %v", r.RemoteAddr, err)
+	}
+}
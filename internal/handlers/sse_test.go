@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_StreamSSE(t *testing.T) {
+	handler := NewHandler()
+	handler.SetSSEHeartbeat(20 * time.Millisecond)
+
+	events := make(chan interface{})
+	done := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done <- handler.StreamSSE(w, r, events)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != SSEContentType {
+		t.Errorf("handler returned wrong content type: got %v want %v", ct, SSEContentType)
+	}
+	if cc := resp.Header.Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("expected Cache-Control no-cache, got %q", cc)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	// readFrame returns the next frame, skipping heartbeat comments
+	readFrame := func() []string {
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read SSE stream: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				if len(lines) > 0 {
+					return lines
+				}
+				continue
+			}
+			if !strings.HasPrefix(line, ":") {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	// Wait for a heartbeat while the stream is idle
+	if line, err := reader.ReadString('\n'); err != nil || line != ": heartbeat\n" {
+		t.Fatalf("expected a heartbeat, got %q (%v)", line, err)
+	}
+
+	events <- map[string]int{"seq": 1}
+	if got := readFrame(); len(got) != 1 || got[0] != `data: {"seq":1}` {
+		t.Errorf("unexpected JSON event frame: %q", got)
+	}
+
+	events <- "first\nsecond"
+	if got := readFrame(); len(got) != 2 || got[0] != "data: first" || got[1] != "data: second" {
+		t.Errorf("unexpected multi-line event frame: %q", got)
+	}
+
+	// Disconnecting ends the stream with the context error
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not stop after the client disconnected")
+	}
+}
+
+func TestHandler_StreamSSEClosedChannel(t *testing.T) {
+	handler := NewHandler()
+
+	events := make(chan interface{}, 1)
+	events <- "done"
+	close(events)
+
+	req := httptest.NewRequest("GET", "/events", nil)
+	rr := httptest.NewRecorder()
+	if err := handler.StreamSSE(rr, req, events); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body := rr.Body.String(); body != "data: done\n\n" {
+		t.Errorf("unexpected body: %q", body)
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// DefaultRobotsTxt disallows all crawling and is served when no robots.txt is configured
+const DefaultRobotsTxt = "User-agent: *\nDisallow: /\n"
+
+//go:embed static/favicon.ico
+var favicon []byte
+
+// Favicon handles the "/favicon.ico" endpoint and serves the embedded icon
+func (h *Handler) Favicon(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "image/x-icon")
+	w.WriteHeader(http.StatusOK)
+	w.Write(favicon)
+}
+
+// RobotsTxt returns a handler for the "/robots.txt" endpoint serving the given body
+// An empty body falls back to DefaultRobotsTxt
+func (h *Handler) RobotsTxt(body string) http.HandlerFunc {
+	if body == "" {
+		body = DefaultRobotsTxt
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}
+}
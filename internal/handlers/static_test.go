@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_Favicon(t *testing.T) {
+	handler := NewHandler()
+
+	req := httptest.NewRequest("GET", "/favicon.ico", nil)
+	rr := httptest.NewRecorder()
+	handler.Favicon(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	if ct := rr.Header().Get("Content-Type"); ct != "image/x-icon" {
+		t.Errorf("handler returned wrong content type: got %v want %v", ct, "image/x-icon")
+	}
+
+	if !bytes.Equal(rr.Body.Bytes(), favicon) || len(favicon) == 0 {
+		t.Error("expected body to be the embedded favicon")
+	}
+}
+
+func TestHandler_RobotsTxt(t *testing.T) {
+	handler := NewHandler()
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"default disallows all", "", DefaultRobotsTxt},
+		{"configured body", "User-agent: *\nAllow: /\n", "User-agent: *\nAllow: /\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/robots.txt", nil)
+			rr := httptest.NewRecorder()
+			handler.RobotsTxt(tt.body)(rr, req)
+
+			if status := rr.Code; status != http.StatusOK {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+			}
+
+			expectedType := "text/plain; charset=utf-8"
+			if ct := rr.Header().Get("Content-Type"); ct != expectedType {
+				t.Errorf("handler returned wrong content type: got %v want %v", ct, expectedType)
+			}
+
+			if rr.Body.String() != tt.expected {
+				t.Errorf("expected body %q, got %q", tt.expected, rr.Body.String())
+			}
+		})
+	}
+}
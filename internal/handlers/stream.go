@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// NDJSONContentType is the content type for newline-delimited JSON responses
+const NDJSONContentType = "application/x-ndjson"
+
+// WriteNDJSON streams each item received from items as one JSON object per line
+// The response is flushed after every line so clients see items as they arrive
+// It returns nil once items is closed, or the context error if the client disconnects
+func (h *Handler) WriteNDJSON(w http.ResponseWriter, r *http.Request, items <-chan interface{}) error {
+	w.Header().Set("Content-Type", NDJSONContentType)
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	encoder := gojson.NewEncoder(w)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return r.Context().Err()
+		case item, ok := <-items:
+			if !ok {
+				return nil
+			}
+			// Encode terminates every value with a newline
+			if err := encoder.Encode(item); err != nil {
+				return fmt.Errorf("failed to encode NDJSON line: %w", err)
+			}
+			if err := controller.Flush(); err != nil {
+				return fmt.Errorf("failed to flush NDJSON line: %w", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_WriteNDJSON(t *testing.T) {
+	handler := NewHandler()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items := make(chan interface{}, 3)
+		for i := 1; i <= 3; i++ {
+			items <- map[string]int{"seq": i}
+		}
+		close(items)
+
+		if err := handler.WriteNDJSON(w, r, items); err != nil {
+			t.Errorf("unexpected error streaming NDJSON: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != NDJSONContentType {
+		t.Errorf("handler returned wrong content type: got %v want %v", ct, NDJSONContentType)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var lines []map[string]int
+	for scanner.Scan() {
+		var line map[string]int
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("could not parse NDJSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if line["seq"] != i+1 {
+			t.Errorf("line %d: expected seq %d, got %d", i, i+1, line["seq"])
+		}
+	}
+}
+
+func TestHandler_WriteNDJSONClientDisconnect(t *testing.T) {
+	handler := NewHandler()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	// The channel is never closed, so only the cancelled context can end the stream
+	items := make(chan interface{})
+	err := handler.WriteNDJSON(rr, req, items)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// FieldError describes a single field that failed request validation
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// DecodeJSON decodes the request body into dst and validates its required fields
+// On failure it writes a structured 400 response and returns false, so callers
+// can simply return when it reports an invalid body. Time fields are parsed with
+// the layout set by SetTimeLayout
+func (h *Handler) DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if r.Body == nil {
+		h.writeValidationError(w, r, "Request body is required", nil)
+		return false
+	}
+
+	if err := gojson.NewDecoder(r.Body).Decode(dst); err != nil {
+		var typeErr *gojson.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			h.writeValidationError(w, r, "Request body is invalid", []FieldError{{
+				Field:  jsonNameForField(dst, typeErr.Field),
+				Reason: "expected " + typeErr.Type.String(),
+			}})
+			return false
+		}
+		if errors.Is(err, errInvalidTime) {
+			h.writeValidationError(w, r, "Request body contains an invalid time", nil)
+			return false
+		}
+		h.writeValidationError(w, r, "Request body must be valid JSON", nil)
+		return false
+	}
+
+	if fieldErrors := ValidateStruct(dst); len(fieldErrors) > 0 {
+		h.writeValidationError(w, r, "Request body is invalid", fieldErrors)
+		return false
+	}
+
+	return true
+}
+
+// ValidateStruct checks fields tagged with `validate:"required"` and reports
+// every one that holds its zero value. Field names follow the json tag when present
+func ValidateStruct(v interface{}) []FieldError {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fieldErrors []FieldError
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			if strings.TrimSpace(rule) == "required" && value.Field(i).IsZero() {
+				fieldErrors = append(fieldErrors, FieldError{
+					Field:  jsonFieldName(field),
+					Reason: "required",
+				})
+			}
+		}
+	}
+
+	return fieldErrors
+}
+
+// jsonFieldName returns the name a struct field is known by in JSON
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// jsonNameForField maps a Go struct field name reported by the decoder to its json name
+func jsonNameForField(v interface{}, name string) string {
+	valueType := reflect.TypeOf(v)
+	for valueType != nil && valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	if valueType == nil || valueType.Kind() != reflect.Struct {
+		return name
+	}
+
+	if field, ok := valueType.FieldByName(name); ok {
+		return jsonFieldName(field)
+	}
+	return name
+}
+
+// writeValidationError writes a 400 response listing the invalid fields
+func (h *Handler) writeValidationError(w http.ResponseWriter, r *http.Request, message string, fieldErrors []FieldError) {
+	response := Response{
+		Status:  "error",
+		Message: message,
+	}
+	if len(fieldErrors) > 0 {
+		response.Data = map[string]interface{}{
+			"errors": fieldErrors,
+		}
+	}
+
+	h.writeJSONResponse(w, r, http.StatusBadRequest, response)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type createUserRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required"`
+	Age   int    `json:"age"`
+}
+
+func decodeValidationErrors(t *testing.T, rr *httptest.ResponseRecorder) []FieldError {
+	t.Helper()
+
+	var response struct {
+		Status string `json:"status"`
+		Data   struct {
+			Errors []FieldError `json:"errors"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not parse response JSON: %v", err)
+	}
+	if response.Status != "error" {
+		t.Errorf("expected status 'error', got %v", response.Status)
+	}
+
+	return response.Data.Errors
+}
+
+func TestHandler_DecodeJSON(t *testing.T) {
+	handler := NewHandler()
+
+	t.Run("valid body", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"Ada","email":"ada@example.com","age":36}`))
+		rr := httptest.NewRecorder()
+
+		var body createUserRequest
+		if !handler.DecodeJSON(rr, req, &body) {
+			t.Fatalf("expected body to be valid, got response: %s", rr.Body.String())
+		}
+
+		if body.Name != "Ada" || body.Email != "ada@example.com" || body.Age != 36 {
+			t.Errorf("unexpected decoded body: %+v", body)
+		}
+	})
+
+	t.Run("missing required field", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"Ada"}`))
+		rr := httptest.NewRecorder()
+
+		var body createUserRequest
+		if handler.DecodeJSON(rr, req, &body) {
+			t.Fatal("expected body to be rejected")
+		}
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+		}
+
+		fieldErrors := decodeValidationErrors(t, rr)
+		if len(fieldErrors) != 1 || fieldErrors[0].Field != "email" || fieldErrors[0].Reason != "required" {
+			t.Errorf("expected a single required error for email, got %+v", fieldErrors)
+		}
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"Ada","email":"ada@example.com","age":"old"}`))
+		rr := httptest.NewRecorder()
+
+		var body createUserRequest
+		if handler.DecodeJSON(rr, req, &body) {
+			t.Fatal("expected body to be rejected")
+		}
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+		}
+
+		fieldErrors := decodeValidationErrors(t, rr)
+		if len(fieldErrors) != 1 || fieldErrors[0].Field != "age" {
+			t.Errorf("expected a single error for age, got %+v", fieldErrors)
+		}
+	})
+
+	t.Run("malformed JSON", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":`))
+		rr := httptest.NewRecorder()
+
+		var body createUserRequest
+		if handler.DecodeJSON(rr, req, &body) {
+			t.Fatal("expected body to be rejected")
+		}
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+		}
+	})
+}
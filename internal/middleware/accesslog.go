@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// AccessLog creates a middleware that logs one line per request rendered from format
+// Supported placeholders are %time, %seq, %method, %path, %query, %proto, %status,
+// %bytes, %duration, %ip and %request_id. An empty format keeps the Logger format
+func AccessLog(enabled bool, format string) Middleware {
+	if format == "" || !enabled {
+		return Logger(enabled)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			seq := requestSeq.Add(1)
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK, ctx: r.Context()}
+			next.ServeHTTP(rec, r)
+
+			requestID := loggedRequestID(r)
+			if requestID == "" {
+				requestID = "-"
+			}
+
+			log.Print(strings.NewReplacer(
+				"%time", logTimestamp(start),
+				"%seq", strconv.FormatUint(seq, 10),
+				"%method", r.Method,
+				"%path", r.URL.Path,
+				"%query", r.URL.RawQuery,
+				"%proto", r.Proto,
+				"%status", strconv.Itoa(rec.loggedStatus()),
+				"%bytes", strconv.FormatInt(rec.bytes, 10),
+				"%duration", time.Since(start).String(),
+				"%ip", clientIP(r),
+				"%request_id", requestID,
+			).Replace(format))
+		})
+	}
+}
+
+// StatusClientClosedRequest is logged instead of the handler's status when the client
+// went away before the response was written, following nginx's 499 convention
+const StatusClientClosedRequest = 499
+
+// statusRecorder captures the status code and body size written by a handler, and
+// whether writing failed because the client closed the connection
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+	broken bool
+	ctx    context.Context // Request context, consulted when a write fails
+}
+
+// loggedStatus returns the status to log, 499 when the connection broke mid-response
+func (sr *statusRecorder) loggedStatus() int {
+	if sr.broken {
+		return StatusClientClosedRequest
+	}
+	return sr.status
+}
+
+func (sr *statusRecorder) WriteHeader(statusCode int) {
+	sr.status = statusCode
+	sr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += int64(n)
+	if err != nil && clientGone(sr.ctx, err) {
+		sr.broken = true
+	}
+	return n, err
+}
+
+// clientGone reports whether a failed write means the client went away, rather than
+// a server-side error such as a body on a 304 or a write after a handler timeout
+func clientGone(ctx context.Context, err error) bool {
+	if ctx != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return true
+	}
+	return errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, io.ErrClosedPipe)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer for Flush and Hijack
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
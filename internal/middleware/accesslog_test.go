@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestAccessLogCustomFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	})
+
+	handler := AccessLog(true, "%method %path %status %bytes %ip %request_id")(testHandler)
+	req := httptest.NewRequest("POST", "/api/users?x=1", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	expected := "POST /api/users 201 7 192.0.2.1 -\n"
+	if buf.String() != expected {
+		t.Errorf("Expected log line %q, got %q", expected, buf.String())
+	}
+}
+
+func TestAccessLogDefaultFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	handler := AccessLog(true, "")(testHandler)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+
+	if !strings.Contains(buf.String(), "GET /test") {
+		t.Errorf("Expected default Logger format, got: %s", buf.String())
+	}
+}
+
+func TestAccessLogDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	handler := AccessLog(false, "%method %path")(testHandler)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log output but got: %s", buf.String())
+	}
+}
+
+// brokenPipeWriter is a ResponseWriter whose writes fail as if the client disconnected
+type brokenPipeWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (bw brokenPipeWriter) Write(b []byte) (int, error) {
+	return 0, syscall.EPIPE
+}
+
+// failingWriter is a ResponseWriter whose writes fail with err
+type failingWriter struct {
+	*httptest.ResponseRecorder
+	err error
+}
+
+func (fw failingWriter) Write(b []byte) (int, error) {
+	return 0, fw.err
+}
+
+func TestAccessLogClientClosedRequest(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	t.Run("custom format", func(t *testing.T) {
+		buf.Reset()
+		AccessLog(true, "%method %path %status")(testHandler).ServeHTTP(
+			brokenPipeWriter{httptest.NewRecorder()}, httptest.NewRequest("GET", "/gone", nil))
+
+		if expected := "GET /gone 499\n"; buf.String() != expected {
+			t.Errorf("Expected log line %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("default format", func(t *testing.T) {
+		buf.Reset()
+		AccessLog(true, "")(testHandler).ServeHTTP(
+			brokenPipeWriter{httptest.NewRecorder()}, httptest.NewRequest("GET", "/gone", nil))
+
+		if !strings.Contains(buf.String(), "GET /gone 499 client closed request") {
+			t.Errorf("Expected a 499 client closed request line, got: %s", buf.String())
+		}
+	})
+
+	t.Run("server-side write errors", func(t *testing.T) {
+		tests := []struct {
+			status int
+			err    error
+		}{
+			{http.StatusNotModified, http.ErrBodyNotAllowed},
+			{http.StatusServiceUnavailable, http.ErrHandlerTimeout},
+		}
+		for _, tt := range tests {
+			buf.Reset()
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte("OK"))
+			})
+			AccessLog(true, "%method %path %status")(handler).ServeHTTP(
+				failingWriter{httptest.NewRecorder(), tt.err}, httptest.NewRequest("GET", "/ok", nil))
+
+			if expected := fmt.Sprintf("GET /ok %d\n", tt.status); buf.String() != expected {
+				t.Errorf("Expected log line %q for %v, got %q", expected, tt.err, buf.String())
+			}
+		}
+	})
+
+	t.Run("cancelled by the client", func(t *testing.T) {
+		buf.Reset()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		AccessLog(true, "%method %path %status")(testHandler).ServeHTTP(
+			failingWriter{httptest.NewRecorder(), errors.New("write failed")},
+			httptest.NewRequest("GET", "/gone", nil).WithContext(ctx))
+
+		if expected := "GET /gone 499\n"; buf.String() != expected {
+			t.Errorf("Expected log line %q, got %q", expected, buf.String())
+		}
+	})
+
+	t.Run("successful write", func(t *testing.T) {
+		buf.Reset()
+		AccessLog(true, "")(testHandler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+
+		if strings.Contains(buf.String(), "499") {
+			t.Errorf("Expected no 499 line for a completed response, got: %s", buf.String())
+		}
+	})
+}
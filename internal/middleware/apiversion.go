@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// APIVersionHeader is the request header selecting an API version, e.g. "2" or "v2"
+const APIVersionHeader = "X-API-Version"
+
+// apiVersionKey is the context key under which the requested API version is stored
+type apiVersionKey struct{}
+
+// vendorVersion matches a version in a vendor media type such as application/vnd.phantom.v2+json
+var vendorVersion = regexp.MustCompile(`\.v(\d+)(?:\+|$)`)
+
+// APIVersion creates a middleware that resolves the API version requested by the client
+// and stores it in the request context. X-API-Version takes precedence over a version in
+// the Accept header, given either as a "version" parameter or a vendor media type. No
+// version is stored when the client does not ask for one, so routes use their latest
+func APIVersion() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if version := requestedAPIVersion(r); version != "" {
+				r = r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// APIVersionFromContext returns the version stored by APIVersion without a leading "v",
+// or "" if the client did not request one
+func APIVersionFromContext(ctx context.Context) string {
+	version, _ := ctx.Value(apiVersionKey{}).(string)
+	return version
+}
+
+// requestedAPIVersion reads the requested version from the request headers
+func requestedAPIVersion(r *http.Request) string {
+	if version := NormalizeAPIVersion(r.Header.Get(APIVersionHeader)); version != "" {
+		return version
+	}
+
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			if version := NormalizeAPIVersion(params["version"]); version != "" {
+				return version
+			}
+			if match := vendorVersion.FindStringSubmatch(mediaType); match != nil {
+				return match[1]
+			}
+		}
+	}
+	return ""
+}
+
+// NormalizeAPIVersion trims whitespace and a leading "v" so "v2" and "2" name the same version
+func NormalizeAPIVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') {
+		version = version[1:]
+	}
+	return version
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{"no version", nil, ""},
+		{"header", map[string]string{"X-API-Version": "2"}, "2"},
+		{"header with prefix", map[string]string{"X-API-Version": "v3"}, "3"},
+		{"accept version parameter", map[string]string{"Accept": "application/json; version=2"}, "2"},
+		{"accept vendor media type", map[string]string{"Accept": "text/html, application/vnd.phantom.v1+json"}, "1"},
+		{"header takes precedence", map[string]string{"X-API-Version": "1", "Accept": "application/vnd.phantom.v2+json"}, "1"},
+		{"plain accept", map[string]string{"Accept": "application/json"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := APIVersion()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = APIVersionFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.expected {
+				t.Errorf("Expected version %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
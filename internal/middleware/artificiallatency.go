@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// ArtificialLatency creates a middleware that delays every request by d before handling it
+// It is meant for development, to reproduce slow networks and exercise client timeouts.
+// A request whose client goes away during the delay is dropped without reaching the
+// handler, and a delay of zero or less disables the middleware
+func ArtificialLatency(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+
+			select {
+			case <-timer.C:
+				next.ServeHTTP(w, r)
+			case <-r.Context().Done():
+			}
+		})
+	}
+}
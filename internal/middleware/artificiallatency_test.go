@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestArtificialLatency(t *testing.T) {
+	called := false
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := ArtificialLatency(50 * time.Millisecond)(testHandler)
+
+	start := time.Now()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected the request to be delayed by 50ms, took %v", elapsed)
+	}
+	if !called {
+		t.Error("Expected the handler to run after the delay")
+	}
+}
+
+func TestArtificialLatencyCanceled(t *testing.T) {
+	called := false
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	handler := ArtificialLatency(time.Minute)(testHandler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	start := time.Now()
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected a canceled request to return early, took %v", elapsed)
+	}
+	if called {
+		t.Error("Expected the handler not to run for a canceled request")
+	}
+}
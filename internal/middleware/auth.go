@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// BearerToken creates a middleware that requires an "Authorization: Bearer <token>" header
+// matching token. Requests without a valid token receive a 401 Unauthorized
+// An empty token rejects every request so a missing secret never opens a route
+func BearerToken(token string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || !found || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeJSONError(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
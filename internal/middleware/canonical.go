@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// CanonicalHost creates a middleware that redirects requests for any other host to
+// host with a 301 Moved Permanently, keeping the path and query. The port of the
+// request is ignored unless host includes one. Paths in exempt, such as health checks
+// that load balancers send by IP or internal hostname, are served for any host.
+// An empty host disables the redirect
+func CanonicalHost(host string, exempt ...string) Middleware {
+	exempted := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		exempted[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		if host == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempted[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			requestHost := r.Host
+			if !strings.Contains(host, ":") {
+				if hostname, _, err := net.SplitHostPort(requestHost); err == nil {
+					requestHost = hostname
+				}
+			}
+
+			if strings.EqualFold(requestHost, host) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			http.Redirect(w, r, scheme+"://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+		})
+	}
+}
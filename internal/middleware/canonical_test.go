@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonicalHost(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	tests := []struct {
+		name             string
+		canonical        string
+		host             string
+		tls              bool
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{"redirects to www", "www.example.com", "example.com", false, http.StatusMovedPermanently, "http://www.example.com/path?q=1"},
+		{"redirects away from www", "example.com", "www.example.com", true, http.StatusMovedPermanently, "https://example.com/path?q=1"},
+		{"passes canonical host", "www.example.com", "www.example.com", false, http.StatusOK, ""},
+		{"ignores case and port", "www.example.com", "WWW.Example.com:8080", false, http.StatusOK, ""},
+		{"canonical port must match", "example.com:8443", "example.com:8080", true, http.StatusMovedPermanently, "https://example.com:8443/path?q=1"},
+		{"disabled", "", "example.com", false, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/path?q=1", nil)
+			req.Host = tt.host
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			w := httptest.NewRecorder()
+
+			CanonicalHost(tt.canonical)(testHandler).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if location := w.Header().Get("Location"); location != tt.expectedLocation {
+				t.Errorf("Expected Location %q, got %q", tt.expectedLocation, location)
+			}
+		})
+	}
+}
+
+func TestCanonicalHostExemptPaths(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	handler := CanonicalHost("www.example.com", "/health")(testHandler)
+
+	// A load balancer probing by IP reaches the health check directly
+	req := httptest.NewRequest("GET", "/health", nil)
+	req.Host = "10.0.0.5:8080"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected exempt path to be served, got %d", w.Code)
+	}
+
+	// Other paths on the same host are still redirected
+	req = httptest.NewRequest("GET", "/healthz", nil)
+	req.Host = "10.0.0.5:8080"
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusMovedPermanently {
+		t.Errorf("Expected other paths to be redirected, got %d", w.Code)
+	}
+}
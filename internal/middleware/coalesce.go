@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// coalescedResponse is a response recorded by the leading request and replayed to waiters
+type coalescedResponse struct {
+	done   chan struct{}
+	status int
+	header http.Header
+	body   bytes.Buffer
+
+	// completed is set when the handler returned normally for a client that was still
+	// connected. A response cut short by a panic or disconnect is never shared
+	completed bool
+}
+
+// Coalesce creates a middleware that collapses identical in-flight GET requests
+// The first request for a method, path and query runs the handler while the
+// others wait and receive a copy of its response. Other request headers are not
+// part of the key, so only use it for idempotent responses that are the same
+// for every client. If the leading request panics or its client goes away,
+// each waiter runs the handler itself instead of sharing the partial response
+func Coalesce() Middleware {
+	var mu sync.Mutex
+	inFlight := make(map[string]*coalescedResponse)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery
+
+			mu.Lock()
+			if call, exists := inFlight[key]; exists {
+				mu.Unlock()
+				select {
+				case <-call.done:
+					if !call.completed {
+						next.ServeHTTP(w, r)
+						return
+					}
+					call.writeTo(w)
+				case <-r.Context().Done():
+				}
+				return
+			}
+			call := &coalescedResponse{done: make(chan struct{}), status: http.StatusOK, header: make(http.Header)}
+			inFlight[key] = call
+			mu.Unlock()
+
+			// Release waiters even if the handler panics
+			defer func() {
+				mu.Lock()
+				delete(inFlight, key)
+				mu.Unlock()
+				close(call.done)
+			}()
+
+			next.ServeHTTP(&coalescingWriter{call: call}, r)
+			call.completed = r.Context().Err() == nil
+			call.writeTo(w)
+		})
+	}
+}
+
+// writeTo replays the recorded response
+func (c *coalescedResponse) writeTo(w http.ResponseWriter) {
+	for name, values := range c.header {
+		w.Header()[name] = append([]string(nil), values...)
+	}
+	w.WriteHeader(c.status)
+	w.Write(c.body.Bytes())
+}
+
+// coalescingWriter records a response so it can be shared with waiting requests
+type coalescingWriter struct {
+	call        *coalescedResponse
+	wroteHeader bool
+}
+
+func (cw *coalescingWriter) Header() http.Header {
+	return cw.call.header
+}
+
+func (cw *coalescingWriter) WriteHeader(statusCode int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.call.status = statusCode
+}
+
+func (cw *coalescingWriter) Write(b []byte) (int, error) {
+	cw.WriteHeader(http.StatusOK)
+	return cw.call.body.Write(b)
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesceConcurrentRequests(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Header().Set("X-Result", "shared")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("expensive"))
+	})
+
+	var arrived atomic.Int32
+	countArrivals := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			arrived.Add(1)
+			next.ServeHTTP(w, r)
+		})
+	}
+	handler := Chain(countArrivals, Coalesce())(testHandler)
+
+	const clients = 10
+	recorders := make([]*httptest.ResponseRecorder, clients)
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(rec *httptest.ResponseRecorder) {
+			defer wg.Done()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/report?id=1", nil))
+		}(recorders[i])
+	}
+
+	// Let every request reach the middleware before the leader finishes
+	for arrived.Load() < clients || calls.Load() == 0 {
+		runtime.Gosched()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected handler to run once, ran %d times", n)
+	}
+	for i, rec := range recorders {
+		if rec.Code != http.StatusAccepted || rec.Body.String() != "expensive" || rec.Header().Get("X-Result") != "shared" {
+			t.Errorf("Client %d got unexpected response: %d %q %v", i, rec.Code, rec.Body.String(), rec.Header())
+		}
+	}
+}
+
+func TestCoalesceDistinctKeys(t *testing.T) {
+	var calls atomic.Int32
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(r.URL.RawQuery))
+	})
+
+	handler := Coalesce()(testHandler)
+	for _, target := range []string{"/report?id=1", "/report?id=2"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+	}
+
+	// Sequential requests never overlap, and non-GET requests are never coalesced
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/report?id=1", nil))
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("Expected handler to run 3 times, ran %d times", n)
+	}
+}
+
+func TestCoalesceLeaderPanics(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-release
+			w.Write([]byte("partial"))
+			panic("handler failed")
+		}
+		w.Write([]byte("fresh"))
+	})
+
+	var arrived atomic.Int32
+	countArrivals := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			arrived.Add(1)
+			next.ServeHTTP(w, r)
+		})
+	}
+	handler := Chain(countArrivals, Coalesce())(testHandler)
+
+	leaderDone := make(chan interface{}, 1)
+	go func() {
+		defer func() { leaderDone <- recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/report", nil))
+	}()
+	for calls.Load() == 0 {
+		runtime.Gosched()
+	}
+
+	waiter := httptest.NewRecorder()
+	waiterDone := make(chan struct{})
+	go func() {
+		defer close(waiterDone)
+		handler.ServeHTTP(waiter, httptest.NewRequest("GET", "/report", nil))
+	}()
+	for arrived.Load() < 2 {
+		runtime.Gosched()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if p := <-leaderDone; p == nil {
+		t.Error("Expected the leader's panic to propagate")
+	}
+	<-waiterDone
+
+	// The waiter ran the handler itself rather than receiving a fake 200
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected handler to run twice, ran %d times", n)
+	}
+	if waiter.Code != http.StatusOK || waiter.Body.String() != "fresh" {
+		t.Errorf("Expected the waiter to get its own response, got %d %q", waiter.Code, waiter.Body.String())
+	}
+}
+
+func TestCoalesceLeaderDisconnects(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-release
+			// Abandon the response once the client is gone
+			return
+		}
+		w.Write([]byte("fresh"))
+	})
+
+	var arrived atomic.Int32
+	countArrivals := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			arrived.Add(1)
+			next.ServeHTTP(w, r)
+		})
+	}
+	handler := Chain(countArrivals, Coalesce())(testHandler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/report", nil).WithContext(ctx))
+	}()
+	for calls.Load() == 0 {
+		runtime.Gosched()
+	}
+
+	waiter := httptest.NewRecorder()
+	waiterDone := make(chan struct{})
+	go func() {
+		defer close(waiterDone)
+		handler.ServeHTTP(waiter, httptest.NewRequest("GET", "/report", nil))
+	}()
+	for arrived.Load() < 2 {
+		runtime.Gosched()
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	close(release)
+	<-leaderDone
+	<-waiterDone
+
+	if waiter.Body.String() != "fresh" {
+		t.Errorf("Expected the waiter not to share an aborted response, got %d %q", waiter.Code, waiter.Body.String())
+	}
+}
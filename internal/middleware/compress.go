@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompressionLevelOrDefault returns level when it is a valid gzip level (1-9)
+// and gzip.DefaultCompression otherwise
+func CompressionLevelOrDefault(level int) int {
+	if level < gzip.BestSpeed || level > gzip.BestCompression {
+		return gzip.DefaultCompression
+	}
+	return level
+}
+
+// Compress creates a middleware that gzip-compresses responses for clients that accept it
+// The level trades speed (1) for ratio (9); invalid levels fall back to the gzip default
+func Compress(level int) Middleware {
+	level = CompressionLevelOrDefault(level)
+	pool := &sync.Pool{
+		New: func() interface{} {
+			gz, _ := gzip.NewWriterLevel(nil, level)
+			return gz
+		},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !AcceptsGzip(r) || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w, pool: pool}
+			defer gw.Close()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// AcceptsGzip reports whether the client lists gzip in Accept-Encoding with a
+// non-zero quality. A q value that is not a number does not count as accepting
+func AcceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		if quality(params) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// quality returns the q parameter from the ;-separated params of an
+// Accept-Encoding entry, 1 when there is none and 0 when it is not a number
+func quality(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if !strings.EqualFold(strings.TrimSpace(key), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
+
+// gzipResponseWriter compresses the response body
+// The status is held back until the first non-empty Write, so compression is only
+// chosen when there is a body to compress. A Flush or the end of the response
+// before any body is written sends the headers as they are, uncompressed
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	pool        *sync.Pool
+	gz          *gzip.Writer
+	status      int
+	wroteHeader bool
+	compress    bool
+}
+
+// WriteHeader records the status code; it is sent along with the first body bytes
+func (gw *gzipResponseWriter) WriteHeader(statusCode int) {
+	if gw.wroteHeader || gw.status != 0 {
+		return
+	}
+	gw.status = statusCode
+}
+
+// sendHeader decides whether the body is compressed and sends the headers
+// Only a response that has a body, allows one and was not already encoded by the
+// handler is compressed
+func (gw *gzipResponseWriter) sendHeader(hasBody bool) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+	if gw.status == 0 {
+		gw.status = http.StatusOK
+	}
+
+	header := gw.Header()
+	if hasBody && gw.status != http.StatusNoContent && gw.status != http.StatusNotModified && header.Get("Content-Encoding") == "" {
+		gw.compress = true
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+	}
+	gw.ResponseWriter.WriteHeader(gw.status)
+}
+
+// Write compresses b into the underlying response
+func (gw *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !gw.wroteHeader {
+		if len(b) == 0 {
+			return 0, nil
+		}
+		if gw.Header().Get("Content-Type") == "" {
+			gw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		gw.sendHeader(true)
+	}
+	if !gw.compress {
+		return gw.ResponseWriter.Write(b)
+	}
+	if gw.gz == nil {
+		gw.gz = gw.pool.Get().(*gzip.Writer)
+		gw.gz.Reset(gw.ResponseWriter)
+	}
+	return gw.gz.Write(b)
+}
+
+// Flush flushes buffered compressed data to the client
+// Flushing before any body is written commits the headers without compression
+func (gw *gzipResponseWriter) Flush() {
+	gw.sendHeader(false)
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	http.NewResponseController(gw.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (gw *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
+
+// Close sends a held back status, finishes the gzip stream and returns the writer
+// to the pool. A handler that wrote nothing gets no Content-Encoding
+func (gw *gzipResponseWriter) Close() {
+	if !gw.wroteHeader && gw.status != 0 {
+		gw.sendHeader(false)
+	}
+	if gw.gz == nil {
+		return
+	}
+	gw.gz.Close()
+	gw.pool.Put(gw.gz)
+	gw.gz = nil
+}
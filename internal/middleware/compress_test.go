@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat("phantom server response ", 200)
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(body))
+	})
+
+	for _, level := range []int{1, 9, 0, 42, -5} {
+		handler := Compress(level)(testHandler)
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if ce := w.Header().Get("Content-Encoding"); ce != "gzip" {
+			t.Fatalf("Level %d: expected Content-Encoding gzip, got %q", level, ce)
+		}
+
+		reader, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("Level %d: expected valid gzip output, got %v", level, err)
+		}
+		decoded, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Level %d: failed to decompress: %v", level, err)
+		}
+		if string(decoded) != body {
+			t.Errorf("Level %d: decompressed body does not match", level)
+		}
+	}
+}
+
+func TestCompressionLevelOrDefault(t *testing.T) {
+	tests := []struct {
+		level    int
+		expected int
+	}{
+		{1, 1},
+		{5, 5},
+		{9, 9},
+		{0, gzip.DefaultCompression},
+		{10, gzip.DefaultCompression},
+		{-2, gzip.DefaultCompression},
+	}
+
+	for _, tt := range tests {
+		if got := CompressionLevelOrDefault(tt.level); got != tt.expected {
+			t.Errorf("CompressionLevelOrDefault(%d) = %d, expected %d", tt.level, got, tt.expected)
+		}
+	}
+}
+
+func TestCompressSkipsClientsWithoutGzip(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain"))
+	})
+	handler := Compress(6)(testHandler)
+
+	for _, accept := range []string{"", "deflate", "gzip;q=0", "gzip; q=0.0", "gzip;q=0.000", "gzip;q=high"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		if accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if ce := w.Header().Get("Content-Encoding"); ce != "" {
+			t.Errorf("Accept-Encoding %q: expected no Content-Encoding, got %q", accept, ce)
+		}
+		if !bytes.Equal(w.Body.Bytes(), []byte("plain")) {
+			t.Errorf("Accept-Encoding %q: expected plain body, got %q", accept, w.Body.String())
+		}
+	}
+}
+
+func TestCompressAcceptsWeightedGzip(t *testing.T) {
+	for _, accept := range []string{"gzip", "gzip;q=0.5", "deflate, gzip; Q=1.0"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Accept-Encoding", accept)
+		if !AcceptsGzip(req) {
+			t.Errorf("Accept-Encoding %q: expected gzip to be accepted", accept)
+		}
+	}
+}
+
+func TestCompressWithoutBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+	}{
+		{"no write", func(w http.ResponseWriter, r *http.Request) {}, http.StatusOK},
+		{"status only", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusAccepted) }, http.StatusAccepted},
+		{"empty write", func(w http.ResponseWriter, r *http.Request) { w.Write(nil) }, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			Compress(6)(tt.handler).ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if ce := w.Header().Get("Content-Encoding"); ce != "" {
+				t.Errorf("Expected no Content-Encoding without a body, got %q", ce)
+			}
+			if w.Body.Len() != 0 {
+				t.Errorf("Expected an empty body, got %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompressFlushBeforeWrite(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		http.NewResponseController(w).Flush()
+		w.Write([]byte("data: hello\n\n"))
+	})
+	req := httptest.NewRequest("GET", "/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	Compress(6)(testHandler).ServeHTTP(w, req)
+
+	if !w.Flushed {
+		t.Error("Expected the flush to reach the client")
+	}
+	if ce := w.Header().Get("Content-Encoding"); ce != "" {
+		t.Errorf("Expected headers flushed before the body to stay uncompressed, got %q", ce)
+	}
+	if w.Body.String() != "data: hello\n\n" {
+		t.Errorf("Expected the plain body, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ConnSnapshot is a point-in-time view of client connection counts
+type ConnSnapshot struct {
+	Open     int    `json:"open"`     // Connections currently new, active or idle
+	New      int    `json:"new"`      // Connections accepted but not yet sending a request
+	Active   int    `json:"active"`   // Connections reading or serving a request
+	Idle     int    `json:"idle"`     // Keep-alive connections waiting for the next request
+	Accepted uint64 `json:"accepted"` // Connections accepted since startup
+	Hijacked uint64 `json:"hijacked"` // Connections taken over by a handler, such as WebSockets
+	Closed   uint64 `json:"closed"`   // Connections closed since startup
+}
+
+// ConnStats maintains connection gauges from http.Server state changes
+// Set its ConnState method as the server's ConnState callback
+type ConnStats struct {
+	mu       sync.Mutex
+	states   map[net.Conn]http.ConnState
+	counts   map[http.ConnState]int
+	accepted uint64
+	hijacked uint64
+	closed   uint64
+}
+
+// NewConnStats creates an empty connection tracker
+func NewConnStats() *ConnStats {
+	return &ConnStats{
+		states: make(map[net.Conn]http.ConnState),
+		counts: make(map[http.ConnState]int),
+	}
+}
+
+// ConnState records a connection moving to state, for use as http.Server.ConnState
+func (s *ConnStats) ConnState(conn net.Conn, state http.ConnState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, exists := s.states[conn]; exists {
+		s.counts[previous]--
+	}
+
+	switch state {
+	case http.StateNew:
+		s.accepted++
+	case http.StateHijacked:
+		s.hijacked++
+		delete(s.states, conn)
+		return
+	case http.StateClosed:
+		s.closed++
+		delete(s.states, conn)
+		return
+	}
+
+	s.states[conn] = state
+	s.counts[state]++
+}
+
+// Snapshot returns the current connection counts
+func (s *ConnStats) Snapshot() ConnSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return ConnSnapshot{
+		Open:     len(s.states),
+		New:      s.counts[http.StateNew],
+		Active:   s.counts[http.StateActive],
+		Idle:     s.counts[http.StateIdle],
+		Accepted: s.accepted,
+		Hijacked: s.hijacked,
+		Closed:   s.closed,
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// waitForConns polls stats until check passes, failing the test after a timeout
+func waitForConns(t *testing.T, stats *ConnStats, check func(ConnSnapshot) bool) ConnSnapshot {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		snapshot := stats.Snapshot()
+		if check(snapshot) {
+			return snapshot
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Connection stats did not reach the expected state, got %+v", snapshot)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConnStats(t *testing.T) {
+	stats := NewConnStats()
+	release := make(chan struct{})
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = stats.ConnState
+	server.Start()
+	defer server.Close()
+
+	// An accepted connection without a request yet is new
+	idleConn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	waitForConns(t, stats, func(s ConnSnapshot) bool { return s.New == 1 && s.Open == 1 && s.Accepted == 1 })
+
+	// A connection serving a request is active, and goes idle once it is done
+	if _, err := idleConn.Write([]byte("GET /slow HTTP/1.1\r\nHost: example.com\r\n\r\n")); err != nil {
+		t.Fatalf("Failed to write request: %v", err)
+	}
+	waitForConns(t, stats, func(s ConnSnapshot) bool { return s.Active == 1 && s.New == 0 })
+	close(release)
+	waitForConns(t, stats, func(s ConnSnapshot) bool { return s.Idle == 1 && s.Active == 0 })
+
+	// A second connection adds to the open count
+	other, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	waitForConns(t, stats, func(s ConnSnapshot) bool { return s.Open == 2 && s.Accepted == 2 })
+
+	// Closing connections removes them from the gauges but not the totals
+	idleConn.Close()
+	other.Close()
+	snapshot := waitForConns(t, stats, func(s ConnSnapshot) bool { return s.Open == 0 && s.Closed == 2 })
+	if snapshot.New != 0 || snapshot.Active != 0 || snapshot.Idle != 0 || snapshot.Accepted != 2 {
+		t.Errorf("Expected empty gauges with 2 accepted, got %+v", snapshot)
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxValidatedBodyBytes bounds the body ContentLength buffers when no limit is given
+const DefaultMaxValidatedBodyBytes = 10 << 20
+
+// ContentLength creates a middleware that rejects requests whose body does not match
+// their Content-Length with a 400 Bad Request, so handlers never process a truncated
+// body. The body is read up front, one byte past the declared length, and replayed
+// to the handler. Bodies declared larger than maxBytes are rejected with 413 instead,
+// and a maxBytes of zero or less uses DefaultMaxValidatedBodyBytes. Requests without
+// a declared length, such as chunked uploads, pass through unchanged
+func ContentLength(maxBytes int64) Middleware {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxValidatedBodyBytes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength < 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.ContentLength > maxBytes {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+				return
+			}
+
+			// The buffer grows with the bytes that arrive rather than the declared size
+			var body bytes.Buffer
+			n, err := io.CopyN(&body, r.Body, r.ContentLength+1)
+			r.Body.Close()
+			switch {
+			case err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF):
+				writeJSONError(w, http.StatusBadRequest, "Failed to read request body")
+				return
+			case n < r.ContentLength:
+				writeJSONError(w, http.StatusBadRequest, "Request body is shorter than Content-Length")
+				return
+			case n > r.ContentLength:
+				writeJSONError(w, http.StatusBadRequest, "Request body is longer than Content-Length")
+				return
+			}
+
+			r.Body = io.NopCloser(&body)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
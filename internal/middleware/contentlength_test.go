@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContentLength(t *testing.T) {
+	var received string
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := ContentLength(0)(testHandler)
+
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		expected      int
+	}{
+		{"matching", `{"id":1}`, 8, http.StatusOK},
+		{"too short", `{"id":1}`, 20, http.StatusBadRequest},
+		{"too long", `{"id":1}`, 3, http.StatusBadRequest},
+		{"undeclared", `{"id":1}`, -1, http.StatusOK},
+		{"too large", `{"id":1}`, DefaultMaxValidatedBodyBytes + 1, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			req := httptest.NewRequest("POST", "/orders", strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+			if tt.expected == http.StatusOK && received != tt.body {
+				t.Errorf("Expected the handler to read the full body, got %q", received)
+			}
+			if tt.expected != http.StatusOK && received != "" {
+				t.Error("Expected the handler not to run for a mismatched body")
+			}
+		})
+	}
+}
+
+func TestContentLengthServer(t *testing.T) {
+	ran := false
+	server := httptest.NewServer(ContentLength(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ran = true
+		w.WriteHeader(http.StatusOK)
+	})))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	// The client declares more than it sends, then stops sending
+	conn.Write([]byte("POST /orders HTTP/1.1\r\nHost: example.com\r\nContent-Length: 20\r\n\r\n{\"id\":1}"))
+	conn.(*net.TCPConn).CloseWrite()
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a short body, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+	if ran {
+		t.Error("Expected the handler not to run for a short body")
+	}
+}
+
+func TestContentLengthLimit(t *testing.T) {
+	handler := ContentLength(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for body, expected := range map[string]int{"abcd": http.StatusOK, "abcde": http.StatusRequestEntityTooLarge} {
+		req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != expected {
+			t.Errorf("Expected status %d for a %d byte body, got %d", expected, len(body), w.Code)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// RequireJSON creates a middleware that rejects request bodies that are not JSON with 415
+// The Content-Type is parsed as a media type, so casing, spacing and parameters such as
+// "Application/JSON ; charset=UTF-8" are accepted. application/json and structured
+// suffixes like application/problem+json are JSON; a charset other than UTF-8 is not.
+// Requests without a body pass through unchecked
+func RequireJSON() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hasRequestBody(r) && !isJSONContentType(r.Header.Get("Content-Type")) {
+				writeJSONError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasRequestBody reports whether r carries a body, declared or chunked
+func hasRequestBody(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	return r.ContentLength != 0
+}
+
+// isJSONContentType reports whether contentType is a UTF-8 JSON media type
+func isJSONContentType(contentType string) bool {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		return false
+	}
+	return mediaType == "application/json" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// hasMediaType reports whether contentType parses to mediaType, ignoring casing and parameters
+func hasMediaType(contentType, mediaType string) bool {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	return err == nil && parsed == mediaType
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireJSON(t *testing.T) {
+	handler := RequireJSON()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		contentType    string
+		expectedStatus int
+	}{
+		{"plain", "application/json", http.StatusOK},
+		{"charset", "application/json; charset=utf-8", http.StatusOK},
+		{"upper case charset", "application/json; charset=UTF-8", http.StatusOK},
+		{"mixed case type", "Application/JSON", http.StatusOK},
+		{"spacing", "application/json ;  charset=\"UTF-8\"", http.StatusOK},
+		{"structured suffix", "application/problem+json", http.StatusOK},
+		{"missing", "", http.StatusUnsupportedMediaType},
+		{"wrong type", "text/plain", http.StatusUnsupportedMediaType},
+		{"form", "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"json prefix only", "application/jsonp", http.StatusUnsupportedMediaType},
+		{"non UTF-8 charset", "application/json; charset=latin1", http.StatusUnsupportedMediaType},
+		{"malformed", "application/json; charset", http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/items", strings.NewReader(`{"name":"widget"}`))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d for %q, got %d", tt.expectedStatus, tt.contentType, rr.Code)
+			}
+		})
+	}
+}
+
+func TestRequireJSONWithoutBody(t *testing.T) {
+	handler := RequireJSON()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, method := range []string{"GET", "DELETE"} {
+		req := httptest.NewRequest(method, "/items", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected %s without a body to pass, got %d", method, rr.Code)
+		}
+	}
+}
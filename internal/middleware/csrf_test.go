@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	handler := CSRF()(testHandler)
+
+	t.Run("exempt method issues token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/form", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != CSRFCookieName || cookies[0].Value == "" {
+			t.Errorf("Expected a %s cookie to be set, got %v", CSRFCookieName, cookies)
+		}
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/form", nil)
+		req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: "token-123"})
+		req.Header.Set(CSRFHeaderName, "token-123")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if w.Body.String() != "OK" {
+			t.Errorf("Expected 'OK', got %s", w.Body.String())
+		}
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/form", nil)
+		req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: "token-123"})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("mismatched token", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/form", nil)
+		req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: "token-123"})
+		req.Header.Set(CSRFHeaderName, "token-456")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/form", nil)
+		req.Header.Set(CSRFHeaderName, "token-123")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxDedupeBodyBytes is the largest body hashed for deduplication, larger bodies are not deduplicated
+const maxDedupeBodyBytes = 1 << 20
+
+// maxDedupeEntries caps the requests remembered at once; while full, new requests
+// are processed without being deduplicated
+const maxDedupeEntries = 10000
+
+// dedupeEntry tracks a request body seen within the deduplication window
+type dedupeEntry struct {
+	expires time.Time
+	pending bool // The first request is still being handled
+}
+
+// Deduplicator drops requests whose body repeats one already processed within a TTL
+// It suits webhook receivers whose senders may deliver the same event more than once
+// and do not send an idempotency key. Requests are keyed by a SHA-256 hash of the
+// sender, method, path and body, so one sender cannot suppress another's request
+type Deduplicator struct {
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	entries    map[[sha256.Size]byte]dedupeEntry
+	lastSweep  time.Time
+	nowFunc    func() time.Time
+}
+
+// NewDeduplicator creates a Deduplicator remembering processed bodies for ttl
+func NewDeduplicator(ttl time.Duration) *Deduplicator {
+	return &Deduplicator{
+		ttl:        ttl,
+		maxEntries: maxDedupeEntries,
+		entries:    make(map[[sha256.Size]byte]dedupeEntry),
+		nowFunc:    time.Now,
+	}
+}
+
+// Middleware returns a middleware deduplicating POST, PUT and PATCH requests
+// A duplicate of a request that completed with a 2xx status gets 200 "already
+// processed" without running the handler. A duplicate arriving while the first is
+// still running gets 409 so the sender retries later. Failed requests are
+// forgotten so a redelivery is processed again
+func (d *Deduplicator) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxDedupeBodyBytes+1))
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, "Failed to read request body")
+				return
+			}
+			if len(body) > maxDedupeBodyBytes {
+				// Too large to hash, pass it through with the body restored
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+				next.ServeHTTP(w, r)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key := dedupeKey(r, body)
+			switch d.claim(key) {
+			case dedupeProcessed:
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				io.WriteString(w, `{"status":"success","message":"Request already processed"}`+"\n")
+				return
+			case dedupePending:
+				writeJSONError(w, http.StatusConflict, "An identical request is already being processed")
+				return
+			case dedupeFull:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			succeeded := false
+			defer func() { d.finish(key, succeeded) }()
+
+			next.ServeHTTP(rec, r)
+			succeeded = rec.status >= 200 && rec.status < 300
+		})
+	}
+}
+
+// dedupeState is the outcome of claiming a request key
+type dedupeState int
+
+const (
+	dedupeNew dedupeState = iota
+	dedupePending
+	dedupeProcessed
+	dedupeFull // Too many entries to remember another request
+)
+
+// claim records key as pending unless an unexpired entry already exists
+// When maxEntries are held, expired entries are swept early, and if none can be
+// dropped the request is not tracked
+func (d *Deduplicator) claim(key [sha256.Size]byte) dedupeState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.nowFunc()
+	d.sweep(now, false)
+
+	if entry, exists := d.entries[key]; exists && (entry.pending || now.Before(entry.expires)) {
+		if entry.pending {
+			return dedupePending
+		}
+		return dedupeProcessed
+	}
+	if len(d.entries) >= d.maxEntries {
+		if d.sweep(now, true); len(d.entries) >= d.maxEntries {
+			return dedupeFull
+		}
+	}
+	d.entries[key] = dedupeEntry{pending: true}
+	return dedupeNew
+}
+
+// finish remembers a successful request for the TTL and forgets a failed one
+func (d *Deduplicator) finish(key [sha256.Size]byte, succeeded bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if succeeded {
+		d.entries[key] = dedupeEntry{expires: d.nowFunc().Add(d.ttl)}
+	} else {
+		delete(d.entries, key)
+	}
+}
+
+// sweep removes expired entries at most once per TTL, or right away when forced
+func (d *Deduplicator) sweep(now time.Time, force bool) {
+	if !force && now.Sub(d.lastSweep) < d.ttl {
+		return
+	}
+	d.lastSweep = now
+	for key, entry := range d.entries {
+		if !entry.pending && !now.Before(entry.expires) {
+			delete(d.entries, key)
+		}
+	}
+}
+
+// dedupeKey hashes the sender, method, path and body of a request
+// The sender is the API key TierRateLimit validated together with the
+// Authorization header; each is length-prefixed so fields cannot run together
+func dedupeKey(r *http.Request, body []byte) [sha256.Size]byte {
+	h := sha256.New()
+	for _, field := range []string{validAPIKey(r.Context()), r.Header.Get("Authorization"), r.Method, r.URL.Path} {
+		fmt.Fprintf(h, "%d:%s\n", len(field), field)
+	}
+	h.Write(body)
+
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
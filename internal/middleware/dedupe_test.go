@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeduplicator(t *testing.T) {
+	dedupe := NewDeduplicator(time.Minute)
+	now := time.Unix(0, 0)
+	dedupe.nowFunc = func() time.Time { return now }
+
+	calls := 0
+	status := http.StatusOK
+	handler := dedupe.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/webhook", strings.NewReader(body)))
+		return w
+	}
+
+	t.Run("identical body is deduplicated", func(t *testing.T) {
+		send(`{"event":"paid","id":1}`)
+		w := send(`{"event":"paid","id":1}`)
+
+		if calls != 1 {
+			t.Errorf("Expected handler to run once, ran %d times", calls)
+		}
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "already processed") {
+			t.Errorf("Expected 200 already processed, got %d %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("different body is processed", func(t *testing.T) {
+		calls = 0
+		send(`{"event":"paid","id":2}`)
+		if calls != 1 {
+			t.Errorf("Expected handler to run once, ran %d times", calls)
+		}
+	})
+
+	t.Run("processed again after the TTL", func(t *testing.T) {
+		calls = 0
+		now = now.Add(2 * time.Minute)
+		send(`{"event":"paid","id":1}`)
+		if calls != 1 {
+			t.Errorf("Expected handler to run after expiry, ran %d times", calls)
+		}
+	})
+
+	t.Run("failed requests are not remembered", func(t *testing.T) {
+		calls = 0
+		status = http.StatusInternalServerError
+		send(`{"event":"refund"}`)
+		status = http.StatusOK
+		send(`{"event":"refund"}`)
+		if calls != 2 {
+			t.Errorf("Expected failed request to be retried, ran %d times", calls)
+		}
+	})
+}
+
+func TestDeduplicatorPending(t *testing.T) {
+	dedupe := NewDeduplicator(time.Minute)
+
+	var inner *httptest.ResponseRecorder
+	handler := dedupe.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A duplicate delivered while the first is still running is rejected for a retry
+		inner = httptest.NewRecorder()
+		dedupe.Middleware()(http.NotFoundHandler()).ServeHTTP(inner, httptest.NewRequest("POST", "/webhook", strings.NewReader("event")))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/webhook", strings.NewReader("event")))
+
+	if inner.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, inner.Code)
+	}
+}
+
+func TestDeduplicatorIgnoresGet(t *testing.T) {
+	calls := 0
+	handler := NewDeduplicator(time.Minute).Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/webhook", nil))
+	}
+	if calls != 2 {
+		t.Errorf("Expected GET requests to always run, ran %d times", calls)
+	}
+}
+
+func TestDeduplicatorSeparatesSenders(t *testing.T) {
+	calls := 0
+	handler := NewDeduplicator(time.Minute).Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	for _, auth := range []string{"Bearer alice", "Bearer bob", "Bearer alice"} {
+		req := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"event":"paid"}`))
+		req.Header.Set("Authorization", auth)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("Expected one run per sender, ran %d times", calls)
+	}
+}
+
+func TestDeduplicatorEntryCap(t *testing.T) {
+	dedupe := NewDeduplicator(time.Minute)
+	dedupe.maxEntries = 2
+	now := time.Unix(0, 0)
+	dedupe.nowFunc = func() time.Time { return now }
+
+	calls := 0
+	handler := dedupe.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	send := func(body string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/webhook", strings.NewReader(body)))
+	}
+
+	// Once full, further requests run untracked and are not remembered
+	send("a")
+	now = now.Add(30 * time.Second)
+	send("b")
+	send("c")
+	send("c")
+	if calls != 4 || len(dedupe.entries) != 2 {
+		t.Fatalf("Expected 4 runs with 2 entries kept, got %d runs and %d entries", calls, len(dedupe.entries))
+	}
+
+	// The regular sweep drops "a" to make room for "c"
+	now = now.Add(30 * time.Second)
+	send("c")
+	send("c")
+	if calls != 5 {
+		t.Errorf("Expected c to be tracked after the regular sweep, ran %d times", calls)
+	}
+
+	// "b" expires before the next regular sweep is due, so a full map is swept early
+	now = now.Add(35 * time.Second)
+	send("d")
+	send("d")
+	if calls != 6 {
+		t.Errorf("Expected d to be tracked after an early sweep, ran %d times", calls)
+	}
+}
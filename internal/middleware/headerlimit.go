@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// essentialHeaders are kept ahead of every other header when a response is truncated
+var essentialHeaders = []string{"Content-Type", "Content-Length", "Content-Encoding", "Location"}
+
+// ResponseHeaderLimit creates a middleware that caps the number of response header
+// fields and their total size in bytes (name plus values) just before they are written,
+// or once the handler returns if it never wrote.
+// Headers beyond either limit are dropped and logged, keeping Content-Type and other
+// essential headers first and the rest in name order. A zero limit is not enforced
+func ResponseHeaderLimit(maxCount, maxBytes int) Middleware {
+	return func(next http.Handler) http.Handler {
+		if maxCount <= 0 && maxBytes <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hw := &headerLimitWriter{ResponseWriter: w, r: r, maxCount: maxCount, maxBytes: maxBytes}
+			next.ServeHTTP(hw, r)
+			// net/http sends an implicit 200 for handlers that never wrote
+			if !hw.wroteHeader {
+				hw.limit()
+			}
+		})
+	}
+}
+
+// headerLimitWriter truncates the response headers when they are written
+type headerLimitWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	maxCount    int
+	maxBytes    int
+	wroteHeader bool
+}
+
+// limit drops and logs the response headers over the limits
+func (hw *headerLimitWriter) limit() {
+	if dropped := truncateHeaders(hw.Header(), hw.maxCount, hw.maxBytes); len(dropped) > 0 {
+		log.Printf("Dropped %d response headers over the limit for %s %s: %s",
+			len(dropped), hw.r.Method, hw.r.URL.Path, strings.Join(dropped, ", "))
+	}
+}
+
+func (hw *headerLimitWriter) WriteHeader(statusCode int) {
+	if !hw.wroteHeader {
+		hw.wroteHeader = true
+		hw.limit()
+	}
+	hw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (hw *headerLimitWriter) Write(b []byte) (int, error) {
+	if !hw.wroteHeader {
+		hw.WriteHeader(http.StatusOK)
+	}
+	return hw.ResponseWriter.Write(b)
+}
+
+// Flush applies the limits before a flush can send the headers
+func (hw *headerLimitWriter) Flush() {
+	if !hw.wroteHeader {
+		hw.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(hw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer for Hijack
+func (hw *headerLimitWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
+
+// truncateHeaders removes header fields beyond maxCount fields or maxBytes bytes
+// and returns the names of the removed fields
+func truncateHeaders(header http.Header, maxCount, maxBytes int) []string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	essential := make([]string, 0, len(essentialHeaders))
+	for _, name := range essentialHeaders {
+		if _, exists := header[name]; exists {
+			essential = append(essential, name)
+		}
+	}
+	ordered := append(essential, names...)
+
+	var dropped []string
+	seen := make(map[string]bool, len(header))
+	count, size := 0, 0
+	for _, name := range ordered {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		fieldSize := len(name)
+		for _, value := range header[name] {
+			fieldSize += len(value)
+		}
+		if (maxCount > 0 && count+1 > maxCount) || (maxBytes > 0 && size+fieldSize > maxBytes) {
+			header.Del(name)
+			dropped = append(dropped, name)
+			continue
+		}
+
+		count++
+		size += fieldSize
+	}
+	return dropped
+}
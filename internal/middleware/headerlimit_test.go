@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseHeaderLimit(t *testing.T) {
+	// A handler that accidentally sets many headers, one of them very large
+	excessive := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 20; i++ {
+			w.Header().Set(fmt.Sprintf("X-Debug-%02d", i), "value")
+		}
+		w.Header().Set("X-Huge", strings.Repeat("a", 4096))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+
+	tests := []struct {
+		name          string
+		maxCount      int
+		maxBytes      int
+		expectedCount int
+	}{
+		{"count limit", 5, 0, 5},
+		{"byte limit drops the huge header", 0, 1024, 21},
+		{"both limits", 3, 1024, 3},
+		{"disabled", 0, 0, 22},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ResponseHeaderLimit(tt.maxCount, tt.maxBytes)(excessive).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			if len(w.Header()) != tt.expectedCount {
+				t.Errorf("Expected %d headers, got %d: %v", tt.expectedCount, len(w.Header()), w.Header())
+			}
+			if w.Header().Get("Content-Type") != "application/json" {
+				t.Error("Expected Content-Type to be kept")
+			}
+			if tt.maxBytes > 0 && w.Header().Get("X-Huge") != "" {
+				t.Error("Expected the oversized header to be dropped")
+			}
+			if w.Body.String() != "{}" {
+				t.Errorf("Expected body %q, got %q", "{}", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestResponseHeaderLimitWithinLimits(t *testing.T) {
+	handler := ResponseHeaderLimit(10, 1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-One", "1")
+		w.Header().Set("X-Two", "2")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	if w.Header().Get("X-One") != "1" || w.Header().Get("X-Two") != "2" {
+		t.Errorf("Expected headers within the limits to be kept, got %v", w.Header())
+	}
+}
+
+func TestResponseHeaderLimitWithoutBody(t *testing.T) {
+	handler := ResponseHeaderLimit(2, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			w.Header().Set(fmt.Sprintf("X-Debug-%d", i), "value")
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if len(w.Header()) != 2 {
+		t.Errorf("Expected 2 headers, got %d: %v", len(w.Header()), w.Header())
+	}
+}
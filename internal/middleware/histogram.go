@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyHistogram counts request durations into fixed latency buckets
+// Unlike LatencyStats it keeps no samples, so downstream systems can estimate
+// percentiles across instances from the cumulative bucket counts
+type LatencyHistogram struct {
+	mu      sync.Mutex
+	bounds  []time.Duration
+	counts  []uint64 // counts[i] is requests no slower than bounds[i] and slower than bounds[i-1]
+	total   uint64
+	sum     time.Duration
+	nowFunc func() time.Time
+}
+
+// HistogramBucket is a cumulative bucket: the requests that took at most UpperBound
+type HistogramBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// HistogramSnapshot is a point-in-time copy of a LatencyHistogram
+// Buckets are cumulative and sorted by UpperBound, and Count includes requests
+// slower than the last bucket
+type HistogramSnapshot struct {
+	Buckets []HistogramBucket
+	Count   uint64
+	Sum     time.Duration
+}
+
+// NewLatencyHistogram creates a histogram with the given bucket upper bounds
+// Bounds are sorted and duplicates and non-positive values are dropped
+func NewLatencyHistogram(bounds []time.Duration) *LatencyHistogram {
+	sorted := make([]time.Duration, 0, len(bounds))
+	for _, bound := range bounds {
+		if bound > 0 {
+			sorted = append(sorted, bound)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	unique := sorted[:0]
+	for i, bound := range sorted {
+		if i == 0 || bound != sorted[i-1] {
+			unique = append(unique, bound)
+		}
+	}
+
+	return &LatencyHistogram{
+		bounds:  unique,
+		counts:  make([]uint64, len(unique)),
+		nowFunc: time.Now,
+	}
+}
+
+// Middleware returns a middleware recording the duration of every request
+func (h *LatencyHistogram) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := h.nowFunc()
+			next.ServeHTTP(w, r)
+			h.Observe(h.nowFunc().Sub(start))
+		})
+	}
+}
+
+// Observe records a request that took d
+func (h *LatencyHistogram) Observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] }); i < len(h.bounds) {
+		h.counts[i]++
+	}
+	h.total++
+	h.sum += d
+}
+
+// Snapshot returns the cumulative bucket counts, total count and summed duration
+func (h *LatencyHistogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshot := HistogramSnapshot{
+		Buckets: make([]HistogramBucket, len(h.bounds)),
+		Count:   h.total,
+		Sum:     h.sum,
+	}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		snapshot.Buckets[i] = HistogramBucket{UpperBound: bound, Count: cumulative}
+	}
+	return snapshot
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	histogram := NewLatencyHistogram([]time.Duration{
+		time.Second, 10 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond, 100 * time.Millisecond,
+	})
+
+	// Advance a fake clock by the handler's artificial latency instead of sleeping
+	now := time.Unix(0, 0)
+	histogram.nowFunc = func() time.Time { return now }
+	delayed := func(d time.Duration) http.Handler {
+		return histogram.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now = now.Add(d)
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+
+	for _, d := range []time.Duration{
+		5 * time.Millisecond,
+		10 * time.Millisecond, // bounds are inclusive
+		30 * time.Millisecond,
+		200 * time.Millisecond,
+		200 * time.Millisecond,
+		2 * time.Second, // only counted in +Inf
+	} {
+		delayed(d).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+
+	snapshot := histogram.Snapshot()
+	expected := []HistogramBucket{
+		{10 * time.Millisecond, 2},
+		{50 * time.Millisecond, 3},
+		{100 * time.Millisecond, 3},
+		{500 * time.Millisecond, 5},
+		{time.Second, 5},
+	}
+	if len(snapshot.Buckets) != len(expected) {
+		t.Fatalf("expected %d sorted unique buckets, got %v", len(expected), snapshot.Buckets)
+	}
+	for i, bucket := range expected {
+		if snapshot.Buckets[i] != bucket {
+			t.Errorf("bucket %d: expected %v, got %v", i, bucket, snapshot.Buckets[i])
+		}
+	}
+	if snapshot.Count != 6 {
+		t.Errorf("expected count 6, got %d", snapshot.Count)
+	}
+	if want := 2445 * time.Millisecond; snapshot.Sum != want {
+		t.Errorf("expected sum %v, got %v", want, snapshot.Sum)
+	}
+}
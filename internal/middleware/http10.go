@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HTTP10 creates a middleware that sets Connection: close for HTTP/1.0 requests
+// HTTP/1.0 clients assume the connection closes after each response unless they
+// explicitly ask for keep-alive, so the header makes that explicit. net/http
+// already avoids chunked encoding for these clients and closes the connection
+func HTTP10() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor == 1 && r.ProtoMinor == 0 && !strings.EqualFold(r.Header.Get("Connection"), "keep-alive") {
+				w.Header().Set("Connection", "close")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
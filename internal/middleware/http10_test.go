@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTP10(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	tests := []struct {
+		name       string
+		proto      string
+		connection string
+		expected   string
+	}{
+		{"http/1.0", "HTTP/1.0", "", "close"},
+		{"http/1.0 keep-alive", "HTTP/1.0", "Keep-Alive", ""},
+		{"http/1.1", "HTTP/1.1", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Proto = tt.proto
+			req.ProtoMajor, req.ProtoMinor, _ = http.ParseHTTPVersion(tt.proto)
+			if tt.connection != "" {
+				req.Header.Set("Connection", tt.connection)
+			}
+			w := httptest.NewRecorder()
+			HTTP10()(testHandler).ServeHTTP(w, req)
+
+			if got := w.Header().Get("Connection"); got != tt.expected {
+				t.Errorf("Expected Connection %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// RedirectHTTPS returns a handler redirecting every request to the same host, path and
+// query over HTTPS on port, with 308 Permanent Redirect so methods and bodies are kept.
+// The port is left out of the URL when it is the default 443
+func RedirectHTTPS(port int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		if port != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(port))
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
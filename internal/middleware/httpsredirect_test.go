@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectHTTPS(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     int
+		host     string
+		expected string
+	}{
+		{"default port", 443, "example.com", "https://example.com/path?q=1"},
+		{"strips HTTP port", 443, "example.com:8080", "https://example.com/path?q=1"},
+		{"custom port", 8443, "example.com:8080", "https://example.com:8443/path?q=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/path?q=1", nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+
+			RedirectHTTPS(tt.port).ServeHTTP(w, req)
+
+			if w.Code != http.StatusPermanentRedirect {
+				t.Errorf("Expected status %d, got %d", http.StatusPermanentRedirect, w.Code)
+			}
+			if location := w.Header().Get("Location"); location != tt.expected {
+				t.Errorf("Expected Location %q, got %q", tt.expected, location)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencySamples is the number of recent requests kept per route
+const DefaultLatencySamples = 1024
+
+// RouteLatency summarizes the latency of a single route
+type RouteLatency struct {
+	Route string        `json:"route"`
+	Count uint64        `json:"count"`
+	Max   time.Duration `json:"max_ns"`
+	P95   time.Duration `json:"p95_ns"`
+}
+
+// routeSamples holds a ring buffer of the most recent latencies for a route
+type routeSamples struct {
+	samples []time.Duration
+	next    int
+	count   uint64
+	max     time.Duration
+}
+
+// LatencyStats aggregates per-route request latencies
+// Memory is bounded by keeping only the most recent samples for each route,
+// and routes are the registered patterns rather than raw request paths
+type LatencyStats struct {
+	mu      sync.Mutex
+	size    int
+	routes  map[string]*routeSamples
+	nowFunc func() time.Time
+}
+
+// NewLatencyStats creates a latency aggregator keeping up to size samples per route
+// A non-positive size uses DefaultLatencySamples
+func NewLatencyStats(size int) *LatencyStats {
+	if size <= 0 {
+		size = DefaultLatencySamples
+	}
+	return &LatencyStats{
+		size:    size,
+		routes:  make(map[string]*routeSamples),
+		nowFunc: time.Now,
+	}
+}
+
+// Middleware returns a middleware recording the latency of every request under route
+func (s *LatencyStats) Middleware(route string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := s.nowFunc()
+			next.ServeHTTP(w, r)
+			s.Record(route, s.nowFunc().Sub(start))
+		})
+	}
+}
+
+// Record adds a latency sample for route
+func (s *LatencyStats) Record(route string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs, exists := s.routes[route]
+	if !exists {
+		rs = &routeSamples{samples: make([]time.Duration, 0, s.size)}
+		s.routes[route] = rs
+	}
+
+	if len(rs.samples) < s.size {
+		rs.samples = append(rs.samples, d)
+	} else {
+		rs.samples[rs.next] = d
+	}
+	rs.next = (rs.next + 1) % s.size
+	rs.count++
+	if d > rs.max {
+		rs.max = d
+	}
+}
+
+// Snapshot returns the latency summary of every route, slowest p95 first
+func (s *LatencyStats) Snapshot() []RouteLatency {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries := make([]RouteLatency, 0, len(s.routes))
+	for route, rs := range s.routes {
+		sorted := make([]time.Duration, len(rs.samples))
+		copy(sorted, rs.samples)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		summaries = append(summaries, RouteLatency{
+			Route: route,
+			Count: rs.count,
+			Max:   rs.max,
+			P95:   percentile(sorted, 0.95),
+		})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].P95 != summaries[j].P95 {
+			return summaries[i].P95 > summaries[j].P95
+		}
+		return summaries[i].Route < summaries[j].Route
+	})
+	return summaries
+}
+
+// percentile returns the nearest-rank percentile p of sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLatencyStatsSlowRoute(t *testing.T) {
+	stats := NewLatencyStats(0)
+
+	// Advance a fake clock by the handler's artificial latency instead of sleeping
+	now := time.Unix(0, 0)
+	stats.nowFunc = func() time.Time { return now }
+	delayed := func(d time.Duration) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now = now.Add(d)
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+
+	fast := stats.Middleware("/fast")(delayed(time.Millisecond))
+	slow := stats.Middleware("/slow")(delayed(50 * time.Millisecond))
+
+	for i := 0; i < 20; i++ {
+		fast.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fast", nil))
+		slow.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	}
+
+	snapshot := stats.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("Expected 2 routes, got %d", len(snapshot))
+	}
+	if snapshot[0].Route != "/slow" {
+		t.Errorf("Expected /slow to be listed first, got %s", snapshot[0].Route)
+	}
+	if snapshot[0].P95 <= snapshot[1].P95 || snapshot[0].Max <= snapshot[1].Max {
+		t.Errorf("Expected /slow to have higher latency than /fast, got %+v", snapshot)
+	}
+	if snapshot[0].Count != 20 {
+		t.Errorf("Expected 20 requests recorded, got %d", snapshot[0].Count)
+	}
+}
+
+func TestLatencyStatsBoundedSamples(t *testing.T) {
+	stats := NewLatencyStats(4)
+
+	for i := 1; i <= 10; i++ {
+		stats.Record("/", time.Duration(i)*time.Millisecond)
+	}
+
+	if n := len(stats.routes["/"].samples); n != 4 {
+		t.Errorf("Expected 4 retained samples, got %d", n)
+	}
+
+	snapshot := stats.Snapshot()
+	if snapshot[0].Count != 10 {
+		t.Errorf("Expected count 10, got %d", snapshot[0].Count)
+	}
+	if snapshot[0].Max != 10*time.Millisecond {
+		t.Errorf("Expected max 10ms, got %v", snapshot[0].Max)
+	}
+	if snapshot[0].P95 != 10*time.Millisecond {
+		t.Errorf("Expected p95 over the most recent samples to be 10ms, got %v", snapshot[0].P95)
+	}
+}
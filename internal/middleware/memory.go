@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryGuard sheds load while heap usage is above a configured limit
+// The heap is sampled periodically by Run rather than on every request, since
+// runtime.ReadMemStats briefly stops the world
+type MemoryGuard struct {
+	limit      uint64
+	retryAfter time.Duration
+	overLimit  atomic.Bool
+	readHeap   func() uint64
+}
+
+// NewMemoryGuard creates a guard that sheds load once heap usage exceeds limit bytes
+func NewMemoryGuard(limit uint64) *MemoryGuard {
+	return &MemoryGuard{
+		limit:      limit,
+		retryAfter: DefaultRetryAfter,
+		readHeap: func() uint64 {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			return stats.HeapAlloc
+		},
+	}
+}
+
+// SetRetryAfter sets the Retry-After sent with shed requests
+func (g *MemoryGuard) SetRetryAfter(d time.Duration) {
+	g.retryAfter = d
+}
+
+// Check samples heap usage, updates the guard state and reports whether it is over the limit
+// State changes are logged so operators can see when shedding starts and stops
+func (g *MemoryGuard) Check() bool {
+	heap := g.readHeap()
+	over := heap > g.limit
+
+	if g.overLimit.Swap(over) != over {
+		if over {
+			log.Printf("Heap usage %d bytes exceeds limit %d bytes, shedding load", heap, g.limit)
+		} else {
+			log.Printf("Heap usage %d bytes back under limit %d bytes, accepting requests", heap, g.limit)
+		}
+	}
+	return over
+}
+
+// Run checks heap usage every interval until ctx is done
+func (g *MemoryGuard) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	g.Check()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.Check()
+		}
+	}
+}
+
+// Middleware returns a middleware responding 503 Service Unavailable while over the limit
+func (g *MemoryGuard) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if g.overLimit.Load() {
+				SetRetryAfter(w, g.retryAfter)
+				writeJSONError(w, http.StatusServiceUnavailable, "Server is under memory pressure")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestMemoryGuard(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	t.Run("low threshold sheds load", func(t *testing.T) {
+		guard := NewMemoryGuard(1)
+		if !guard.Check() {
+			t.Fatal("Expected heap usage to exceed a 1 byte limit")
+		}
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		guard.Middleware()(testHandler).ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Error("Expected Retry-After header on shed request")
+		}
+		if _, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil {
+			t.Errorf("Expected Retry-After in delay-seconds, got %q", w.Header().Get("Retry-After"))
+		}
+	})
+
+	t.Run("recovers when heap drops", func(t *testing.T) {
+		heap := uint64(200)
+		guard := NewMemoryGuard(100)
+		guard.readHeap = func() uint64 { return heap }
+
+		guard.Check()
+		heap = 50
+		guard.Check()
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		guard.Middleware()(testHandler).ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AllowMethods creates a middleware that rejects methods not in methods with 405
+// HEAD is allowed whenever GET is, and an empty list allows every method
+func AllowMethods(methods []string) Middleware {
+	if len(methods) == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	allowed := make(map[string]bool, len(methods)+1)
+	var allow []string
+	for _, method := range methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" || allowed[method] {
+			continue
+		}
+		allowed[method] = true
+		allow = append(allow, method)
+	}
+	if allowed[http.MethodGet] && !allowed[http.MethodHead] {
+		allowed[http.MethodHead] = true
+		allow = append(allow, http.MethodHead)
+	}
+	allowHeader := strings.Join(allow, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !allowed[r.Method] {
+				w.Header().Set("Allow", allowHeader)
+				writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DenyMethods creates a middleware that rejects the listed methods with 405
+// Methods are matched case-insensitively and an empty list denies nothing
+func DenyMethods(methods []string) Middleware {
+	if len(methods) == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	denied := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+			denied[method] = true
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if denied[r.Method] {
+				writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowMethods(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		methods  []string
+		method   string
+		expected int
+	}{
+		{"allowed", []string{"GET"}, "GET", http.StatusOK},
+		{"head implied by get", []string{"get"}, "HEAD", http.StatusOK},
+		{"rejected", []string{"GET"}, "POST", http.StatusMethodNotAllowed},
+		{"head only", []string{"HEAD"}, "GET", http.StatusMethodNotAllowed},
+		{"empty allows all", nil, "DELETE", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			AllowMethods(tt.methods)(testHandler).ServeHTTP(w, httptest.NewRequest(tt.method, "/health", nil))
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+			if tt.expected == http.StatusMethodNotAllowed && w.Header().Get("Allow") == "" {
+				t.Error("Expected Allow header on 405 response")
+			}
+		})
+	}
+}
+
+func TestDenyMethods(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := DenyMethods([]string{"trace", "CONNECT"})(testHandler)
+
+	for method, expected := range map[string]int{
+		"GET":     http.StatusOK,
+		"TRACE":   http.StatusMethodNotAllowed,
+		"CONNECT": http.StatusMethodNotAllowed,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/", nil))
+
+		if w.Code != expected {
+			t.Errorf("Expected status %d for %s, got %d", expected, method, w.Code)
+		}
+	}
+}
@@ -1,8 +1,17 @@
 package middleware
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,19 +32,421 @@ func Chain(middlewares ...Middleware) Middleware {
 	}
 }
 
+// logLocation is the time zone request log timestamps are rendered in, local time when unset
+var logLocation atomic.Pointer[time.Location]
+
+// SetLogLocation sets the time zone used for request log timestamps
+func SetLogLocation(loc *time.Location) {
+	logLocation.Store(loc)
+}
+
+// logTimestamp formats t for request logs in the configured time zone
+func logTimestamp(t time.Time) string {
+	if loc := logLocation.Load(); loc != nil {
+		t = t.In(loc)
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// requestSeq numbers requests in the order they reach a Logger, for the life of the process
+var requestSeq atomic.Uint64
+
 // Logger creates a middleware that logs HTTP requests
-// It logs the request method, path, and timestamp for each request
+// It logs the request method, path, and timestamp for each request, along with a
+// process-wide sequence number and the request ID when one is available, so log
+// lines can be ordered even when timestamps collide. If writing the response fails
+// because the client disconnected, a second line with status 499 is logged
 // The enabled parameter allows configurable logging enable/disable functionality
 func Logger(enabled bool) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if enabled {
 				start := time.Now()
-				log.Printf("[%s] %s %s",
-					start.Format("2006-01-02 15:04:05"),
-					r.Method,
-					r.URL.Path)
+				seq := requestSeq.Add(1)
+				rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK, ctx: r.Context()}
+				w = rec
+				defer func() {
+					if rec.broken {
+						log.Printf("[%s] #%d %s %s %d client closed request",
+							logTimestamp(start),
+							seq,
+							r.Method,
+							r.URL.Path,
+							StatusClientClosedRequest)
+					}
+				}()
+
+				requestID := loggedRequestID(r)
+
+				if requestID != "" {
+					log.Printf("[%s] #%d %s %s request_id=%s",
+						logTimestamp(start),
+						seq,
+						r.Method,
+						r.URL.Path,
+						requestID)
+				} else {
+					log.Printf("[%s] #%d %s %s",
+						logTimestamp(start),
+						seq,
+						r.Method,
+						r.URL.Path)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequestBudget creates a middleware that enforces an overall deadline on a request
+// It is meant to be the outermost wrapper so the budget covers every middleware
+// and the final handler. The deadline is set on the request context, and a request
+// that gives up at it without writing anything receives a 503 Service Unavailable.
+// The response is not buffered, so streaming responses and upgrades still work
+// A budget of zero or less disables the deadline
+func RequestBudget(budget time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		if budget <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serveWithDeadline(w, r, next, budget, "Request exceeded the total processing budget")
+		})
+	}
+}
+
+// serveWithDeadline runs next under a context deadline of timeout. next runs on its
+// own goroutine so that one ignoring the context is still bounded: if the deadline
+// passes before it started a response, a 503 with message is written straight away
+// and anything next writes afterwards is dropped, as http.TimeoutHandler does.
+// Unlike TimeoutHandler the response is not buffered, so once next has started
+// writing it is left to finish. A panic in next is re-raised on the caller's goroutine
+func serveWithDeadline(w http.ResponseWriter, r *http.Request, next http.Handler, timeout time.Duration, message string) {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	sw := &startedWriter{ResponseWriter: w, header: make(http.Header)}
+	done := make(chan struct{})
+	panicChan := make(chan any, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicChan <- p
+				return
+			}
+			close(done)
+		}()
+		next.ServeHTTP(sw, r.WithContext(ctx))
+	}()
+
+	select {
+	case p := <-panicChan:
+		panic(p)
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && sw.timeout() {
+		writeJSONError(w, http.StatusServiceUnavailable, message)
+		// The handler may still panic after the 503 went out, with no caller left to recover it
+		go func() {
+			select {
+			case p := <-panicChan:
+				if p != http.ErrAbortHandler {
+					log.Printf("Panic after deadline serving %s %s: %v", r.Method, r.URL.Path, p)
+				}
+			case <-done:
+			}
+		}()
+		return
+	}
+
+	// The response already started, or the client went away, so let next finish
+	select {
+	case p := <-panicChan:
+		panic(p)
+	case <-done:
+	}
+}
+
+// startedWriter records whether a response was started or the connection taken over
+// Headers are collected in a private map until the response starts, so a handler
+// that is cut off at the deadline never touches the underlying writer again
+type startedWriter struct {
+	http.ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	started  bool
+	timedOut bool
+}
+
+// timeout marks the writer as timed out unless the response already started
+// It reports whether the caller now owns the underlying writer
+func (sw *startedWriter) timeout() bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.started {
+		return false
+	}
+	sw.timedOut = true
+	return true
+}
+
+// start copies the collected headers to the underlying writer the first time
+// the response is written. It must be called with mu held
+func (sw *startedWriter) start() {
+	if sw.started {
+		return
+	}
+	sw.started = true
+	dst := sw.ResponseWriter.Header()
+	for k, v := range sw.header {
+		dst[k] = v
+	}
+}
+
+func (sw *startedWriter) Header() http.Header {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.started {
+		return sw.ResponseWriter.Header()
+	}
+	return sw.header
+}
+
+func (sw *startedWriter) WriteHeader(statusCode int) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.timedOut {
+		return
+	}
+	sw.start()
+	sw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (sw *startedWriter) Write(b []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	sw.start()
+	return sw.ResponseWriter.Write(b)
+}
+
+// Flush sends the headers, so the response counts as started
+func (sw *startedWriter) Flush() {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.timedOut {
+		return
+	}
+	sw.start()
+	http.NewResponseController(sw.ResponseWriter).Flush()
+}
+
+// Hijack takes over the connection, after which no error response can be written
+func (sw *startedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	conn, rw, err := http.NewResponseController(sw.ResponseWriter).Hijack()
+	if err == nil {
+		sw.started = true
+	}
+	return conn, rw, err
+}
+
+// SetWriteDeadline is refused once the deadline response has taken over the writer
+func (sw *startedWriter) SetWriteDeadline(deadline time.Time) error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.timedOut {
+		return http.ErrHandlerTimeout
+	}
+	return http.NewResponseController(sw.ResponseWriter).SetWriteDeadline(deadline)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (sw *startedWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// QueryPolicy selects which value is kept when a query parameter is repeated
+type QueryPolicy int
+
+const (
+	// QueryFirstWins keeps the first occurrence of a repeated query parameter
+	QueryFirstWins QueryPolicy = iota
+	// QueryLastWins keeps the last occurrence of a repeated query parameter
+	QueryLastWins
+)
+
+// CanonicalQuery creates a middleware that canonicalizes the request query string
+// Parameter names are lowercased and repeated parameters are collapsed to a single
+// value according to the policy, so handlers can read r.URL.Query() without
+// defending against duplicates or inconsistent casing
+func CanonicalQuery(policy QueryPolicy) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.RawQuery == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			canonical := url.Values{}
+			for _, pair := range strings.Split(r.URL.RawQuery, "&") {
+				if pair == "" {
+					continue
+				}
+				rawKey, rawValue, _ := strings.Cut(pair, "=")
+				key, err := url.QueryUnescape(rawKey)
+				if err != nil {
+					continue
+				}
+				value, err := url.QueryUnescape(rawValue)
+				if err != nil {
+					continue
+				}
+
+				key = strings.ToLower(key)
+				if _, exists := canonical[key]; exists && policy == QueryFirstWins {
+					continue
+				}
+				canonical.Set(key, value)
+			}
+
+			// Shallow copy the request so the caller's URL is left untouched
+			r2 := new(http.Request)
+			*r2 = *r
+			u := *r.URL
+			u.RawQuery = canonical.Encode()
+			r2.URL = &u
+
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
+
+// PathTraversalGuard creates a middleware that rejects requests whose raw URL contains
+// path traversal sequences, including percent-encoded and double-encoded forms such as
+// "%2e%2e" or "%252e%252e". Rejected requests receive a 400 Bad Request and are logged
+// as a potential attack
+func PathTraversalGuard() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawPath := r.URL.EscapedPath()
+			if r.RequestURI != "" {
+				rawPath, _, _ = strings.Cut(r.RequestURI, "?")
+			}
+
+			if containsTraversal(rawPath) {
+				log.Printf("Rejected potential path traversal attack from %s: %s %s",
+					r.RemoteAddr, r.Method, rawPath)
+				writeJSONError(w, http.StatusBadRequest, "Invalid request path")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maxTraversalDecodes bounds how many layers of percent-encoding are unwrapped
+const maxTraversalDecodes = 3
+
+// containsTraversal reports whether the raw path contains a ".." segment once
+// decoded, unwrapping nested percent-encoding and treating backslashes as separators
+func containsTraversal(rawPath string) bool {
+	decoded := rawPath
+	for i := 0; i <= maxTraversalDecodes; i++ {
+		normalized := strings.ReplaceAll(decoded, "\\", "/")
+		for _, segment := range strings.Split(normalized, "/") {
+			if segment == ".." {
+				return true
+			}
+		}
+
+		next, err := url.PathUnescape(decoded)
+		if err != nil || next == decoded {
+			break
+		}
+		decoded = next
+	}
+
+	// Overlong UTF-8 encodings of "." and "/" are never legitimate in a path
+	lower := strings.ToLower(rawPath)
+	return strings.Contains(lower, "%c0%ae") || strings.Contains(lower, "%c0%af")
+}
+
+// errorBody is the JSON error body written by writeJSONError
+type errorBody struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// writeJSONError writes a minimal JSON error body matching the handlers response shape
+func writeJSONError(w http.ResponseWriter, statusCode int, message string) {
+	body, _ := json.Marshal(errorBody{Status: "error", Message: message})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(append(body, '\n'))
+}
+
+// CacheControl creates a middleware that sets the Cache-Control response header
+// An empty value leaves the response untouched
+func CacheControl(value string) Middleware {
+	return func(next http.Handler) http.Handler {
+		if value == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MethodOverrideHeader is the header clients use to request a method override
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// overridableMethods lists the methods a POST request may be overridden to
+var overridableMethods = map[string]bool{
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// MethodOverride creates a middleware that lets POST requests specify their effective method
+// The method is taken from the X-HTTP-Method-Override header or, for form posts, the
+// _method field. Only PUT, PATCH and DELETE are accepted; any other override is
+// rejected with a 400 Bad Request. Requests with other methods pass through unchanged
+func MethodOverride() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			override := r.Header.Get(MethodOverrideHeader)
+			if override == "" && hasMediaType(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+				override = r.PostFormValue("_method")
+			}
+			if override == "" {
+				next.ServeHTTP(w, r)
+				return
 			}
+
+			method := strings.ToUpper(strings.TrimSpace(override))
+			if !overridableMethods[method] {
+				writeJSONError(w, http.StatusBadRequest, "Invalid method override")
+				return
+			}
+
+			r.Method = method
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -2,12 +2,15 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestChain(t *testing.T) {
@@ -213,3 +216,433 @@ func TestLoggerWithChain(t *testing.T) {
 		t.Errorf("Expected log to contain '/api/test', got: %s", logOutput)
 	}
 }
+
+func TestRequestBudget(t *testing.T) {
+	t.Run("slow middleware exceeds budget", func(t *testing.T) {
+		slowMiddleware := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(200 * time.Millisecond):
+				case <-r.Context().Done():
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		}
+
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		})
+
+		handler := Chain(RequestBudget(20*time.Millisecond), slowMiddleware)(testHandler)
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		if strings.Contains(w.Body.String(), "OK") {
+			t.Errorf("Expected handler output to be discarded, got %s", w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected JSON error body, got content type %q", ct)
+		}
+	})
+
+	t.Run("middleware ignoring the context is still bounded", func(t *testing.T) {
+		release := make(chan struct{})
+		finished := make(chan struct{})
+		blockingMiddleware := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer close(finished)
+				<-release
+				w.Header().Set("X-Late", "1")
+				next.ServeHTTP(w, r)
+			})
+		}
+
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		})
+
+		handler := Chain(RequestBudget(20*time.Millisecond), blockingMiddleware)(testHandler)
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		start := time.Now()
+		handler.ServeHTTP(w, req)
+		elapsed := time.Since(start)
+		close(release)
+		<-finished
+
+		if elapsed > time.Second {
+			t.Errorf("Expected the budget to answer without waiting for the middleware, took %v", elapsed)
+		}
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		if strings.Contains(w.Body.String(), "OK") {
+			t.Errorf("Expected late handler output to be dropped, got %s", w.Body.String())
+		}
+		if w.Header().Get("X-Late") != "" {
+			t.Error("Expected headers set after the deadline to be dropped")
+		}
+	})
+
+	t.Run("panic is re-raised to the caller", func(t *testing.T) {
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})
+
+		handler := Chain(Recover(), RequestBudget(time.Second))(testHandler)
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+
+	t.Run("streaming response keeps flusher and hijacker", func(t *testing.T) {
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Deadline(); !ok {
+				t.Error("Expected a deadline on the request context")
+			}
+			if r.URL.Path == "/upgrade" {
+				conn, _, err := http.NewResponseController(w).Hijack()
+				if err != nil {
+					t.Errorf("Expected hijack to be supported, got %v", err)
+					return
+				}
+				conn.Close()
+				return
+			}
+			w.Write([]byte("chunk"))
+			if err := http.NewResponseController(w).Flush(); err != nil {
+				t.Errorf("Expected flush to be supported, got %v", err)
+			}
+		})
+		server := httptest.NewServer(RequestBudget(time.Second)(testHandler))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/stream")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if _, err := http.Get(server.URL + "/upgrade"); err == nil {
+			t.Error("Expected the hijacked connection to be closed without a response")
+		}
+	})
+
+	t.Run("request within budget", func(t *testing.T) {
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		})
+
+		handler := RequestBudget(time.Second)(testHandler)
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if w.Body.String() != "OK" {
+			t.Errorf("Expected 'OK', got %s", w.Body.String())
+		}
+	})
+
+	t.Run("zero budget disables deadline", func(t *testing.T) {
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Deadline(); ok {
+				t.Error("Expected no deadline on request context")
+			}
+			w.Write([]byte("OK"))
+		})
+
+		handler := RequestBudget(0)(testHandler)
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Body.String() != "OK" {
+			t.Errorf("Expected 'OK', got %s", w.Body.String())
+		}
+	})
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   QueryPolicy
+		query    string
+		expected string
+	}{
+		{"first wins", QueryFirstWins, "a=1&a=2", "1"},
+		{"last wins", QueryLastWins, "a=1&a=2", "2"},
+		{"mixed casing first wins", QueryFirstWins, "A=1&a=2", "1"},
+		{"mixed casing last wins", QueryLastWins, "A=1&a=2", "2"},
+		{"encoded values", QueryFirstWins, "a=hello%20world&a=x", "hello world"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var values []string
+			testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				values = r.URL.Query()["a"]
+			})
+
+			handler := CanonicalQuery(tt.policy)(testHandler)
+			req := httptest.NewRequest("GET", "/test?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if len(values) != 1 || values[0] != tt.expected {
+				t.Errorf("Expected single value %q, got %v", tt.expected, values)
+			}
+			if req.URL.RawQuery != tt.query {
+				t.Errorf("Expected original request query to be untouched, got %s", req.URL.RawQuery)
+			}
+		})
+	}
+}
+
+func TestPathTraversalGuard(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	handler := PathTraversalGuard()(testHandler)
+
+	rejected := []string{
+		"/static/%2e%2e/etc/passwd",
+		"/static/%2E%2E/etc/passwd",
+		"/static/.%2e/etc/passwd",
+		"/static/%2e./etc/passwd",
+		"/static/..%2fetc/passwd",
+		"/static/%2e%2e%2fetc%2fpasswd",
+		"/static/%252e%252e/etc/passwd",
+		"/static/..%5cetc/passwd",
+		"/static/%c0%ae%c0%ae/etc/passwd",
+		"/static/../etc/passwd",
+	}
+
+	for _, path := range rejected {
+		t.Run(path, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest("GET", path, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			if !strings.Contains(buf.String(), "path traversal") {
+				t.Errorf("Expected rejection to be logged, got: %s", buf.String())
+			}
+		})
+	}
+
+	allowed := []string{"/static/app.js", "/files/report..final.pdf", "/search?q=%2e%2e"}
+	for _, path := range allowed {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", path, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			if w.Body.String() != "OK" {
+				t.Errorf("Expected 'OK', got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	t.Run("header set", func(t *testing.T) {
+		handler := CacheControl("max-age=60")(testHandler)
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if cc := w.Header().Get("Cache-Control"); cc != "max-age=60" {
+			t.Errorf("Expected Cache-Control 'max-age=60', got %s", cc)
+		}
+	})
+
+	t.Run("empty value sends no header", func(t *testing.T) {
+		handler := CacheControl("")(testHandler)
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if _, exists := w.Header()["Cache-Control"]; exists {
+			t.Errorf("Expected no Cache-Control header, got %s", w.Header().Get("Cache-Control"))
+		}
+	})
+}
+
+func TestMethodOverride(t *testing.T) {
+	var method string
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+	})
+	handler := MethodOverride()(testHandler)
+
+	t.Run("header overrides POST to PUT", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/items/1", nil)
+		req.Header.Set(MethodOverrideHeader, "PUT")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if method != http.MethodPut {
+			t.Errorf("Expected method PUT, got %s", method)
+		}
+	})
+
+	t.Run("form field overrides POST to DELETE", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/items/1", strings.NewReader("_method=delete"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if method != http.MethodDelete {
+			t.Errorf("Expected method DELETE, got %s", method)
+		}
+	})
+
+	t.Run("form field read regardless of content type casing", func(t *testing.T) {
+		method = ""
+		req := httptest.NewRequest("POST", "/items/1", strings.NewReader("_method=patch"))
+		req.Header.Set("Content-Type", "Application/X-WWW-Form-Urlencoded ; charset=UTF-8")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if method != http.MethodPatch {
+			t.Errorf("Expected method PATCH, got %s", method)
+		}
+	})
+
+	t.Run("invalid override is rejected", func(t *testing.T) {
+		method = ""
+		req := httptest.NewRequest("POST", "/items/1", nil)
+		req.Header.Set(MethodOverrideHeader, "CONNECT")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+		if method != "" {
+			t.Errorf("Expected handler not to run, got method %s", method)
+		}
+	})
+
+	t.Run("GET is never overridden", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/items/1", nil)
+		req.Header.Set(MethodOverrideHeader, "DELETE")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if method != http.MethodGet {
+			t.Errorf("Expected method GET, got %s", method)
+		}
+	})
+}
+
+func TestLoggerSequenceNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	handler := Logger(true)(testHandler)
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(RequestIDHeader, "req-"+strconv.Itoa(i))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 log lines, got %d: %s", len(lines), buf.String())
+	}
+
+	var previous uint64
+	for i, line := range lines {
+		_, rest, found := strings.Cut(line, "#")
+		if !found {
+			t.Fatalf("Expected sequence number in log line: %s", line)
+		}
+		seqStr, _, _ := strings.Cut(rest, " ")
+		seq, err := strconv.ParseUint(seqStr, 10, 64)
+		if err != nil {
+			t.Fatalf("Expected numeric sequence in log line %q: %v", line, err)
+		}
+		if i > 0 && seq != previous+1 {
+			t.Errorf("Expected sequence %d to follow %d", seq, previous)
+		}
+		previous = seq
+
+		if !strings.Contains(line, "request_id=req-"+strconv.Itoa(i)) {
+			t.Errorf("Expected request ID in log line: %s", line)
+		}
+	}
+}
+
+func TestLoggerTimezone(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+		SetLogLocation(nil)
+	}()
+
+	// Render log timestamps 14 hours ahead of UTC, then check the logged hour
+	loc := time.FixedZone("UTC+14", 14*60*60)
+	SetLogLocation(loc)
+
+	before := time.Now().In(loc)
+	Logger(true)(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/tz", nil))
+	after := time.Now().In(loc)
+
+	line := buf.String()
+	if !strings.Contains(line, before.Format("2006-01-02 15:04")) && !strings.Contains(line, after.Format("2006-01-02 15:04")) {
+		t.Errorf("Expected log timestamp in the configured zone around %s, got %s", before.Format("2006-01-02 15:04"), line)
+	}
+}
+
+func TestWriteJSONErrorEncodesMessage(t *testing.T) {
+	for _, message := range []string{`quote " and \ backslash`, "control \x7f\x01 bytes", "invalid \xff utf-8", "<script>"} {
+		w := httptest.NewRecorder()
+		writeJSONError(w, http.StatusBadRequest, message)
+
+		var body struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Errorf("Expected valid JSON for %q, got %v: %s", message, err, w.Body.String())
+			continue
+		}
+		if body.Status != "error" || body.Message != strings.ToValidUTF8(message, "\uFFFD") {
+			t.Errorf("Expected the message %q to round-trip, got %+v", message, body)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Options creates a middleware answering OPTIONS requests that carry no Origin header
+// Such requests come from non-browser clients and are not CORS preflights, so they
+// get status (200 or 204) with an Allow header listing methods instead of reaching
+// the route handler. Any other status leaves OPTIONS requests untouched
+func Options(status int, methods []string) Middleware {
+	if status != http.StatusOK && status != http.StatusNoContent {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	allow := strings.Join(methods, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodOptions || r.Header.Get("Origin") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Allow", allow)
+			if status == http.StatusOK {
+				w.Header().Set("Content-Length", "0")
+			}
+			w.WriteHeader(status)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOptionsWithoutOrigin(t *testing.T) {
+	methods := []string{"GET", "POST", "OPTIONS"}
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tests := []struct {
+		name          string
+		status        int
+		origin        string
+		expected      int
+		expectedAllow string
+	}{
+		{"no content", http.StatusNoContent, "", http.StatusNoContent, "GET, POST, OPTIONS"},
+		{"ok", http.StatusOK, "", http.StatusOK, "GET, POST, OPTIONS"},
+		{"disabled passes through", 0, "", http.StatusTeapot, ""},
+		{"with origin passes through", http.StatusNoContent, "https://example.com", http.StatusTeapot, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Options(tt.status, methods)(testHandler)
+			req := httptest.NewRequest("OPTIONS", "/api/users", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.expectedAllow {
+				t.Errorf("Expected Allow %q, got %q", tt.expectedAllow, allow)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"strings"
+)
+
+// OriginSet matches request origins against a list of allowed origins
+// Exact origins are kept in a map for constant-time lookup, so large lists cost
+// the same per request as small ones. Origins containing a single "*" are
+// wildcards (e.g. "https://*.example.com") and are still checked in order
+type OriginSet struct {
+	all       bool
+	exact     map[string]struct{}
+	wildcards []originWildcard
+}
+
+// originWildcard is an allowed origin split around its "*"
+type originWildcard struct {
+	prefix string
+	suffix string
+}
+
+// NewOriginSet builds a set from allowed origins, compared case-insensitively
+// An empty list or one containing "*" allows every origin
+func NewOriginSet(origins []string) *OriginSet {
+	set := &OriginSet{exact: make(map[string]struct{}, len(origins))}
+	if len(origins) == 0 {
+		set.all = true
+		return set
+	}
+
+	for _, origin := range origins {
+		origin = strings.ToLower(origin)
+		if origin == "*" {
+			set.all = true
+			continue
+		}
+		if i := strings.IndexByte(origin, '*'); i >= 0 {
+			set.wildcards = append(set.wildcards, originWildcard{prefix: origin[:i], suffix: origin[i+1:]})
+			continue
+		}
+		set.exact[origin] = struct{}{}
+	}
+	return set
+}
+
+// AllowsAll reports whether every origin is allowed
+func (s *OriginSet) AllowsAll() bool {
+	return s.all
+}
+
+// Allowed reports whether origin is in the set
+func (s *OriginSet) Allowed(origin string) bool {
+	if s.all {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if _, ok := s.exact[origin]; ok {
+		return true
+	}
+	for _, w := range s.wildcards {
+		if len(origin) >= len(w.prefix)+len(w.suffix) && strings.HasPrefix(origin, w.prefix) && strings.HasSuffix(origin, w.suffix) {
+			return true
+		}
+	}
+	return false
+}
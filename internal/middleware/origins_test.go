@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"testing"
+)
+
+func largeOriginList(n int) []string {
+	origins := make([]string, n)
+	for i := range origins {
+		origins[i] = fmt.Sprintf("https://app%d.example.com", i)
+	}
+	return origins
+}
+
+func TestOriginSet(t *testing.T) {
+	set := NewOriginSet(append(largeOriginList(1000), "https://*.partner.io"))
+
+	tests := []struct {
+		origin   string
+		expected bool
+	}{
+		{"https://app0.example.com", true},
+		{"https://app999.example.com", true},
+		{"HTTPS://APP500.EXAMPLE.COM", true},
+		{"https://app1000.example.com", false},
+		{"http://app1.example.com", false},
+		{"https://eu.partner.io", true},
+		{"https://partner.io", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := set.Allowed(tt.origin); got != tt.expected {
+			t.Errorf("Allowed(%q) = %v, expected %v", tt.origin, got, tt.expected)
+		}
+	}
+}
+
+func TestOriginSetAllowsAll(t *testing.T) {
+	for _, origins := range [][]string{nil, {"https://a.example.com", "*"}} {
+		set := NewOriginSet(origins)
+		if !set.AllowsAll() || !set.Allowed("https://anything.example.org") {
+			t.Errorf("Expected %v to allow every origin", origins)
+		}
+	}
+}
+
+// linearOriginMatch mirrors a per-request scan of the allowed origin list
+func linearOriginMatch(origins []string, origin string) bool {
+	for _, o := range origins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func BenchmarkOriginMatchLinear(b *testing.B) {
+	origins := largeOriginList(500)
+	origin := origins[len(origins)-1]
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		linearOriginMatch(origins, origin)
+	}
+}
+
+func BenchmarkOriginMatchSet(b *testing.B) {
+	origins := largeOriginList(500)
+	set := NewOriginSet(origins)
+	origin := origins[len(origins)-1]
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Allowed(origin)
+	}
+}
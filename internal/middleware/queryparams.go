@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MaxQueryParams creates a middleware that rejects requests with more than max
+// query parameters with 400, before the query is parsed into a map. Repeated keys
+// count once per occurrence and empty segments such as "a=1&&b=2" are not counted.
+// A max of 0 or less allows any number
+func MaxQueryParams(max int) Middleware {
+	if max <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if countQueryParams(r.URL.RawQuery, max) > max {
+				writeJSONError(w, http.StatusBadRequest, "Too many query parameters")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// countQueryParams counts the non-empty &-separated segments of query
+// It stops once the count exceeds max so oversized queries are not fully scanned
+func countQueryParams(query string, max int) int {
+	count := 0
+	for query != "" && count <= max {
+		var segment string
+		segment, query, _ = strings.Cut(query, "&")
+		if segment != "" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxQueryParams(t *testing.T) {
+	handler := MaxQueryParams(3)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{"no query", "", http.StatusOK},
+		{"at limit", "a=1&b=2&c=3", http.StatusOK},
+		{"empty segments ignored", "a=1&&b=2&c=3&", http.StatusOK},
+		{"over limit", "a=1&b=2&c=3&d=4", http.StatusBadRequest},
+		{"repeated keys counted", "a=1&a=2&a=3&a=4", http.StatusBadRequest},
+		{"far over limit", strings.Repeat("x=1&", 10000), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/search", nil)
+			req.URL.RawQuery = tt.query
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestMaxQueryParamsDisabled(t *testing.T) {
+	handler := MaxQueryParams(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/search", nil)
+	req.URL.RawQuery = strings.Repeat("x=1&", 1000)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d with no limit, got %d", http.StatusOK, rr.Code)
+	}
+}
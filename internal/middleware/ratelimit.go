@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimitFunc returns the current rate limit: the number of requests allowed
+// per client within each window. A limit of zero or less disables rate limiting
+type RateLimitFunc func() (limit int, window time.Duration)
+
+// maxRateLimitBuckets is the bucket count above which expired buckets are swept
+const maxRateLimitBuckets = 10000
+
+// rateLimitBucket counts requests from one client within a fixed window
+type rateLimitBucket struct {
+	start  time.Time
+	count  int
+	limit  int
+	window time.Duration
+}
+
+// RateLimit creates a middleware that limits requests per client IP using fixed windows
+// Limits are read from the limits function on every request, so a runtime config
+// reload takes effect without a restart. A bucket created under an old limit is
+// reset the next time its client makes a request, so new limits apply to every
+// client immediately rather than once their current window expires
+// Requests over the limit receive a 429 Too Many Requests with a Retry-After header
+func RateLimit(limits RateLimitFunc) Middleware {
+	limiter := newFixedWindowLimiter()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit, window := limits()
+			if limit <= 0 || window <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowed, retryAfter := limiter.allow(clientIP(r), limit, window); !allowed {
+				SetRetryAfter(w, retryAfter)
+				writeJSONError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// fixedWindowLimiter counts requests per key in fixed windows
+type fixedWindowLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*rateLimitBucket
+}
+
+// newFixedWindowLimiter creates an empty limiter
+func newFixedWindowLimiter() *fixedWindowLimiter {
+	return &fixedWindowLimiter{buckets: make(map[string]*rateLimitBucket)}
+}
+
+// allow counts a request for key and reports whether it is within limit, along
+// with how long until the key's current window ends
+func (l *fixedWindowLimiter) allow(key string, limit int, window time.Duration) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket := l.buckets[key]
+	if bucket == nil || bucket.limit != limit || bucket.window != window || now.Sub(bucket.start) >= window {
+		if len(l.buckets) >= maxRateLimitBuckets {
+			sweepRateLimitBuckets(l.buckets, now)
+		}
+		bucket = &rateLimitBucket{start: now, limit: limit, window: window}
+		l.buckets[key] = bucket
+	}
+	bucket.count++
+
+	return bucket.count <= limit, bucket.start.Add(window).Sub(now)
+}
+
+// sweepRateLimitBuckets removes buckets whose window has already expired
+func sweepRateLimitBuckets(buckets map[string]*rateLimitBucket, now time.Time) {
+	for key, bucket := range buckets {
+		if now.Sub(bucket.start) >= bucket.window {
+			delete(buckets, key)
+		}
+	}
+}
+
+// clientIP returns the IP address of the client that sent the request
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	var limit atomic.Int64
+	limit.Store(2)
+	limits := func() (int, time.Duration) {
+		return int(limit.Load()), time.Minute
+	}
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	handler := RateLimit(limits)(testHandler)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("limits at the configured rate", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if w := send(); w.Code != http.StatusOK {
+				t.Fatalf("Request %d: expected status %d, got %d", i+1, http.StatusOK, w.Code)
+			}
+		}
+
+		w := send()
+		if w.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Error("Expected Retry-After header on limited response")
+		}
+		if _, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil {
+			t.Errorf("Expected Retry-After in delay-seconds, got %q", w.Header().Get("Retry-After"))
+		}
+	})
+
+	t.Run("runtime change applies the new rate", func(t *testing.T) {
+		limit.Store(4)
+
+		for i := 0; i < 4; i++ {
+			if w := send(); w.Code != http.StatusOK {
+				t.Fatalf("Request %d: expected status %d, got %d", i+1, http.StatusOK, w.Code)
+			}
+		}
+
+		if w := send(); w.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+		}
+	})
+
+	t.Run("zero limit disables rate limiting", func(t *testing.T) {
+		limit.Store(0)
+
+		for i := 0; i < 10; i++ {
+			if w := send(); w.Code != http.StatusOK {
+				t.Fatalf("Request %d: expected status %d, got %d", i+1, http.StatusOK, w.Code)
+			}
+		}
+	})
+}
+
+func TestRateLimitPerClient(t *testing.T) {
+	limits := func() (int, time.Duration) {
+		return 1, time.Minute
+	}
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	handler := RateLimit(limits)(testHandler)
+
+	for _, addr := range []string{"192.0.2.1:1234", "192.0.2.2:1234"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusOK, addr, w.Code)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// ReadOnly creates a middleware that rejects writes with 503 while enabled reports true
+// POST, PUT, PATCH and DELETE get a maintenance message and Retry-After, while
+// GET, HEAD, OPTIONS and other methods still pass. enabled is checked on every
+// request so read-only mode can be toggled at runtime. Paths in exempt accept
+// writes regardless, so an admin endpoint can still switch the mode off
+func ReadOnly(enabled func() bool, retryAfter time.Duration, exempt ...string) Middleware {
+	exempted := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		exempted[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isWriteMethod(r.Method) && !exempted[r.URL.Path] && enabled() {
+				SetRetryAfter(w, retryAfter)
+				writeJSONError(w, http.StatusServiceUnavailable, "The server is in read-only mode for maintenance")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isWriteMethod reports whether method modifies state and is rejected in read-only mode
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
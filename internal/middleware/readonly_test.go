@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadOnly(t *testing.T) {
+	var enabled atomic.Bool
+	enabled.Store(true)
+
+	handler := ReadOnly(enabled.Load, 5*time.Second, "/admin/readonly")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		method         string
+		expectedStatus int
+	}{
+		{http.MethodGet, http.StatusOK},
+		{http.MethodHead, http.StatusOK},
+		{http.MethodOptions, http.StatusOK},
+		{http.MethodPost, http.StatusServiceUnavailable},
+		{http.MethodPut, http.StatusServiceUnavailable},
+		{http.MethodPatch, http.StatusServiceUnavailable},
+		{http.MethodDelete, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(tt.method, "/", nil))
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if tt.expectedStatus == http.StatusServiceUnavailable && rr.Header().Get("Retry-After") != "5" {
+				t.Errorf("expected Retry-After 5, got %q", rr.Header().Get("Retry-After"))
+			}
+		})
+	}
+
+	// Exempt paths accept writes while read-only
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/readonly", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected exempt path to accept writes, got %d", rr.Code)
+	}
+
+	// Turning read-only mode off lets writes through again
+	enabled.Store(false)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected writes to pass once read-only mode is off, got %d", rr.Code)
+	}
+}
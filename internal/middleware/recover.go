@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover creates a middleware that converts handler panics into a 500 response
+// The panic value and stack trace are logged. http.ErrAbortHandler is re-panicked so
+// the server can abort the response as intended
+func Recover() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if err := recover(); err != nil {
+					if err == http.ErrAbortHandler {
+						panic(err)
+					}
+					log.Printf("Recovered from panic serving %s %s: %v\n%s", r.Method, r.URL.Path, err, debug.Stack())
+					writeJSONError(w, http.StatusInternalServerError, "Internal server error")
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var contextID string
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contextID = RequestIDFromContext(r.Context())
+	})
+	handler := RequestID()(testHandler)
+
+	t.Run("generates an ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		id := w.Header().Get(RequestIDHeader)
+		if len(id) != 32 {
+			t.Errorf("Expected a 32 character ID, got %q", id)
+		}
+		if contextID != id {
+			t.Errorf("Expected context ID %q to match header, got %q", id, contextID)
+		}
+	})
+
+	t.Run("reuses client ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(RequestIDHeader, "client-id")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if id := w.Header().Get(RequestIDHeader); id != "client-id" {
+			t.Errorf("Expected client-id, got %q", id)
+		}
+		if contextID != "client-id" {
+			t.Errorf("Expected context ID client-id, got %q", contextID)
+		}
+	})
+	for _, id := range []string{strings.Repeat("a", maxRequestIDLength+1), "forged\nGET /admin", "has space"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(RequestIDHeader, id)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if got := w.Header().Get(RequestIDHeader); got == id || len(got) != 32 {
+			t.Errorf("Expected invalid ID %q to be replaced with a generated one, got %q", id, got)
+		}
+	}
+}
+
+func TestLoggerDropsInvalidRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	handler := Logger(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "forged\nGET /admin")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "forged") {
+		t.Errorf("Expected an invalid request ID to be left out of the log, got: %s", buf.String())
+	}
+}
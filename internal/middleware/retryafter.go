@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryAfter is the Retry-After used when shedding load without a better estimate
+const DefaultRetryAfter = time.Second
+
+// SetRetryAfter sets the Retry-After header to d as delay-seconds
+// Every 429 and 503 response uses it so clients always see the same format.
+// Partial seconds are rounded up so clients never retry before d has passed,
+// and the value is at least one second
+func SetRetryAfter(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Retry-After", RetryAfterValue(d))
+}
+
+// RetryAfterValue formats d as the delay-seconds value SetRetryAfter sends
+// It is for headers set outside a ResponseWriter, such as proxied responses
+func RetryAfterValue(d time.Duration) string {
+	seconds := int64((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetRetryAfter(t *testing.T) {
+	tests := []struct {
+		delay    time.Duration
+		expected string
+	}{
+		{0, "1"},
+		{-time.Second, "1"},
+		{time.Millisecond, "1"},
+		{time.Second, "1"},
+		{1400 * time.Millisecond, "2"},
+		{30 * time.Second, "30"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		SetRetryAfter(w, tt.delay)
+
+		if got := w.Header().Get("Retry-After"); got != tt.expected {
+			t.Errorf("SetRetryAfter(%v): expected %q, got %q", tt.delay, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// rewriteRule rewrites paths matching a prefix or a regular expression
+type rewriteRule struct {
+	prefix      string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// RewritePath creates a middleware that rewrites request paths before routing, so old
+// paths keep working after routes are renamed. A rule whose key starts with "^" is a
+// regular expression and its value may refer to groups as $1; any other key is a path
+// prefix matched on whole segments, e.g. "/old/api" rewrites "/old/api/users" to
+// "/api/users". Longer prefixes are tried first, then expressions in key order, and
+// only the first matching rule applies. Invalid expressions are logged and skipped
+func RewritePath(rules map[string]string) Middleware {
+	compiled := compileRewriteRules(rules)
+
+	return func(next http.Handler) http.Handler {
+		if len(compiled) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, rule := range compiled {
+				if path, ok := rule.rewrite(r.URL.Path); ok {
+					r = r.Clone(r.Context())
+					r.URL.Path = path
+					r.URL.RawPath = ""
+					break
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// compileRewriteRules orders prefix rules longest first followed by expressions in key order
+func compileRewriteRules(rules map[string]string) []rewriteRule {
+	var prefixes, patterns []string
+	for from := range rules {
+		if strings.HasPrefix(from, "^") {
+			patterns = append(patterns, from)
+		} else {
+			prefixes = append(prefixes, from)
+		}
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) != len(prefixes[j]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	sort.Strings(patterns)
+
+	compiled := make([]rewriteRule, 0, len(rules))
+	for _, prefix := range prefixes {
+		compiled = append(compiled, rewriteRule{prefix: strings.TrimSuffix(prefix, "/"), replacement: strings.TrimSuffix(rules[prefix], "/")})
+	}
+	for _, expr := range patterns {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			log.Printf("Skipping invalid path rewrite %q: %v", expr, err)
+			continue
+		}
+		compiled = append(compiled, rewriteRule{pattern: pattern, replacement: rules[expr]})
+	}
+	return compiled
+}
+
+// rewrite returns the rewritten path and whether the rule matched
+func (rule rewriteRule) rewrite(path string) (string, bool) {
+	if rule.pattern != nil {
+		if !rule.pattern.MatchString(path) {
+			return "", false
+		}
+		return rule.pattern.ReplaceAllString(path, rule.replacement), true
+	}
+
+	rest, found := strings.CutPrefix(path, rule.prefix)
+	if !found || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return "", false
+	}
+	if rule.replacement == "" && rest == "" {
+		return "/", true
+	}
+	return rule.replacement + rest, true
+}
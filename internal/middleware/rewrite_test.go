@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRewritePath(t *testing.T) {
+	rules := map[string]string{
+		"/old/api":            "/api",
+		"/old/api/legacy":     "/legacy",
+		"/v0/":                "/",
+		`^/users/(\d+)/info$`: "/api/users/$1",
+		`^/broken(`:           "/never",
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/old/api", "/api"},
+		{"/old/api/users", "/api/users"},
+		{"/old/api/legacy/items", "/legacy/items"},
+		{"/old/apiary", "/old/apiary"},
+		{"/v0", "/"},
+		{"/v0/health", "/health"},
+		{"/users/42/info", "/api/users/42"},
+		{"/users/abc/info", "/users/abc/info"},
+		{"/api/users", "/api/users"},
+	}
+
+	var got string
+	handler := RewritePath(rules)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path+"?q=1", nil))
+			if got != tt.expected {
+				t.Errorf("Expected path %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
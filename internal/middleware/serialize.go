@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+)
+
+// clientQueue orders the requests of one client, each waiting for the one before it
+type clientQueue struct {
+	tail    chan struct{} // Closed when the most recently queued request finishes
+	pending int
+}
+
+// SerializeClients creates a middleware that processes each client's requests one at a
+// time in arrival order. Clients are identified by the API key TierRateLimit validated,
+// or by IP address otherwise, so an unchecked X-API-Key header cannot be varied to
+// escape the queue. Requests from different clients still run concurrently
+// A request whose client goes away while it waits is dropped without a response
+func SerializeClients() Middleware {
+	var mu sync.Mutex
+	queues := make(map[string]*clientQueue)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := "ip:" + clientIP(r)
+			if key := validAPIKey(r.Context()); key != "" {
+				client = "key:" + key
+			}
+
+			// Take a place at the back of the client's queue
+			mu.Lock()
+			queue, exists := queues[client]
+			if !exists {
+				queue = &clientQueue{}
+				queues[client] = queue
+			}
+			prev, done := queue.tail, make(chan struct{})
+			queue.tail = done
+			queue.pending++
+			mu.Unlock()
+
+			// Hand over to the next request only once the previous one has finished,
+			// so a request that gives up early cannot let later ones overtake
+			finish := func() {
+				if prev != nil {
+					<-prev
+				}
+				close(done)
+
+				mu.Lock()
+				if queue.pending--; queue.pending == 0 {
+					delete(queues, client)
+				}
+				mu.Unlock()
+			}
+
+			if prev != nil {
+				select {
+				case <-prev:
+				case <-r.Context().Done():
+					go finish()
+					return
+				}
+			}
+			defer finish()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
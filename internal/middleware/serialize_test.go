@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSerializeClientsNoOverlap(t *testing.T) {
+	var active, maxActive atomic.Int32
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		for {
+			max := maxActive.Load()
+			if n <= max || maxActive.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		active.Add(-1)
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := TierRateLimit(map[string]string{"client-a": "free"}, nil, nil)(SerializeClients()(testHandler))
+
+	const requests = 10
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("POST", "/orders", nil)
+			req.Header.Set(APIKeyHeader, "client-a")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+	wg.Wait()
+
+	if max := maxActive.Load(); max != 1 {
+		t.Errorf("Expected one request at a time for a client, got %d overlapping", max)
+	}
+}
+
+func TestSerializeClientsArrivalOrder(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var mu sync.Mutex
+	var order []string
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		order = append(order, r.URL.Query().Get("n"))
+		mu.Unlock()
+		if r.URL.Query().Get("n") == "0" {
+			started <- struct{}{}
+			<-release
+		}
+	})
+	handler := SerializeClients()(testHandler)
+
+	var wg sync.WaitGroup
+	send := func(n string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/?n="+n, nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+
+	// Queue the later requests one after another while the first is still running
+	send("0")
+	<-started
+	for _, n := range []string{"1", "2", "3"} {
+		send(n)
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := len(order); got != 4 || order[0] != "0" || order[1] != "1" || order[2] != "2" || order[3] != "3" {
+		t.Errorf("Expected requests to run in arrival order, got %v", order)
+	}
+}
+
+func TestSerializeClientsIndependentClients(t *testing.T) {
+	release := make(chan struct{})
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(APIKeyHeader) == "slow" {
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	keys := map[string]string{"slow": "free", "fast": "free"}
+	handler := TierRateLimit(keys, nil, nil)(SerializeClients()(testHandler))
+
+	done := make(chan struct{})
+	go func() {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(APIKeyHeader, "slow")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		close(done)
+	}()
+
+	// Another client is not held up by the slow one
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(APIKeyHeader, "fast")
+	rec := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(rec, req)
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected another client's request to run concurrently")
+	}
+	close(release)
+	<-done
+}
+
+func TestSerializeClientsIgnoresUnvalidatedKeys(t *testing.T) {
+	var active, maxActive atomic.Int32
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		for {
+			max := maxActive.Load()
+			if n <= max || maxActive.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		active.Add(-1)
+	})
+	handler := SerializeClients()(testHandler)
+
+	// Without TierRateLimit the header is not trusted, so varying it does not split
+	// one IP's requests into separate queues
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest("POST", "/orders", nil)
+			req.Header.Set(APIKeyHeader, "forged-"+strconv.Itoa(i))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}(i)
+	}
+	wg.Wait()
+
+	if max := maxActive.Load(); max != 1 {
+		t.Errorf("Expected one request at a time for the IP, got %d overlapping", max)
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// ServedByHeader is the response header naming the instance that served a request
+const ServedByHeader = "X-Served-By"
+
+// instanceID is generated once so every request from this process reports the same ID
+var instanceID = sync.OnceValue(func() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "instance"
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hostname + "-" + hex.EncodeToString(b)
+})
+
+// InstanceID returns this process's generated instance ID, the hostname and a random suffix
+func InstanceID() string {
+	return instanceID()
+}
+
+// ServedBy creates a middleware that names the serving instance in the X-Served-By header
+// An empty id uses the generated InstanceID, which is stable for the life of the process
+func ServedBy(id string) Middleware {
+	if id == "" {
+		id = InstanceID()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(ServedByHeader, id)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestServedBy(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		handler := ServedBy("")(testHandler)
+
+		var ids []string
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			ids = append(ids, w.Header().Get(ServedByHeader))
+		}
+
+		if ids[0] == "" {
+			t.Fatal("Expected X-Served-By header to be set")
+		}
+		if ids[1] != ids[0] || ids[2] != ids[0] {
+			t.Errorf("Expected a stable instance ID, got %v", ids)
+		}
+		if hostname, err := os.Hostname(); err == nil && !strings.HasPrefix(ids[0], hostname+"-") {
+			t.Errorf("Expected the ID to start with the hostname %q, got %q", hostname, ids[0])
+		}
+
+		// Another middleware in the same process reports the same instance
+		w := httptest.NewRecorder()
+		ServedBy("")(testHandler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if got := w.Header().Get(ServedByHeader); got != ids[0] {
+			t.Errorf("Expected %q from a second middleware, got %q", ids[0], got)
+		}
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ServedBy("web-1")(testHandler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if got := w.Header().Get(ServedByHeader); got != "web-1" {
+			t.Errorf("Expected configured instance ID web-1, got %q", got)
+		}
+	})
+}
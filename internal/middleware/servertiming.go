@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerTimingHeader is the header carrying server-side timings to browser dev tools
+const ServerTimingHeader = "Server-Timing"
+
+// serverTimingKey is the context key under which request timings are stored
+type serverTimingKey struct{}
+
+// serverTimings collects the named durations recorded while serving a request
+type serverTimings struct {
+	mu      sync.Mutex
+	start   time.Time
+	entries []string
+}
+
+// RecordServerTiming adds a named phase duration to the request's Server-Timing header
+// It is a no-op unless the ServerTiming middleware is installed, and phases
+// recorded after the response headers are written are dropped
+func RecordServerTiming(r *http.Request, name string, d time.Duration) {
+	timings, ok := r.Context().Value(serverTimingKey{}).(*serverTimings)
+	if !ok {
+		return
+	}
+	timings.mu.Lock()
+	timings.entries = append(timings.entries, name+";dur="+formatTimingMillis(d))
+	timings.mu.Unlock()
+}
+
+// ServerTiming creates a middleware that sets the Server-Timing header
+// It always reports a "total" phase measured until the response headers are
+// written, along with any phases recorded through RecordServerTiming. When the
+// request is part of a sampled trace, the trace ID is included as a "trace"
+// entry, so "trace" must come before this middleware to be reported
+func ServerTiming() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timings := &serverTimings{start: time.Now()}
+			r = r.WithContext(context.WithValue(r.Context(), serverTimingKey{}, timings))
+
+			sw := &serverTimingWriter{ResponseWriter: w, r: r, timings: timings}
+			next.ServeHTTP(sw, r)
+
+			// Handlers that write nothing still get an implicit 200 with timings
+			if !sw.wroteHeader {
+				sw.WriteHeader(http.StatusOK)
+			}
+		})
+	}
+}
+
+// serverTimingWriter sets the Server-Timing header just before the headers are written
+type serverTimingWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	timings     *serverTimings
+	wroteHeader bool
+}
+
+func (sw *serverTimingWriter) WriteHeader(statusCode int) {
+	if !sw.wroteHeader {
+		sw.wroteHeader = true
+		sw.Header().Set(ServerTimingHeader, sw.headerValue())
+	}
+	sw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (sw *serverTimingWriter) Write(b []byte) (int, error) {
+	if !sw.wroteHeader {
+		sw.WriteHeader(http.StatusOK)
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer for Flush and Hijack
+func (sw *serverTimingWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// headerValue renders the recorded phases followed by the total duration
+func (sw *serverTimingWriter) headerValue() string {
+	sw.timings.mu.Lock()
+	entries := append([]string(nil), sw.timings.entries...)
+	sw.timings.mu.Unlock()
+
+	if tc, ok := TraceFromContext(sw.r.Context()); ok && tc.Sampled {
+		entries = append(entries, `trace;desc="`+tc.TraceID+`"`)
+	}
+	entries = append(entries, "total;dur="+formatTimingMillis(time.Since(sw.timings.start)))
+	return strings.Join(entries, ", ")
+}
+
+// formatTimingMillis formats d in milliseconds as Server-Timing expects
+func formatTimingMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
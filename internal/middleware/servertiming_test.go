@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serverTimingEntry matches one metric in a Server-Timing header
+var serverTimingEntry = regexp.MustCompile(`^[A-Za-z0-9_-]+(;dur=[0-9]+\.[0-9]{3}|;desc="[^"]*")*$`)
+
+func assertServerTiming(t *testing.T, header string) []string {
+	t.Helper()
+
+	if header == "" {
+		t.Fatal("Expected Server-Timing header to be set")
+	}
+	entries := strings.Split(header, ", ")
+	for _, entry := range entries {
+		if !serverTimingEntry.MatchString(entry) {
+			t.Errorf("Malformed Server-Timing entry %q in %q", entry, header)
+		}
+	}
+	if !strings.HasPrefix(entries[len(entries)-1], "total;dur=") {
+		t.Errorf("Expected total to be the last entry, got %q", header)
+	}
+	return entries
+}
+
+func TestServerTiming(t *testing.T) {
+	t.Run("total and recorded phases", func(t *testing.T) {
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			RecordServerTiming(r, "db", 12*time.Millisecond)
+			w.Write([]byte("OK"))
+		})
+
+		w := httptest.NewRecorder()
+		ServerTiming()(testHandler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		entries := assertServerTiming(t, w.Header().Get(ServerTimingHeader))
+		if entries[0] != "db;dur=12.000" {
+			t.Errorf("Expected db phase first, got %q", entries[0])
+		}
+	})
+
+	t.Run("empty response", func(t *testing.T) {
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+		w := httptest.NewRecorder()
+		ServerTiming()(testHandler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		assertServerTiming(t, w.Header().Get(ServerTimingHeader))
+	})
+
+	t.Run("sampled trace", func(t *testing.T) {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		w := httptest.NewRecorder()
+		Chain(Trace(1), ServerTiming())(testHandler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		entries := assertServerTiming(t, w.Header().Get(ServerTimingHeader))
+		if !strings.HasPrefix(entries[0], `trace;desc="`) {
+			t.Errorf("Expected trace entry for sampled request, got %q", entries)
+		}
+	})
+
+	t.Run("record without middleware is a no-op", func(t *testing.T) {
+		RecordServerTiming(httptest.NewRequest("GET", "/", nil), "db", time.Millisecond)
+	})
+}
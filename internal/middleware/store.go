@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// requestStoreKey is the context key under which the request store is stored
+type requestStoreKey struct{}
+
+// requestStore holds values shared along the middleware chain for one request
+type requestStore struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// RequestStore creates a middleware that seeds a request-scoped key/value store
+// Later middleware and handlers share data through Set and Get without each
+// defining its own context key. It should run before anything that uses them
+func RequestStore() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Value(requestStoreKey{}).(*requestStore); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			store := &requestStore{values: make(map[string]interface{})}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestStoreKey{}, store)))
+		})
+	}
+}
+
+// Set stores val under key for the rest of the request
+// It reports false, storing nothing, when RequestStore is not installed
+func Set(r *http.Request, key string, val interface{}) bool {
+	store, ok := r.Context().Value(requestStoreKey{}).(*requestStore)
+	if !ok {
+		return false
+	}
+	store.mu.Lock()
+	store.values[key] = val
+	store.mu.Unlock()
+	return true
+}
+
+// Get returns the value stored under key and whether it was set
+func Get(r *http.Request, key string) (interface{}, bool) {
+	store, ok := r.Context().Value(requestStoreKey{}).(*requestStore)
+	if !ok {
+		return nil, false
+	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	val, ok := store.values[key]
+	return val, ok
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestStore(t *testing.T) {
+	setSubject := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !Set(r, "subject", "user-42") {
+				t.Error("Expected Set to succeed with the store installed")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	// A middleware that derives a new request must not hide earlier values
+	withContext := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(r.Context()))
+		})
+	}
+
+	var got interface{}
+	var found bool
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, found = Get(r, "subject")
+	})
+
+	handler := Chain(RequestStore(), setSubject, withContext)(testHandler)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if !found || got != "user-42" {
+		t.Errorf("Expected handler to read subject user-42, got %v (found %v)", got, found)
+	}
+}
+
+func TestRequestStoreMissing(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+
+	if Set(req, "key", "value") {
+		t.Error("Expected Set to report false without the store")
+	}
+	if _, ok := Get(req, "key"); ok {
+		t.Error("Expected Get to find nothing without the store")
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// StripHeaders creates a middleware that removes the named headers from responses
+// The headers are deleted just before they are written, or once the handler returns
+// if it never wrote, so values set by handlers or by middleware further down the
+// chain, such as X-Powered-By, never leak
+func StripHeaders(names []string) Middleware {
+	return func(next http.Handler) http.Handler {
+		if len(names) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &stripHeadersWriter{ResponseWriter: w, names: names}
+			next.ServeHTTP(sw, r)
+			// net/http sends an implicit 200 for handlers that never wrote
+			if !sw.wroteHeader {
+				sw.strip()
+			}
+		})
+	}
+}
+
+// stripHeadersWriter deletes the configured headers before the headers are written
+type stripHeadersWriter struct {
+	http.ResponseWriter
+	names       []string
+	wroteHeader bool
+}
+
+// strip deletes the configured headers from the response
+func (sw *stripHeadersWriter) strip() {
+	for _, name := range sw.names {
+		sw.Header().Del(name)
+	}
+}
+
+func (sw *stripHeadersWriter) WriteHeader(statusCode int) {
+	if !sw.wroteHeader {
+		sw.wroteHeader = true
+		sw.strip()
+	}
+	sw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (sw *stripHeadersWriter) Write(b []byte) (int, error) {
+	if !sw.wroteHeader {
+		sw.WriteHeader(http.StatusOK)
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// Flush strips the headers before a flush can send them
+func (sw *stripHeadersWriter) Flush() {
+	if !sw.wroteHeader {
+		sw.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(sw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer for Hijack
+func (sw *stripHeadersWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
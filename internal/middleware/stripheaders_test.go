@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStripHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		writeHeader bool
+	}{
+		{"explicit WriteHeader", true},
+		{"implicit WriteHeader", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := StripHeaders([]string{"X-Powered-By", "server"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Powered-By", "Go")
+				w.Header().Set("Server", "phantom/1.2.3 (linux)")
+				w.Header().Set("Content-Type", "text/plain")
+				if tt.writeHeader {
+					w.WriteHeader(http.StatusCreated)
+				}
+				w.Write([]byte("OK"))
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			for _, name := range []string{"X-Powered-By", "Server"} {
+				if value := w.Header().Get(name); value != "" {
+					t.Errorf("Expected %s to be stripped, got %q", name, value)
+				}
+			}
+			if w.Header().Get("Content-Type") != "text/plain" {
+				t.Errorf("Expected other headers to be kept, got %v", w.Header())
+			}
+			if w.Body.String() != "OK" {
+				t.Errorf("Expected body %q, got %q", "OK", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestStripHeadersWithoutBody(t *testing.T) {
+	handler := StripHeaders([]string{"X-Powered-By"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Powered-By", "PHP")
+		w.Header().Set("X-Request-Kind", "empty")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if value := w.Header().Get("X-Powered-By"); value != "" {
+		t.Errorf("Expected X-Powered-By to be stripped, got %q", value)
+	}
+	if w.Header().Get("X-Request-Kind") != "empty" {
+		t.Errorf("Expected other headers to be kept, got %v", w.Header())
+	}
+}
+
+func TestStripHeadersEmptyList(t *testing.T) {
+	handler := StripHeaders(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Powered-By", "Go")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Header().Get("X-Powered-By") != "Go" {
+		t.Error("Expected headers to be kept when no headers are configured")
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+)
+
+// APIKeyHeader is the header clients send their API key in
+const APIKeyHeader = "X-API-Key"
+
+// TierLimit is the number of requests allowed per window for an API key tier
+type TierLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// apiKeyTierKey is the context key under which the tier of a valid API key is stored
+type apiKeyTierKey struct{}
+
+// apiKeyKey is the context key under which a valid API key itself is stored
+type apiKeyKey struct{}
+
+// APIKeyTierFromContext returns the tier of the request's API key, or "" when the
+// request had no valid key
+func APIKeyTierFromContext(ctx context.Context) string {
+	tier, _ := ctx.Value(apiKeyTierKey{}).(string)
+	return tier
+}
+
+// validAPIKey returns the API key TierRateLimit validated for the request, or ""
+// Unlike the raw header, it cannot be an arbitrary value chosen by the client
+func validAPIKey(ctx context.Context) string {
+	key, _ := ctx.Value(apiKeyKey{}).(string)
+	return key
+}
+
+// TierRateLimit creates a middleware that rate limits requests by API key
+// keys maps each API key to its tier and tiers maps each tier to its limit.
+// Buckets are keyed by API key, so keys on the same tier are limited
+// independently, and a tier can allow more than the per-IP limit. Every other
+// request goes through fallback, normally RateLimit, instead: requests without a
+// key, keys on a tier without a positive limit, and unknown keys, which are then
+// rejected with 401 so keys cannot be guessed faster than the per-IP limit.
+// Keys are compared in constant time. A nil fallback passes those requests through
+func TierRateLimit(keys map[string]string, tiers map[string]TierLimit, fallback Middleware) Middleware {
+	limiter := newFixedWindowLimiter()
+	if fallback == nil {
+		fallback = func(next http.Handler) http.Handler { return next }
+	}
+
+	return func(next http.Handler) http.Handler {
+		perIP := fallback(next)
+		rejectKey := fallback(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSONError(w, http.StatusUnauthorized, "Invalid API key")
+		}))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				perIP.ServeHTTP(w, r)
+				return
+			}
+
+			tier, known := lookupAPIKey(keys, key)
+			if !known {
+				rejectKey.ServeHTTP(w, r)
+				return
+			}
+			ctx := context.WithValue(r.Context(), apiKeyTierKey{}, tier)
+			r = r.WithContext(context.WithValue(ctx, apiKeyKey{}, key))
+
+			limit := tiers[tier]
+			if limit.Requests <= 0 || limit.Window <= 0 {
+				perIP.ServeHTTP(w, r)
+				return
+			}
+
+			if allowed, retryAfter := limiter.allow(key, limit.Requests, limit.Window); !allowed {
+				SetRetryAfter(w, retryAfter)
+				writeJSONError(w, http.StatusTooManyRequests, "Rate limit exceeded for tier "+tier)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// lookupAPIKey returns the tier of key, comparing it with every configured key in
+// constant time so response timing does not reveal how much of a guess matched
+func lookupAPIKey(keys map[string]string, key string) (string, bool) {
+	var tier string
+	known := false
+	for candidate, candidateTier := range keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			tier, known = candidateTier, true
+		}
+	}
+	return tier, known
+}
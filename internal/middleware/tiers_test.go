@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTierRateLimit(t *testing.T) {
+	keys := map[string]string{
+		"free-key": "free",
+		"pro-key":  "pro",
+		"pro-key2": "pro",
+	}
+	tiers := map[string]TierLimit{
+		"free": {Requests: 2, Window: time.Minute},
+		"pro":  {Requests: 5, Window: time.Minute},
+	}
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	handler := TierRateLimit(keys, tiers, nil)(testHandler)
+
+	send := func(key string) int {
+		req := httptest.NewRequest("GET", "/api", nil)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Count how many requests each key gets through before being limited
+	allowed := func(key string) int {
+		for i := 0; i < 10; i++ {
+			if code := send(key); code != http.StatusOK {
+				if code != http.StatusTooManyRequests {
+					t.Fatalf("Expected status %d for %s, got %d", http.StatusTooManyRequests, key, code)
+				}
+				return i
+			}
+		}
+		return 10
+	}
+
+	if n := allowed("free-key"); n != 2 {
+		t.Errorf("Expected free tier key to be allowed 2 requests, got %d", n)
+	}
+	if n := allowed("pro-key"); n != 5 {
+		t.Errorf("Expected pro tier key to be allowed 5 requests, got %d", n)
+	}
+	if n := allowed("pro-key2"); n != 5 {
+		t.Errorf("Expected second pro key to have its own bucket, got %d", n)
+	}
+
+	if code := send("unknown"); code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for unknown key, got %d", http.StatusUnauthorized, code)
+	}
+	if code := send(""); code != http.StatusOK {
+		t.Errorf("Expected requests without a key to pass, got %d", code)
+	}
+}
+
+func TestTierRateLimitFallback(t *testing.T) {
+	keys := map[string]string{"pro-key": "pro", "basic-key": "basic"}
+	tiers := map[string]TierLimit{"pro": {Requests: 3, Window: time.Minute}}
+	perIP := RateLimit(func() (int, time.Duration) { return 1, time.Minute })
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	handler := TierRateLimit(keys, tiers, perIP)(testHandler)
+
+	send := func(key, ip string) int {
+		req := httptest.NewRequest("GET", "/api", nil)
+		req.RemoteAddr = ip + ":1234"
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Unknown keys are limited per IP before being rejected
+	if code := send("guess-1", "10.0.0.1"); code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for the first unknown key, got %d", http.StatusUnauthorized, code)
+	}
+	if code := send("guess-2", "10.0.0.1"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected guessing to be rate limited per IP, got %d", code)
+	}
+
+	// A valid key whose tier has no limit falls back to the per-IP limit
+	if code := send("basic-key", "10.0.0.2"); code != http.StatusOK {
+		t.Errorf("Expected first request on an unlimited tier to pass, got %d", code)
+	}
+	if code := send("basic-key", "10.0.0.2"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the per-IP limit on an unlimited tier, got %d", code)
+	}
+
+	// A limited tier replaces the per-IP limit
+	for i := 0; i < 3; i++ {
+		if code := send("pro-key", "10.0.0.3"); code != http.StatusOK {
+			t.Fatalf("Expected pro request %d to pass, got %d", i+1, code)
+		}
+	}
+}
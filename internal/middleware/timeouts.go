@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ResponseTimeouts creates a middleware that times producing a response separately
+// from sending it. The handler runs under a context deadline of handlerTimeout, and
+// one that gives up at it without writing anything receives a 503 Service Unavailable.
+// writeTimeout then bounds writing the response, starting at its first write, so
+// time spent before that does not count against it. The deadline is cleared when
+// each request starts, so one left over from an earlier response on a keep-alive
+// connection never applies. The response is not buffered, so streaming and upgrades work
+// A handlerTimeout of zero or less disables both, leaving the server's WriteTimeout
+// to cover the whole response
+func ResponseTimeouts(handlerTimeout, writeTimeout time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		if handlerTimeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if writeTimeout > 0 {
+				dw := &writeDeadlineWriter{ResponseWriter: w, timeout: writeTimeout}
+				dw.reset()
+				defer dw.finish()
+				w = dw
+			}
+			serveWithDeadline(w, r, next, handlerTimeout, "Request exceeded the handler timeout")
+		})
+	}
+}
+
+// writeDeadlineWriter sets the connection's write deadline when the response starts
+// Writers that cannot set a deadline, such as test recorders, are left without one
+type writeDeadlineWriter struct {
+	http.ResponseWriter
+	timeout  time.Duration
+	started  bool
+	hijacked bool
+}
+
+// reset clears any write deadline left on the connection by a previous response
+func (dw *writeDeadlineWriter) reset() {
+	http.NewResponseController(dw.ResponseWriter).SetWriteDeadline(time.Time{})
+}
+
+// start sets the write deadline the first time the response is written
+func (dw *writeDeadlineWriter) start() {
+	if dw.started {
+		return
+	}
+	dw.started = true
+	http.NewResponseController(dw.ResponseWriter).SetWriteDeadline(time.Now().Add(dw.timeout))
+}
+
+// finish bounds the final flush of a response the handler never wrote to
+// A hijacked connection belongs to the handler and is left alone
+func (dw *writeDeadlineWriter) finish() {
+	if !dw.hijacked {
+		dw.start()
+	}
+}
+
+func (dw *writeDeadlineWriter) WriteHeader(statusCode int) {
+	dw.start()
+	dw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (dw *writeDeadlineWriter) Write(b []byte) (int, error) {
+	dw.start()
+	return dw.ResponseWriter.Write(b)
+}
+
+func (dw *writeDeadlineWriter) Flush() {
+	dw.start()
+	http.NewResponseController(dw.ResponseWriter).Flush()
+}
+
+// SetWriteDeadline lets a handler such as a stream replace the deadline
+// Once set explicitly it is not overridden when the response starts
+func (dw *writeDeadlineWriter) SetWriteDeadline(deadline time.Time) error {
+	dw.started = true
+	return http.NewResponseController(dw.ResponseWriter).SetWriteDeadline(deadline)
+}
+
+// Hijack takes over the connection, after which no deadline is set on it
+func (dw *writeDeadlineWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(dw.ResponseWriter).Hijack()
+	if err == nil {
+		dw.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (dw *writeDeadlineWriter) Unwrap() http.ResponseWriter {
+	return dw.ResponseWriter
+}
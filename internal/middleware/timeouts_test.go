@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseTimeoutsSlowHandler(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+			w.Write([]byte("too late"))
+		case <-r.Context().Done():
+		}
+	})
+	handler := ResponseTimeouts(50*time.Millisecond, time.Second)(testHandler)
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "handler timeout") {
+		t.Errorf("Expected handler timeout message, got %q", rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the handler timeout to end the request early, took %v", elapsed)
+	}
+}
+
+func TestResponseTimeoutsHandlerTimeNotCountedAsWrite(t *testing.T) {
+	// The handler takes longer than the write timeout but well within the handler timeout
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+	server := httptest.NewServer(ResponseTimeouts(time.Second, 50*time.Millisecond)(testHandler))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the response to be delivered, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK || string(body) != "done" {
+		t.Errorf("Expected 200 with body, got %d %q (%v)", resp.StatusCode, body, err)
+	}
+}
+
+func TestResponseTimeoutsSlowClient(t *testing.T) {
+	// The handler is fast but the response is far larger than the socket buffers
+	payload := strings.Repeat("x", 32<<20)
+	handlerErr := make(chan error, 1)
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(payload))
+		handlerErr <- r.Context().Err()
+	})
+	server := httptest.NewServer(ResponseTimeouts(time.Second, 100*time.Millisecond)(testHandler))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")); err != nil {
+		t.Fatalf("Failed to write request: %v", err)
+	}
+
+	// The write timeout, not the handler timeout, ends a response the client is not reading
+	select {
+	case err := <-handlerErr:
+		if errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the handler to finish before its deadline, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the handler to finish")
+	}
+
+	// Reading only after the write timeout gets a truncated response
+	time.Sleep(500 * time.Millisecond)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err == nil && len(body) == len(payload) {
+		t.Error("Expected the write timeout to cut off the response to a slow client")
+	}
+}
+
+func TestResponseTimeoutsKeepAlive(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	})
+	server := httptest.NewServer(ResponseTimeouts(time.Second, 50*time.Millisecond)(testHandler))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Failed to read first response: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	// Outlive the first response's deadline, then ask the server to write 100 Continue
+	// before the handler writes anything
+	time.Sleep(150 * time.Millisecond)
+	conn.Write([]byte("POST / HTTP/1.1\r\nHost: example.com\r\nContent-Length: 4\r\nExpect: 100-continue\r\n\r\n"))
+	resp, err = http.ReadResponse(reader, nil)
+	if err != nil || resp.StatusCode != http.StatusContinue {
+		t.Fatalf("Expected 100 Continue on the reused connection, got %v (%v)", resp, err)
+	}
+	conn.Write([]byte("body"))
+	resp, err = http.ReadResponse(reader, nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 on the reused connection, got %v (%v)", resp, err)
+	}
+	resp.Body.Close()
+}
+
+func TestResponseTimeoutsStreaming(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/upgrade" {
+			conn, rw, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				t.Errorf("Expected hijack to be supported, got %v", err)
+				return
+			}
+			go func() {
+				defer conn.Close()
+				// The hijacked connection outlives the write timeout
+				time.Sleep(150 * time.Millisecond)
+				rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nupgraded")
+				rw.Flush()
+			}()
+			return
+		}
+		w.Write([]byte("chunk"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Expected flush to be supported, got %v", err)
+		}
+	})
+	server := httptest.NewServer(ResponseTimeouts(time.Second, 50*time.Millisecond)(testHandler))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/upgrade")
+	if err != nil {
+		t.Fatalf("Expected the hijacked connection to be written without a deadline, got %v", err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "upgraded" {
+		t.Errorf("Expected body written after hijacking, got %q", body)
+	}
+}
+
+func TestResponseTimeoutsDisabled(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := ResponseTimeouts(0, time.Second)(testHandler)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("Expected the handler to run unchanged, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TraceparentHeader is the W3C Trace Context header
+const TraceparentHeader = "traceparent"
+
+// TraceContext identifies the trace and span a request belongs to
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+// traceContextKey is the context key under which the TraceContext is stored
+type traceContextKey struct{}
+
+// TraceFromContext returns the TraceContext stored by Trace, if any
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+// Trace creates a middleware that applies head-based trace sampling
+// A valid inbound traceparent decides sampling for the request, so upstream sampled
+// traces are always continued. Otherwise a fraction sampleRate (0 to 1) of requests
+// are sampled. Sampled requests record a span, logged with its duration
+func Trace(sampleRate float64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tc := TraceContext{SpanID: randomHex(8)}
+			if parent, ok := parseTraceparent(r.Header.Get(TraceparentHeader)); ok {
+				tc.TraceID = parent.TraceID
+				tc.Sampled = parent.Sampled
+			} else {
+				tc.TraceID = randomHex(16)
+				tc.Sampled = mathrand.Float64() < sampleRate
+			}
+
+			flags := "00"
+			if tc.Sampled {
+				flags = "01"
+			}
+			w.Header().Set(TraceparentHeader, "00-"+tc.TraceID+"-"+tc.SpanID+"-"+flags)
+
+			ctx := context.WithValue(r.Context(), traceContextKey{}, tc)
+			if !tc.Sampled {
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			start := time.Now()
+			next.ServeHTTP(w, r.WithContext(ctx))
+			log.Printf("span trace_id=%s span_id=%s %s %s duration=%s",
+				tc.TraceID, tc.SpanID, r.Method, r.URL.Path, time.Since(start))
+		})
+	}
+}
+
+// parseTraceparent parses a version 00 W3C traceparent header
+func parseTraceparent(header string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(parts[1]) || !isLowerHex(parts[2]) || !isLowerHex(parts[3]) {
+		return TraceContext{}, false
+	}
+	// All-zero trace and span IDs are invalid
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return TraceContext{}, false
+	}
+
+	flags, _ := hex.DecodeString(parts[3])
+	return TraceContext{
+		TraceID: parts[1],
+		SpanID:  parts[2],
+		Sampled: flags[0]&0x01 == 0x01,
+	}, true
+}
+
+// isLowerHex reports whether s only contains lowercase hex digits
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
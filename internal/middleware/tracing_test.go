@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestTraceSampling(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	var sampled int
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tc, ok := TraceFromContext(r.Context()); ok && tc.Sampled {
+			sampled++
+		}
+	})
+
+	t.Run("samples roughly the configured fraction", func(t *testing.T) {
+		sampled = 0
+		handler := Trace(0.25)(testHandler)
+
+		const requests = 10000
+		for i := 0; i < requests; i++ {
+			req := httptest.NewRequest("GET", "/test", nil)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		fraction := float64(sampled) / requests
+		if fraction < 0.2 || fraction > 0.3 {
+			t.Errorf("Expected about 25%% of requests to be sampled, got %.1f%%", fraction*100)
+		}
+	})
+
+	t.Run("inbound sampled trace is always sampled", func(t *testing.T) {
+		sampled = 0
+		handler := Trace(0)(testHandler)
+
+		for i := 0; i < 100; i++ {
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		if sampled != 100 {
+			t.Errorf("Expected all 100 requests to be sampled, got %d", sampled)
+		}
+	})
+
+	t.Run("inbound unsampled trace is not sampled", func(t *testing.T) {
+		sampled = 0
+		handler := Trace(1)(testHandler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if sampled != 0 {
+			t.Errorf("Expected the inbound decision to be respected, got %d sampled", sampled)
+		}
+	})
+
+	t.Run("trace ID is propagated", func(t *testing.T) {
+		handler := Trace(0)(testHandler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		parent, ok := parseTraceparent(w.Header().Get(TraceparentHeader))
+		if !ok || parent.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || !parent.Sampled {
+			t.Errorf("Expected response traceparent to continue the trace, got %q", w.Header().Get(TraceparentHeader))
+		}
+	})
+}
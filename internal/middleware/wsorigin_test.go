@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebSocketOrigin(t *testing.T) {
+	handler := WebSocketOrigin([]string{"https://app.example.com", "https://*.partner.com"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusSwitchingProtocols)
+	}))
+
+	tests := []struct {
+		name           string
+		upgrade        bool
+		origin         string
+		expectedStatus int
+	}{
+		{"allowed origin", true, "https://app.example.com", http.StatusSwitchingProtocols},
+		{"allowed wildcard origin", true, "https://eu.partner.com", http.StatusSwitchingProtocols},
+		{"same origin", true, "http://api.example.com", http.StatusSwitchingProtocols},
+		{"no origin", true, "", http.StatusSwitchingProtocols},
+		{"disallowed origin", true, "https://evil.example.net", http.StatusForbidden},
+		{"lookalike origin", true, "https://app.example.com.evil.net", http.StatusForbidden},
+		{"plain request from disallowed origin", false, "https://evil.example.net", http.StatusSwitchingProtocols},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://api.example.com/ws", nil)
+			if tt.upgrade {
+				req.Header.Set("Connection", "keep-alive, Upgrade")
+				req.Header.Set("Upgrade", "websocket")
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestWebSocketOriginWildcardIsSameOrigin(t *testing.T) {
+	for name, origins := range map[string][]string{
+		"star":       {"*"},
+		"empty list": nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := WebSocketOrigin(origins)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusSwitchingProtocols)
+			}))
+
+			send := func(origin string) int {
+				req := httptest.NewRequest("GET", "http://api.example.com/ws", nil)
+				req.Header.Set("Connection", "Upgrade")
+				req.Header.Set("Upgrade", "websocket")
+				req.Header.Set("Origin", origin)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				return rr.Code
+			}
+
+			if code := send("https://anywhere.example.net"); code != http.StatusForbidden {
+				t.Errorf("expected a cross-site upgrade to be rejected, got %d", code)
+			}
+			if code := send("https://api.example.com"); code != http.StatusSwitchingProtocols {
+				t.Errorf("expected a same-origin upgrade to be allowed, got %d", code)
+			}
+		})
+	}
+}
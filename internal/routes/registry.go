@@ -0,0 +1,91 @@
+package routes
+
+import (
+	"log"
+	"time"
+
+	"phantom-server/internal/config"
+	"phantom-server/internal/middleware"
+)
+
+// MiddlewareConstructor builds a middleware from the server configuration
+type MiddlewareConstructor func(cfg *config.Config) middleware.Middleware
+
+// middlewareRegistry maps the names accepted in ServerConfig.EnabledMiddleware
+// to their constructors
+var middlewareRegistry = map[string]MiddlewareConstructor{
+	"recover": func(cfg *config.Config) middleware.Middleware {
+		return middleware.Recover()
+	},
+	"requestid": func(cfg *config.Config) middleware.Middleware {
+		return middleware.RequestID()
+	},
+	"compress": func(cfg *config.Config) middleware.Middleware {
+		return middleware.Compress(cfg.Server.CompressionLevel)
+	},
+	"trace": func(cfg *config.Config) middleware.Middleware {
+		return middleware.Trace(cfg.Server.TraceSampleRate)
+	},
+	"methodoverride": func(cfg *config.Config) middleware.Middleware {
+		return middleware.MethodOverride()
+	},
+	"coalesce": func(cfg *config.Config) middleware.Middleware {
+		return middleware.Coalesce()
+	},
+	"servertiming": func(cfg *config.Config) middleware.Middleware {
+		return middleware.ServerTiming()
+	},
+	"contentlength": func(cfg *config.Config) middleware.Middleware {
+		return middleware.ContentLength(cfg.Server.MaxValidatedBodyBytes)
+	},
+	"requirejson": func(cfg *config.Config) middleware.Middleware {
+		return middleware.RequireJSON()
+	},
+	"servedby": func(cfg *config.Config) middleware.Middleware {
+		return middleware.ServedBy(cfg.Server.InstanceID)
+	},
+	"latency": func(cfg *config.Config) middleware.Middleware {
+		return middleware.ArtificialLatency(time.Duration(cfg.Server.ArtificialLatencyMs) * time.Millisecond)
+	},
+}
+
+// EnabledMiddlewareNames returns the optional middleware names from the config in order
+// EnableCompression implies "compress", and names listed more than once are only kept once
+func EnabledMiddlewareNames(cfg *config.Config) []string {
+	names := cfg.Server.EnabledMiddleware
+	if cfg.Server.EnableCompression {
+		names = append(names[:len(names):len(names)], "compress")
+	}
+
+	seen := make(map[string]bool, len(names))
+	var unique []string
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		unique = append(unique, name)
+	}
+
+	return unique
+}
+
+// enabledMiddleware returns the optional middleware named in the config, in order
+// Unknown names are logged and skipped. "requestid" is also skipped because
+// SetupRoutes installs it ahead of the access log
+func enabledMiddleware(cfg *config.Config) []middleware.Middleware {
+	var middlewares []middleware.Middleware
+	for _, name := range EnabledMiddlewareNames(cfg) {
+		if name == "requestid" {
+			continue
+		}
+		constructor, exists := middlewareRegistry[name]
+		if !exists {
+			log.Printf("Warning: unknown middleware %q in enabled_middleware, skipping", name)
+			continue
+		}
+		middlewares = append(middlewares, constructor(cfg))
+	}
+
+	return middlewares
+}
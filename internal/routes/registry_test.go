@@ -0,0 +1,91 @@
+package routes
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"phantom-server/internal/config"
+	"phantom-server/internal/handlers"
+)
+
+func TestEnabledMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	router := NewRouter(handlers.NewHandler())
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.EnabledMiddleware = []string{"requestid", "bogus"}
+	finalHandler := router.SetupRoutes(cfg)
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// Enabled middleware takes effect
+	if w.Header().Get("X-Request-ID") == "" {
+		t.Error("Expected X-Request-ID header from enabled requestid middleware")
+	}
+
+	// Disabled middleware does not
+	if ce := w.Header().Get("Content-Encoding"); ce != "" {
+		t.Errorf("Expected no Content-Encoding with compress disabled, got %q", ce)
+	}
+
+	// Unknown names warn
+	if !strings.Contains(buf.String(), `unknown middleware "bogus"`) {
+		t.Errorf("Expected warning for unknown middleware, got: %s", buf.String())
+	}
+}
+
+func TestEnabledMiddlewareDeduplicatesCompress(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnabledMiddleware = []string{"compress", "recover"}
+	cfg.Server.EnableCompression = true
+
+	if got := len(enabledMiddleware(cfg)); got != 2 {
+		t.Errorf("Expected 2 middleware, got %d", got)
+	}
+	if len(cfg.Server.EnabledMiddleware) != 2 {
+		t.Errorf("Expected config list to be left untouched, got %v", cfg.Server.EnabledMiddleware)
+	}
+}
+
+func TestEnabledRequestIDIsLogged(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	router := NewRouter(handlers.NewHandler())
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = true
+	cfg.Server.AccessLogFormat = "%path %request_id"
+	cfg.Server.EnabledMiddleware = []string{"requestid"}
+	handler := router.SetupRoutes(cfg)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+
+	id := w.Header().Get("X-Request-ID")
+	if id == "" {
+		t.Fatal("Expected a request ID on the response")
+	}
+	if !strings.Contains(buf.String(), "/health "+id+"\n") {
+		t.Errorf("Expected the access log to carry request ID %q, got: %s", id, buf.String())
+	}
+}
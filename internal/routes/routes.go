@@ -1,7 +1,16 @@
 package routes
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rs/cors"
 	"phantom-server/internal/config"
@@ -9,60 +18,552 @@ import (
 	"phantom-server/internal/middleware"
 )
 
+// StaticPrefix is the path prefix static files are served under
+const StaticPrefix = "/static/"
+
+// HealthPath is the health and readiness endpoint probed by load balancers
+const HealthPath = "/health"
+
+// ReadOnlyPath is the admin endpoint that switches read-only mode, which stays writable in that mode
+const ReadOnlyPath = "/admin/readonly"
+
+// RouteOptions holds per-route settings applied when the route is registered
+type RouteOptions struct {
+	CacheControl string        // Value of the Cache-Control header, empty sends no header
+	CSRF         bool          // Require a double-submit CSRF token on unsafe methods
+	Description  string        // Human-readable summary listed by /debug/routes
+	Tags         []string      // Labels for grouping routes in /debug/routes
+	CORSOrigins  []string      // Allowed origins overriding the global CORS policy, nil uses AllowedOrigins
+	DedupeTTL    time.Duration // Answer repeated request bodies within this window without running the handler, 0 disables it
+	Serialize    bool          // Process each client's requests one at a time in arrival order, keyed by API key or IP
+}
+
+// customRoute is a route added with Handle, registered when SetupRoutes runs
+type customRoute struct {
+	path        string
+	handlerFunc http.HandlerFunc
+}
+
 // Router manages HTTP routes and middleware integration
 type Router struct {
-	mux     *http.ServeMux
-	handler *handlers.Handler
+	mux      *http.ServeMux
+	handler  *handlers.Handler
+	routes   map[string]http.Handler
+	options  map[string]RouteOptions
+	live     *config.AtomicConfig
+	memory   *middleware.MemoryGuard
+	latency  *middleware.LatencyStats
+	buckets  *middleware.LatencyHistogram
+	conns    *middleware.ConnStats
+	custom   []customRoute
+	versions map[string]map[string]http.HandlerFunc
 }
 
 // NewRouter creates a new Router instance with handler dependency
 func NewRouter(handler *handlers.Handler) *Router {
 	return &Router{
-		mux:     http.NewServeMux(),
-		handler: handler,
+		mux:      http.NewServeMux(),
+		handler:  handler,
+		routes:   make(map[string]http.Handler),
+		options:  make(map[string]RouteOptions),
+		versions: make(map[string]map[string]http.HandlerFunc),
 	}
 }
 
+// SetLiveConfig sets the runtime configuration read by reloadable middleware
+// such as the rate limiter. Without it the config passed to SetupRoutes is used
+func (r *Router) SetLiveConfig(live *config.AtomicConfig) {
+	r.live = live
+}
+
+// SetMemoryGuard sets the guard used to shed load under memory pressure
+// The caller is responsible for running the guard's periodic check
+func (r *Router) SetMemoryGuard(guard *middleware.MemoryGuard) {
+	r.memory = guard
+}
+
+// SetConnStats sets the connection tracker reported at /debug/connections
+// The caller is responsible for installing its ConnState callback on the servers
+func (r *Router) SetConnStats(stats *middleware.ConnStats) {
+	r.conns = stats
+}
+
+// SetRouteOptions sets the options for a route path
+// It must be called before SetupRoutes for the options to take effect
+func (r *Router) SetRouteOptions(path string, opts RouteOptions) {
+	r.options[path] = opts
+}
+
+// ErrDuplicateRoute is returned by Handle and HandleVersion when the path already has a route
+var ErrDuplicateRoute = errors.New("route already registered")
+
+// builtinRoute is a route SetupRoutes registers itself when the configuration enables it
+type builtinRoute struct {
+	path    string
+	enabled func(r *Router, cfg *config.Config) bool
+	handler func(r *Router, cfg *config.Config) http.Handler
+}
+
+// builtinRoutes are the routes SetupRoutes registers before any added with Handle
+// or HandleVersion, in order. Those methods reject every path listed here, so
+// this table is the single source of the built-in paths
+var builtinRoutes = []builtinRoute{
+	{"/", always, func(r *Router, cfg *config.Config) http.Handler {
+		return http.HandlerFunc(r.handler.Home)
+	}},
+	{HealthPath, always, func(r *Router, cfg *config.Config) http.Handler {
+		return middleware.AllowMethods(cfg.Server.HealthMethods)(http.HandlerFunc(r.handler.Health))
+	}},
+	// Serve favicon and robots.txt when enabled to avoid noisy 404s
+	{"/favicon.ico", func(r *Router, cfg *config.Config) bool { return cfg.Server.ServeFavicon }, func(r *Router, cfg *config.Config) http.Handler {
+		return http.HandlerFunc(r.handler.Favicon)
+	}},
+	{"/robots.txt", func(r *Router, cfg *config.Config) bool { return cfg.Server.ServeRobotsTxt }, func(r *Router, cfg *config.Config) http.Handler {
+		return r.handler.RobotsTxt(cfg.Server.RobotsTxt)
+	}},
+	// Stream server-sent events when enabled
+	{"/events", func(r *Router, cfg *config.Config) bool { return cfg.Server.EnableEventStream }, func(r *Router, cfg *config.Config) http.Handler {
+		return http.HandlerFunc(r.handler.ClockEvents)
+	}},
+	// Admin endpoints are only exposed when an admin token is configured
+	{"/admin/flags", adminEnabled, func(r *Router, cfg *config.Config) http.Handler {
+		return adminOnly(cfg, http.HandlerFunc(r.handler.Flags))
+	}},
+	{ReadOnlyPath, adminEnabled, func(r *Router, cfg *config.Config) http.Handler {
+		return adminOnly(cfg, r.handler.ReadOnlyMode(r.readOnly(cfg), r.setReadOnly(cfg)))
+	}},
+	{"/debug/routes", adminEnabled, func(r *Router, cfg *config.Config) http.Handler {
+		return adminOnly(cfg, r.handler.RouteList(r.routeInfo))
+	}},
+	{"/debug/dependencies", adminEnabled, func(r *Router, cfg *config.Config) http.Handler {
+		return adminOnly(cfg, http.HandlerFunc(r.handler.Dependencies))
+	}},
+	// Stats endpoints are behind the admin token when one is configured
+	{"/debug/requests", func(r *Router, cfg *config.Config) bool { return r.latency != nil }, func(r *Router, cfg *config.Config) http.Handler {
+		return adminOnly(cfg, r.handler.RequestStats(r.latency))
+	}},
+	{"/metrics", func(r *Router, cfg *config.Config) bool { return r.buckets != nil }, func(r *Router, cfg *config.Config) http.Handler {
+		return adminOnly(cfg, r.handler.Metrics(r.buckets))
+	}},
+	{"/debug/connections", func(r *Router, cfg *config.Config) bool { return r.conns != nil }, func(r *Router, cfg *config.Config) http.Handler {
+		return adminOnly(cfg, r.handler.ConnectionStats(r.conns))
+	}},
+	// Serve static files from the configured directory, matching every path under the prefix
+	{StaticPrefix, func(r *Router, cfg *config.Config) bool { return cfg.Server.StaticDir != "" }, func(r *Router, cfg *config.Config) http.Handler {
+		return r.handler.StaticFiles(StaticPrefix, cfg.Server.StaticDir)
+	}},
+}
+
+// always enables a built-in route in every configuration
+func always(r *Router, cfg *config.Config) bool { return true }
+
+// adminEnabled enables a built-in route only when an admin token is configured
+func adminEnabled(r *Router, cfg *config.Config) bool { return cfg.Server.AdminToken != "" }
+
+// adminOnly puts handler behind the admin token when one is configured
+func adminOnly(cfg *config.Config, handler http.Handler) http.Handler {
+	if cfg.Server.AdminToken == "" {
+		return handler
+	}
+	return middleware.BearerToken(cfg.Server.AdminToken)(handler)
+}
+
+// isBuiltinRoute reports whether SetupRoutes may register path itself
+func isBuiltinRoute(path string) bool {
+	for _, route := range builtinRoutes {
+		if route.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle adds a route with optional options such as a description and tags
+// Like SetRouteOptions it must be called before SetupRoutes, which registers the route.
+// A built-in path, or one that was already added with Handle or HandleVersion, is
+// rejected with ErrDuplicateRoute and the first route is kept
+func (r *Router) Handle(path string, handlerFunc http.HandlerFunc, opts ...RouteOptions) error {
+	if isBuiltinRoute(path) || r.hasCustomRoute(path) {
+		return fmt.Errorf("%w: %s", ErrDuplicateRoute, path)
+	}
+	if len(opts) > 0 {
+		r.options[path] = opts[0]
+	}
+	r.custom = append(r.custom, customRoute{path: path, handlerFunc: handlerFunc})
+	return nil
+}
+
+// hasCustomRoute reports whether path was added with Handle or HandleVersion
+func (r *Router) hasCustomRoute(path string) bool {
+	_, exists := r.versions[path]
+	return exists || r.hasHandledRoute(path)
+}
+
+// hasHandledRoute reports whether path was added with Handle
+func (r *Router) hasHandledRoute(path string) bool {
+	for _, route := range r.custom {
+		if route.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleVersion adds one version of a route, such as "1" or "v2", for the same path
+// The version is chosen per request from X-API-Version or the Accept header, and
+// requests that do not ask for one get the latest. Like Handle it must be called
+// before SetupRoutes, with options given on any version applying to the path.
+// A built-in path, one added with Handle, or a version already added for the path
+// is rejected with ErrDuplicateRoute and the first route is kept
+func (r *Router) HandleVersion(path, version string, handlerFunc http.HandlerFunc, opts ...RouteOptions) error {
+	version = middleware.NormalizeAPIVersion(version)
+	if isBuiltinRoute(path) || r.hasHandledRoute(path) {
+		return fmt.Errorf("%w: %s", ErrDuplicateRoute, path)
+	}
+	if _, exists := r.versions[path][version]; exists {
+		return fmt.Errorf("%w: %s version %s", ErrDuplicateRoute, path, version)
+	}
+
+	if len(opts) > 0 {
+		r.options[path] = opts[0]
+	}
+	if r.versions[path] == nil {
+		r.versions[path] = make(map[string]http.HandlerFunc)
+	}
+	r.versions[path][version] = handlerFunc
+	return nil
+}
+
 // SetupRoutes configures all routes with middleware and returns the final handler
 func (r *Router) SetupRoutes(cfg *config.Config) http.Handler {
-	// Register specific routes
-	r.mux.HandleFunc("/", r.handler.Home)
-	r.mux.HandleFunc("/health", r.handler.Health)
+	cfg = withDefaultMethods(cfg)
+
+	// Track per-route latency when enabled, before any route is registered
+	if cfg.Server.EnableRequestStats {
+		r.latency = middleware.NewLatencyStats(middleware.DefaultLatencySamples)
+	}
+
+	// Collect the request duration histogram when buckets are configured
+	if len(cfg.Server.LatencyBucketsMs) > 0 {
+		bounds := make([]time.Duration, len(cfg.Server.LatencyBucketsMs))
+		for i, ms := range cfg.Server.LatencyBucketsMs {
+			bounds[i] = time.Duration(ms) * time.Millisecond
+		}
+		r.buckets = middleware.NewLatencyHistogram(bounds)
+	}
+
+	// Register the built-in routes first, so a configured or added route can never replace one
+	for _, route := range builtinRoutes {
+		if route.enabled(r, cfg) {
+			r.handle(route.path, route.handler(r, cfg).ServeHTTP)
+		}
+	}
+
+	// Forward configured routes to their upstreams
+	proxyRetryAfter := time.Duration(cfg.Server.RetryAfterSeconds) * time.Second
+	for path, upstream := range cfg.Server.ProxyRoutes {
+		if err := config.ValidateRoutePath(path); err != nil {
+			log.Printf("Warning: skipping proxy route: %v", err)
+			continue
+		}
+		target, err := url.Parse(upstream)
+		if err != nil {
+			log.Printf("Warning: invalid upstream %q for proxy route %s: %v", upstream, path, err)
+			continue
+		}
+		r.handle(path, r.handler.Proxy(target, proxyRetryAfter).ServeHTTP)
+	}
+
+	// Register routes added with Handle
+	for _, route := range r.custom {
+		r.handle(route.path, route.handlerFunc)
+	}
+
+	// Register routes added with HandleVersion, dispatching on the requested version
+	for path, versions := range r.versions {
+		r.handle(path, r.versionHandler(versions))
+	}
+	staticFiles := r.routes[StaticPrefix]
 
 	// Create a wrapper that handles 404s for unregistered routes
 	routeHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		// For the root path, serve it directly
-		if req.URL.Path == "/" {
-			r.handler.Home(w, req)
+		// Serve registered paths directly
+		if handler, exists := r.routes[req.URL.Path]; exists {
+			handler.ServeHTTP(w, req)
 			return
 		}
-		// For health path, serve it directly
-		if req.URL.Path == "/health" {
-			r.handler.Health(w, req)
+		// Serve static files under the static prefix
+		if staticFiles != nil && strings.HasPrefix(req.URL.Path, StaticPrefix) {
+			staticFiles.ServeHTTP(w, req)
 			return
 		}
 		// For all other paths, return 404
 		r.handler.NotFound(w, req)
 	})
 
-	// Setup CORS middleware
-	corsHandler := r.setupCORS(cfg)
-
-	// Create middleware chain: Logger -> CORS -> Routes
-	middlewareChain := middleware.Chain(
-		middleware.Logger(cfg.Server.EnableLogging),
+	// Create middleware chain: ResponseHeaderLimit -> StripHeaders -> RequestStore -> [RequestID] -> Logger -> PathTraversalGuard -> MaxQueryParams -> WebSocketOrigin -> GlobalMethods -> ReadOnly -> HTTP10 -> CanonicalHost -> RewritePath -> [TierRateLimit] -> RateLimit -> Options -> [APIVersion] -> [EnabledMiddleware] -> [GlobalMethods] -> Routes
+	middlewares := []middleware.Middleware{
+		middleware.ResponseHeaderLimit(cfg.Server.MaxResponseHeaders, cfg.Server.MaxResponseHeaderBytes),
+		middleware.StripHeaders(cfg.Server.StripResponseHeaders),
+		middleware.RequestStore(),
+	}
+	// RequestID runs ahead of the access log so logged lines carry the assigned ID;
+	// enabledMiddleware leaves it out of the optional middleware for the same reason
+	if slices.Contains(EnabledMiddlewareNames(cfg), "requestid") {
+		middlewares = append(middlewares, middleware.RequestID())
+	}
+	middlewares = append(middlewares,
+		middleware.AccessLog(cfg.Server.EnableLogging, cfg.Server.AccessLogFormat),
+		middleware.PathTraversalGuard(),
+		middleware.MaxQueryParams(cfg.Server.MaxQueryParams),
+		middleware.WebSocketOrigin(cfg.Server.AllowedOrigins),
+		middleware.AllowMethods(cfg.Server.GlobalAllowedMethods),
+		middleware.DenyMethods(cfg.Server.GlobalDeniedMethods),
+		middleware.ReadOnly(r.readOnly(cfg), time.Duration(cfg.Server.RetryAfterSeconds)*time.Second, ReadOnlyPath),
+		middleware.HTTP10(),
+		middleware.CanonicalHost(cfg.Server.CanonicalHost, HealthPath),
+		middleware.RewritePath(cfg.Server.PathRewrites),
 	)
+	// With API keys the per-IP limit applies to every request not limited by its tier
+	rateLimit := middleware.RateLimit(r.rateLimits(cfg))
+	if len(cfg.Server.APIKeys) > 0 {
+		rateLimit = middleware.TierRateLimit(cfg.Server.APIKeys, tierLimits(cfg), rateLimit)
+	}
+	middlewares = append(middlewares, rateLimit)
+	middlewares = append(middlewares, middleware.Options(cfg.Server.OptionsStatus, cfg.Server.AllowedMethods))
+	if r.memory != nil {
+		middlewares = append(middlewares, r.memory.Middleware())
+	}
+	if len(r.versions) > 0 {
+		middlewares = append(middlewares, middleware.APIVersion())
+	}
+	middlewares = append(middlewares, enabledMiddleware(cfg)...)
+	// MethodOverride changes the method after the global checks above, so the
+	// effective method is checked again once it has run
+	if slices.Contains(EnabledMiddlewareNames(cfg), "methodoverride") {
+		middlewares = append(middlewares,
+			middleware.AllowMethods(cfg.Server.GlobalAllowedMethods),
+			middleware.DenyMethods(cfg.Server.GlobalDeniedMethods),
+		)
+	}
+	middlewareChain := middleware.Chain(middlewares...)
 
 	// Apply middleware chain to the route handler, then wrap with CORS
-	return corsHandler.Handler(middlewareChain(routeHandler))
+	finalHandler := r.corsHandler(cfg, middlewareChain(routeHandler))
+
+	// Bucket the duration of every request, including those answered by middleware
+	if r.buckets != nil {
+		finalHandler = r.buckets.Middleware()(finalHandler)
+	}
+
+	// The total request budget wraps CORS and all middleware. Response timeouts are
+	// outermost so the write deadline also covers responses from the budget itself
+	budget := time.Duration(cfg.Server.TotalRequestBudget) * time.Second
+	handlerTimeout := time.Duration(cfg.Server.HandlerTimeout) * time.Second
+	writeTimeout := time.Duration(cfg.Server.WriteTimeout) * time.Second
+	return middleware.ResponseTimeouts(handlerTimeout, writeTimeout)(middleware.RequestBudget(budget)(finalHandler))
+}
+
+// handle registers a route, applying any per-route options as middleware
+// A path that is already registered, such as a built-in route, is skipped with a
+// warning instead of replacing the existing route or panicking in the mux
+func (r *Router) handle(path string, handlerFunc http.HandlerFunc) {
+	if _, exists := r.routes[path]; exists {
+		log.Printf("Warning: route %s is already registered, ignoring duplicate registration", path)
+		return
+	}
+
+	opts := r.options[path]
+	handler := middleware.CacheControl(opts.CacheControl)(handlerFunc)
+	if opts.DedupeTTL > 0 {
+		handler = middleware.NewDeduplicator(opts.DedupeTTL).Middleware()(handler)
+	}
+	if opts.Serialize {
+		handler = middleware.SerializeClients()(handler)
+	}
+	if opts.CSRF {
+		handler = middleware.CSRF()(handler)
+	}
+	if r.latency != nil {
+		handler = r.latency.Middleware(path)(handler)
+	}
+
+	r.mux.Handle(path, handler)
+	r.routes[path] = handler
+}
+
+// versionHandler dispatches to the handler for the requested API version, using the
+// latest version when none is requested and answering 406 for unknown versions
+func (r *Router) versionHandler(versions map[string]http.HandlerFunc) http.HandlerFunc {
+	supported := make([]string, 0, len(versions))
+	for version := range versions {
+		supported = append(supported, version)
+	}
+	sort.Slice(supported, func(i, j int) bool {
+		return versionLess(supported[i], supported[j])
+	})
+	latest := supported[len(supported)-1]
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", middleware.APIVersionHeader)
+		w.Header().Add("Vary", "Accept")
+
+		version := middleware.APIVersionFromContext(req.Context())
+		if version == "" {
+			version = latest
+		}
+
+		handlerFunc, exists := versions[version]
+		if !exists {
+			r.handler.UnsupportedVersion(w, req, version, supported)
+			return
+		}
+		handlerFunc(w, req)
+	}
+}
+
+// versionLess orders versions numerically when both are numbers, otherwise lexically
+func versionLess(a, b string) bool {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	if aErr == nil && bErr == nil {
+		return an < bn
+	}
+	return a < b
+}
+
+// routeInfo lists every registered route with its description and tags, sorted by path
+func (r *Router) routeInfo() []handlers.RouteInfo {
+	paths := make([]string, 0, len(r.routes))
+	for path := range r.routes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	infos := make([]handlers.RouteInfo, 0, len(paths))
+	for _, path := range paths {
+		opts := r.options[path]
+		infos = append(infos, handlers.RouteInfo{
+			Path:        path,
+			Description: opts.Description,
+			Tags:        opts.Tags,
+		})
+	}
+	return infos
+}
+
+// rateLimits returns the rate limit source, preferring the live config when set
+func (r *Router) rateLimits(cfg *config.Config) middleware.RateLimitFunc {
+	return func() (int, time.Duration) {
+		current := cfg
+		if r.live != nil {
+			current = r.live.Load()
+		}
+		return current.Server.RateLimitRequests, time.Duration(current.Server.RateLimitWindow) * time.Second
+	}
+}
+
+// readOnly reports whether writes are rejected, preferring the live config when set
+func (r *Router) readOnly(cfg *config.Config) func() bool {
+	return func() bool {
+		if r.live != nil {
+			return r.live.Load().Server.ReadOnly
+		}
+		return cfg.Server.ReadOnly
+	}
+}
+
+// setReadOnly switches read-only mode by storing an updated copy of the live config
+// The change lasts until the next reload, which restores the configured mode.
+// Without a live config one is created from cfg so the switch still takes effect
+func (r *Router) setReadOnly(cfg *config.Config) func(bool) {
+	if r.live == nil {
+		r.live = config.NewAtomicConfig(cfg)
+	}
+	return func(enabled bool) {
+		updated := *r.live.Load()
+		updated.Server.ReadOnly = enabled
+		r.live.Store(&updated)
+	}
+}
+
+// corsHandler wraps next with the global CORS policy, switching to a route's own
+// policy for paths that override it through RouteOptions or RouteCORSOrigins.
+// The config takes precedence over options set in code
+func (r *Router) corsHandler(cfg *config.Config, next http.Handler) http.Handler {
+	perRoute := make(map[string]http.Handler)
+	for path, opts := range r.options {
+		if opts.CORSOrigins != nil {
+			perRoute[path] = r.newCORS(cfg, opts.CORSOrigins).Handler(next)
+		}
+	}
+	for path, origins := range cfg.Server.RouteCORSOrigins {
+		perRoute[path] = r.newCORS(cfg, origins).Handler(next)
+	}
+
+	global := r.setupCORS(cfg).Handler(next)
+	if len(perRoute) == 0 {
+		return global
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if handler, exists := perRoute[req.URL.Path]; exists {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		global.ServeHTTP(w, req)
+	})
+}
+
+// tierLimits converts the configured rate limit tiers to middleware limits
+func tierLimits(cfg *config.Config) map[string]middleware.TierLimit {
+	limits := make(map[string]middleware.TierLimit, len(cfg.Server.RateLimitTiers))
+	for name, tier := range cfg.Server.RateLimitTiers {
+		limits[name] = middleware.TierLimit{
+			Requests: tier.Requests,
+			Window:   time.Duration(tier.WindowSeconds) * time.Second,
+		}
+	}
+	return limits
+}
+
+// withDefaultMethods returns cfg, or a copy using the default AllowedMethods when
+// its list is empty, since CORS and OPTIONS would otherwise allow no method at all
+func withDefaultMethods(cfg *config.Config) *config.Config {
+	if len(cfg.Server.AllowedMethods) > 0 {
+		return cfg
+	}
+
+	defaults := config.GetDefaultConfig().Server.AllowedMethods
+	log.Printf("Warning: allowed_methods is empty, using the defaults %s", strings.Join(defaults, ", "))
+	fallback := *cfg
+	fallback.Server.AllowedMethods = defaults
+	return &fallback
 }
 
 // setupCORS configures CORS using rs/cors package with config options
 func (r *Router) setupCORS(cfg *config.Config) *cors.Cors {
-	return cors.New(cors.Options{
-		AllowedOrigins:   cfg.Server.AllowedOrigins,
-		AllowedMethods:   cfg.Server.AllowedMethods,
-		AllowedHeaders:   []string{"*"},
-		AllowCredentials: true,
-	})
+	return r.newCORS(cfg, cfg.Server.AllowedOrigins)
+}
+
+// newCORS configures CORS for the given allowed origins with the other config options
+// Specific origins are matched through an OriginSet rather than rs/cors' linear
+// scan, so deployments with hundreds of allowed origins stay O(1) per request.
+// Browsers reject credentialed responses with a wildcard origin, so credentials
+// are only allowed for explicit origin lists
+func (r *Router) newCORS(cfg *config.Config, allowedOrigins []string) *cors.Cors {
+	opts := cors.Options{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: cfg.Server.AllowedMethods,
+		AllowedHeaders: []string{"*"},
+	}
+
+	// Leave match-all lists to rs/cors so it keeps answering with "*"
+	if origins := middleware.NewOriginSet(allowedOrigins); !origins.AllowsAll() {
+		opts.AllowedOrigins = nil
+		opts.AllowOriginFunc = origins.Allowed
+		opts.AllowCredentials = true
+	}
+
+	return cors.New(opts)
 }
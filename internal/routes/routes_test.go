@@ -1,9 +1,22 @@
 package routes
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"phantom-server/internal/config"
 	"phantom-server/internal/handlers"
@@ -77,3 +90,1024 @@ func TestSetupCORS(t *testing.T) {
 		t.Fatal("setupCORS returned nil")
 	}
 }
+
+func TestSetupRoutesFaviconAndRobots(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		router := NewRouter(handlers.NewHandler())
+		cfg := config.GetDefaultConfig()
+		cfg.Server.EnableLogging = false
+		finalHandler := router.SetupRoutes(cfg)
+
+		req := httptest.NewRequest("GET", "/favicon.ico", nil)
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "image/x-icon" {
+			t.Errorf("Expected favicon content type image/x-icon, got %s", ct)
+		}
+
+		req = httptest.NewRequest("GET", "/robots.txt", nil)
+		w = httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+			t.Errorf("Expected robots.txt content type text/plain, got %s", ct)
+		}
+		if w.Body.String() != handlers.DefaultRobotsTxt {
+			t.Errorf("Expected default robots.txt, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router := NewRouter(handlers.NewHandler())
+		cfg := config.GetDefaultConfig()
+		cfg.Server.EnableLogging = false
+		cfg.Server.ServeFavicon = false
+		cfg.Server.ServeRobotsTxt = false
+		finalHandler := router.SetupRoutes(cfg)
+
+		for _, path := range []string{"/favicon.ico", "/robots.txt"} {
+			req := httptest.NewRequest("GET", path, nil)
+			w := httptest.NewRecorder()
+			finalHandler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status %d for %s, got %d", http.StatusNotFound, path, w.Code)
+			}
+		}
+	})
+}
+
+func TestRouteOptionsCacheControl(t *testing.T) {
+	router := NewRouter(handlers.NewHandler())
+	router.SetRouteOptions("/", RouteOptions{CacheControl: "public, max-age=3600"})
+	router.SetRouteOptions("/health", RouteOptions{CacheControl: "no-cache"})
+
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	finalHandler := router.SetupRoutes(cfg)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/", "public, max-age=3600"},
+		{"/health", "no-cache"},
+		{"/robots.txt", ""},
+		{"/nonexistent", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+
+		if cc := w.Header().Get("Cache-Control"); cc != tt.expected {
+			t.Errorf("Expected Cache-Control %q for %s, got %q", tt.expected, tt.path, cc)
+		}
+	}
+}
+
+func TestSetupRoutesLiveRateLimit(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	live := config.NewAtomicConfig(cfg)
+
+	router := NewRouter(handlers.NewHandler())
+	router.SetLiveConfig(live)
+	finalHandler := router.SetupRoutes(cfg)
+
+	send := func() int {
+		req := httptest.NewRequest("GET", "/health", nil)
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send(); code != http.StatusOK {
+		t.Fatalf("Expected status %d with rate limiting disabled, got %d", http.StatusOK, code)
+	}
+
+	// Reload with a limit of one request per window
+	reloaded := config.MergeConfigs(cfg, &config.Config{Server: config.ServerConfig{RateLimitRequests: 1}})
+	live.Store(reloaded)
+
+	if code := send(); code != http.StatusOK {
+		t.Errorf("Expected first request after reload to pass, got %d", code)
+	}
+	if code := send(); code != http.StatusTooManyRequests {
+		t.Errorf("Expected second request after reload to be limited, got %d", code)
+	}
+}
+
+func TestSetupRoutesTierLimitReplacesIPLimit(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.RateLimitRequests = 1
+	cfg.Server.RateLimitWindow = 60
+	cfg.Server.APIKeys = map[string]string{"pro-key": "pro"}
+	cfg.Server.RateLimitTiers = map[string]config.RateLimitTier{"pro": {Requests: 3, WindowSeconds: 60}}
+	finalHandler := NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+	send := func(key string) int {
+		req := httptest.NewRequest("GET", "/health", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A keyed request from the same IP is limited by its tier, not the per-IP limit
+	for i := 0; i < 3; i++ {
+		if code := send("pro-key"); code != http.StatusOK {
+			t.Fatalf("Expected pro request %d to pass, got %d", i+1, code)
+		}
+	}
+	if code := send("pro-key"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the pro tier limit to apply, got %d", code)
+	}
+
+	// Keyless requests still get the per-IP limit
+	if code := send(""); code != http.StatusOK {
+		t.Errorf("Expected first keyless request to pass, got %d", code)
+	}
+	if code := send(""); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the per-IP limit for keyless requests, got %d", code)
+	}
+}
+
+func TestRouteOptionsCSRF(t *testing.T) {
+	router := NewRouter(handlers.NewHandler())
+	router.SetRouteOptions("/", RouteOptions{CSRF: true})
+
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	finalHandler := router.SetupRoutes(cfg)
+
+	// Opted-in route rejects unsafe methods without a token
+	req := httptest.NewRequest("POST", "/", nil)
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+
+	// Other routes are unaffected
+	req = httptest.NewRequest("POST", "/health", nil)
+	w = httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestSetupRoutesAdminFlags(t *testing.T) {
+	t.Run("requires admin token", func(t *testing.T) {
+		handler := handlers.NewHandler()
+		router := NewRouter(handler)
+		cfg := config.GetDefaultConfig()
+		cfg.Server.EnableLogging = false
+		cfg.Server.AdminToken = "secret"
+		finalHandler := router.SetupRoutes(cfg)
+
+		req := httptest.NewRequest("GET", "/admin/flags", nil)
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+
+		req = httptest.NewRequest("GET", "/admin/flags", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w = httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("disabled without admin token", func(t *testing.T) {
+		router := NewRouter(handlers.NewHandler())
+		cfg := config.GetDefaultConfig()
+		cfg.Server.EnableLogging = false
+		finalHandler := router.SetupRoutes(cfg)
+
+		req := httptest.NewRequest("GET", "/admin/flags", nil)
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
+
+func TestSetupRoutesReadOnly(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.AdminToken = "secret"
+	cfg.Server.ReadOnly = true
+	live := config.NewAtomicConfig(cfg)
+
+	router := NewRouter(handlers.NewHandler())
+	router.SetLiveConfig(live)
+	finalHandler := router.SetupRoutes(cfg)
+
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("GET", "/", ""); code != http.StatusOK {
+		t.Errorf("Expected reads to pass in read-only mode, got %d", code)
+	}
+	if code := send("POST", "/", ""); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected writes to be rejected in read-only mode, got %d", code)
+	}
+
+	// The admin endpoint stays writable so it can switch read-only mode off
+	if code := send("POST", ReadOnlyPath, `{"enabled": false}`); code != http.StatusOK {
+		t.Fatalf("Expected read-only mode to be switched off, got %d", code)
+	}
+	if live.Load().Server.ReadOnly {
+		t.Error("Expected the live config to leave read-only mode")
+	}
+	if code := send("POST", "/", ""); code != http.StatusOK {
+		t.Errorf("Expected writes to pass after read-only mode is off, got %d", code)
+	}
+
+	// A reload restores the configured mode
+	live.Store(cfg)
+	if code := send("DELETE", "/", ""); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected writes to be rejected after reload, got %d", code)
+	}
+}
+
+func TestSetupRoutesEventStream(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.EnableEventStream = true
+	server := httptest.NewServer(NewRouter(handlers.NewHandler()).SetupRoutes(cfg))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect to event stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != handlers.SSEContentType {
+		t.Errorf("Expected Content-Type %q, got %q", handlers.SSEContentType, ct)
+	}
+
+	// The first event arrives through the middleware chain without waiting for the stream to end
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read event: %v", err)
+	}
+	if !strings.HasPrefix(line, `data: {"time":`) {
+		t.Errorf("Expected a time event, got %q", line)
+	}
+}
+
+func TestSetupRoutesStaticFilesCompressedOnTheFly(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "style.css"), []byte(strings.Repeat("body {} ", 100)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	router := NewRouter(handlers.NewHandler())
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.EnableCompression = true
+	cfg.Server.StaticDir = root
+	finalHandler := router.SetupRoutes(cfg)
+
+	req := httptest.NewRequest("GET", "/static/style.css", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if ce := w.Header().Get("Content-Encoding"); ce != "gzip" {
+		t.Errorf("Expected asset to be compressed on the fly, got Content-Encoding %q", ce)
+	}
+}
+
+func TestSetupRoutesRequestStats(t *testing.T) {
+	router := NewRouter(handlers.NewHandler())
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.EnableRequestStats = true
+	finalHandler := router.SetupRoutes(cfg)
+
+	for _, path := range []string{"/", "/health", "/health"} {
+		finalHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	req := httptest.NewRequest("GET", "/debug/requests", nil)
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Data struct {
+			Routes []struct {
+				Route string `json:"route"`
+				Count int    `json:"count"`
+			} `json:"routes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not parse response JSON: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, route := range response.Data.Routes {
+		counts[route.Route] = route.Count
+	}
+	if counts["/"] != 1 || counts["/health"] != 2 {
+		t.Errorf("Expected per-route counts for / and /health, got %v", counts)
+	}
+}
+
+func TestSetupRoutesMetrics(t *testing.T) {
+	router := NewRouter(handlers.NewHandler())
+	router.Handle("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.LatencyBucketsMs = []int{10, 1000}
+	finalHandler := router.SetupRoutes(cfg)
+
+	for _, path := range []string{"/health", "/health", "/slow"} {
+		finalHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != handlers.MetricsContentType {
+		t.Errorf("Expected Content-Type %q, got %q", handlers.MetricsContentType, ct)
+	}
+	for _, line := range []string{
+		"# TYPE http_request_duration_seconds histogram",
+		`http_request_duration_seconds_bucket{le="0.01"} 2`,
+		`http_request_duration_seconds_bucket{le="1"} 3`,
+		`http_request_duration_seconds_bucket{le="+Inf"} 3`,
+		"http_request_duration_seconds_count 3",
+	} {
+		if !strings.Contains(w.Body.String(), line+"\n") {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, w.Body.String())
+		}
+	}
+}
+
+func TestRouterHandleDescribedRoute(t *testing.T) {
+	router := NewRouter(handlers.NewHandler())
+	router.Handle("/api/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, RouteOptions{Description: "List users", Tags: []string{"users"}})
+
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.AdminToken = "secret"
+	finalHandler := router.SetupRoutes(cfg)
+
+	// The described route itself is served
+	req := httptest.NewRequest("GET", "/api/users", nil)
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// The listing requires the admin token
+	req = httptest.NewRequest("GET", "/debug/routes", nil)
+	w = httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/debug/routes", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Data struct {
+			Routes []handlers.RouteInfo `json:"routes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("could not parse response JSON: %v", err)
+	}
+
+	var found *handlers.RouteInfo
+	for i := range response.Data.Routes {
+		if response.Data.Routes[i].Path == "/api/users" {
+			found = &response.Data.Routes[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("Expected /api/users in route listing, got %+v", response.Data.Routes)
+	}
+	if found.Description != "List users" || len(found.Tags) != 1 || found.Tags[0] != "users" {
+		t.Errorf("Expected description and tags to be listed, got %+v", *found)
+	}
+}
+
+func TestRouterHandleDuplicateRoute(t *testing.T) {
+	router := NewRouter(handlers.NewHandler())
+	first := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	second := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+
+	if err := router.Handle("/api/users", first); err != nil {
+		t.Fatalf("Expected first registration to succeed, got %v", err)
+	}
+	if err := router.Handle("/api/users", second); !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected ErrDuplicateRoute, got %v", err)
+	}
+
+	if err := router.Handle("/health", second); !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected ErrDuplicateRoute for a built-in path, got %v", err)
+	}
+	if err := router.Handle(StaticPrefix, second); !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected ErrDuplicateRoute for the static prefix, got %v", err)
+	}
+	if err := router.HandleVersion(StaticPrefix, "v1", second); !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected ErrDuplicateRoute for a versioned static prefix, got %v", err)
+	}
+	if err := router.HandleVersion("/api/users", "v1", second); !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected ErrDuplicateRoute for a versioned route over Handle, got %v", err)
+	}
+
+	// A configured route colliding with a built-in one is skipped with a warning at setup
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.ProxyRoutes = map[string]string{"/health": "http://127.0.0.1:1", StaticPrefix: "http://127.0.0.1:1"}
+	cfg.Server.StaticDir = t.TempDir()
+	finalHandler := router.SetupRoutes(cfg)
+
+	if !strings.Contains(buf.String(), "route /health is already registered") {
+		t.Errorf("Expected a duplicate registration warning, got %q", buf.String())
+	}
+
+	for _, path := range []string{"/api/users", "/health"} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected the first route for %s to be kept, got status %d", path, w.Code)
+		}
+	}
+}
+
+func TestSetupRoutesInvalidProxyPaths(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.ProxyRoutes = map[string]string{
+		"api":     "http://127.0.0.1:1",
+		"api/":    "http://127.0.0.1:1",
+		"/x/{bad": "http://127.0.0.1:1",
+	}
+	finalHandler := NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+	for _, path := range []string{`"api"`, `"api/"`, `"/x/{bad"`} {
+		if !strings.Contains(buf.String(), path) {
+			t.Errorf("Expected a warning for proxy route %s, got %q", path, buf.String())
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the server to keep serving, got status %d", w.Code)
+	}
+}
+
+func TestRouterHandleVersionDuplicateRoute(t *testing.T) {
+	router := NewRouter(handlers.NewHandler())
+	handlerFunc := func(w http.ResponseWriter, r *http.Request) {}
+
+	if err := router.HandleVersion("/api/items", "1", handlerFunc); err != nil {
+		t.Fatalf("Expected first version to succeed, got %v", err)
+	}
+	if err := router.HandleVersion("/api/items", "v2", handlerFunc); err != nil {
+		t.Errorf("Expected another version of the same path to succeed, got %v", err)
+	}
+	if err := router.HandleVersion("/api/items", "v1", handlerFunc); !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected ErrDuplicateRoute for a repeated version, got %v", err)
+	}
+	if err := router.HandleVersion("/health", "v1", handlerFunc); !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected ErrDuplicateRoute for a built-in path, got %v", err)
+	}
+	if err := router.Handle("/api/items", handlerFunc); !errors.Is(err, ErrDuplicateRoute) {
+		t.Errorf("Expected ErrDuplicateRoute for Handle over a versioned route, got %v", err)
+	}
+}
+
+func TestSetupRoutesDependencies(t *testing.T) {
+	handler := handlers.NewHandler()
+	handler.RegisterHealthCheck("database", func(ctx context.Context) error { return nil })
+
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+
+	// Without an admin token the endpoint is not exposed
+	finalHandler := NewRouter(handler).SetupRoutes(cfg)
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, httptest.NewRequest("GET", "/debug/dependencies", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	cfg.Server.AdminToken = "secret"
+	finalHandler = NewRouter(handler).SetupRoutes(cfg)
+
+	w = httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, httptest.NewRequest("GET", "/debug/dependencies", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/debug/dependencies", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"name":"database"`) {
+		t.Errorf("Expected database in dependency listing, got %s", w.Body.String())
+	}
+}
+
+func TestSetupRoutesOptionsWithoutOrigin(t *testing.T) {
+	router := NewRouter(handlers.NewHandler())
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.OptionsStatus = http.StatusNoContent
+	finalHandler := router.SetupRoutes(cfg)
+
+	req := httptest.NewRequest("OPTIONS", "/nonexistent", nil)
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != strings.Join(cfg.Server.AllowedMethods, ", ") {
+		t.Errorf("Expected Allow to list the allowed methods, got %q", allow)
+	}
+}
+
+func TestSetupRoutesEmptyAllowedMethods(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.AllowedMethods = []string{}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	finalHandler := NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+	if !strings.Contains(buf.String(), "allowed_methods is empty") {
+		t.Errorf("Expected a warning about empty allowed methods, got %q", buf.String())
+	}
+	if len(cfg.Server.AllowedMethods) != 0 {
+		t.Errorf("Expected the caller's config to be left unchanged, got %v", cfg.Server.AllowedMethods)
+	}
+
+	// A preflight for a default method is allowed instead of rejected
+	req := httptest.NewRequest("OPTIONS", "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "PUT" {
+		t.Errorf("Expected PUT to be allowed by the default methods, got %q", got)
+	}
+}
+
+func TestSetupCORSLargeOriginList(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.AllowedOrigins = nil
+	for i := 0; i < 500; i++ {
+		cfg.Server.AllowedOrigins = append(cfg.Server.AllowedOrigins, fmt.Sprintf("https://app%d.example.com", i))
+	}
+	finalHandler := NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+	tests := []struct {
+		origin   string
+		expected string
+	}{
+		{"https://app499.example.com", "https://app499.example.com"},
+		{"https://app0.example.com", "https://app0.example.com"},
+		{"https://evil.example.com", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/health", nil)
+		req.Header.Set("Origin", tt.origin)
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+
+		if acao := w.Header().Get("Access-Control-Allow-Origin"); acao != tt.expected {
+			t.Errorf("Expected Access-Control-Allow-Origin %q for %s, got %q", tt.expected, tt.origin, acao)
+		}
+	}
+}
+
+func TestSetupRoutesPerRouteCORS(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	router := NewRouter(handlers.NewHandler())
+	router.Handle("/public", ok)
+	router.Handle("/admin", ok)
+	router.Handle("/partners", ok, RouteOptions{CORSOrigins: []string{"https://partner.example.com"}})
+
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.RouteCORSOrigins = map[string][]string{
+		"/admin": {"https://admin.example.com"},
+	}
+	finalHandler := router.SetupRoutes(cfg)
+
+	preflight := func(path, origin string) string {
+		req := httptest.NewRequest("OPTIONS", path, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+		return w.Header().Get("Access-Control-Allow-Origin")
+	}
+
+	tests := []struct {
+		path     string
+		origin   string
+		expected string
+	}{
+		{"/public", "https://other.example.com", "*"},
+		{"/admin", "https://other.example.com", ""},
+		{"/admin", "https://admin.example.com", "https://admin.example.com"},
+		{"/partners", "https://other.example.com", ""},
+		{"/partners", "https://partner.example.com", "https://partner.example.com"},
+	}
+
+	for _, tt := range tests {
+		if acao := preflight(tt.path, tt.origin); acao != tt.expected {
+			t.Errorf("Preflight to %s from %s: expected Access-Control-Allow-Origin %q, got %q", tt.path, tt.origin, tt.expected, acao)
+		}
+	}
+}
+
+func TestSetupCORSCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		origins     []string
+		credentials string
+	}{
+		{"wildcard", []string{"*"}, ""},
+		{"wildcard among explicit", []string{"https://app.example.com", "*"}, ""},
+		{"empty list allows all", nil, ""},
+		{"explicit", []string{"https://app.example.com"}, "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.GetDefaultConfig()
+			cfg.Server.EnableLogging = false
+			cfg.Server.AllowedOrigins = tt.origins
+			finalHandler := NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+			req := httptest.NewRequest("OPTIONS", "/health", nil)
+			req.Header.Set("Origin", "https://app.example.com")
+			req.Header.Set("Access-Control-Request-Method", "GET")
+			w := httptest.NewRecorder()
+			finalHandler.ServeHTTP(w, req)
+
+			if acao := w.Header().Get("Access-Control-Allow-Origin"); acao == "" {
+				t.Fatal("Expected origin to be allowed")
+			}
+			if acac := w.Header().Get("Access-Control-Allow-Credentials"); acac != tt.credentials {
+				t.Errorf("Expected Access-Control-Allow-Credentials %q, got %q", tt.credentials, acac)
+			}
+		})
+	}
+}
+
+func TestSetupRoutesHealthMethods(t *testing.T) {
+	t.Run("head", func(t *testing.T) {
+		cfg := config.GetDefaultConfig()
+		cfg.Server.EnableLogging = false
+		finalHandler := NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, httptest.NewRequest("HEAD", "/health", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Expected no body for HEAD, got %q", w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected Content-Type application/json, got %q", ct)
+		}
+	})
+
+	t.Run("restricted", func(t *testing.T) {
+		cfg := config.GetDefaultConfig()
+		cfg.Server.EnableLogging = false
+		cfg.Server.HealthMethods = []string{"GET"}
+		finalHandler := NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+		for method, expected := range map[string]int{
+			"GET":  http.StatusOK,
+			"HEAD": http.StatusOK,
+			"POST": http.StatusMethodNotAllowed,
+		} {
+			w := httptest.NewRecorder()
+			finalHandler.ServeHTTP(w, httptest.NewRequest(method, "/health", nil))
+
+			if w.Code != expected {
+				t.Errorf("Expected status %d for %s, got %d", expected, method, w.Code)
+			}
+		}
+	})
+}
+
+func TestSetupRoutesGlobalMethods(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.GlobalAllowedMethods = []string{"GET", "POST"}
+	finalHandler := NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+	for method, expected := range map[string]int{
+		"GET":   http.StatusOK,
+		"HEAD":  http.StatusOK,
+		"TRACE": http.StatusMethodNotAllowed,
+	} {
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, httptest.NewRequest(method, "/health", nil))
+
+		if w.Code != expected {
+			t.Errorf("Expected status %d for %s, got %d", expected, method, w.Code)
+		}
+	}
+
+	// A denied method is rejected even on routes that would otherwise accept it
+	cfg = config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.GlobalDeniedMethods = []string{"trace", "CONNECT"}
+	finalHandler = NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+	w := httptest.NewRecorder()
+	finalHandler.ServeHTTP(w, httptest.NewRequest("TRACE", "/health", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d for TRACE, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestSetupRoutesGlobalMethodsWithOverride(t *testing.T) {
+	for name, mutate := range map[string]func(s *config.ServerConfig){
+		"denied":      func(s *config.ServerConfig) { s.GlobalDeniedMethods = []string{"DELETE"} },
+		"not allowed": func(s *config.ServerConfig) { s.GlobalAllowedMethods = []string{"GET", "POST"} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := config.GetDefaultConfig()
+			cfg.Server.EnableLogging = false
+			cfg.Server.EnabledMiddleware = []string{"methodoverride"}
+			mutate(&cfg.Server)
+
+			router := NewRouter(handlers.NewHandler())
+			router.Handle("/api/items", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(r.Method))
+			})
+			finalHandler := router.SetupRoutes(cfg)
+
+			req := httptest.NewRequest("POST", "/api/items", nil)
+			req.Header.Set("X-HTTP-Method-Override", "DELETE")
+			w := httptest.NewRecorder()
+			finalHandler.ServeHTTP(w, req)
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Errorf("Expected status %d for an overridden DELETE, got %d with body %q", http.StatusMethodNotAllowed, w.Code, w.Body.String())
+			}
+
+			w = httptest.NewRecorder()
+			finalHandler.ServeHTTP(w, httptest.NewRequest("POST", "/api/items", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected a plain POST to pass, got status %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestSetupRoutesHTTP10Client(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	server := httptest.NewServer(NewRouter(handlers.NewHandler()).SetupRoutes(cfg))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial server: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// HTTP/1.0 requests may omit the Host header
+	if _, err := conn.Write([]byte("GET /health HTTP/1.0\r\n\r\n")); err != nil {
+		t.Fatalf("Failed to write request: %v", err)
+	}
+
+	// The server closes the connection after the response, so reading to EOF must succeed
+	raw, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("Expected connection to be closed after the response, got %v", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if len(resp.TransferEncoding) > 0 {
+		t.Errorf("Expected no chunked encoding for HTTP/1.0, got %v", resp.TransferEncoding)
+	}
+	if !resp.Close {
+		t.Error("Expected the response to close the connection")
+	}
+}
+
+func TestSetupRoutesStripResponseHeaders(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.StripResponseHeaders = []string{"X-Powered-By"}
+
+	router := NewRouter(handlers.NewHandler())
+	router.Handle("/legacy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Powered-By", "PHP/5.6")
+		w.Write([]byte("OK"))
+	})
+	handler := router.SetupRoutes(cfg)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/legacy", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if value := w.Header().Get("X-Powered-By"); value != "" {
+		t.Errorf("Expected X-Powered-By to be stripped, got %q", value)
+	}
+}
+
+func TestSetupRoutesVersionedHandlers(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+
+	router := NewRouter(handlers.NewHandler())
+	router.HandleVersion("/api/items", "v1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v1"))
+	})
+	router.HandleVersion("/api/items", "v2", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v2"))
+	})
+	handler := router.SetupRoutes(cfg)
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"defaults to latest", nil, http.StatusOK, "v2"},
+		{"version header", map[string]string{"X-API-Version": "1"}, http.StatusOK, "v1"},
+		{"accept header", map[string]string{"Accept": "application/vnd.phantom.v2+json"}, http.StatusOK, "v2"},
+		{"unsupported version", map[string]string{"X-API-Version": "9"}, http.StatusNotAcceptable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/items", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, w.Body.String())
+			}
+			if vary := w.Header().Values("Vary"); len(vary) == 0 {
+				t.Error("Expected Vary header on versioned route")
+			}
+		})
+	}
+}
+
+func TestSetupRoutesPathRewrites(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.PathRewrites = map[string]string{"/old/api": "/api"}
+
+	router := NewRouter(handlers.NewHandler())
+	router.Handle("/api/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("users"))
+	})
+	handler := router.SetupRoutes(cfg)
+
+	for _, path := range []string{"/old/api/users", "/api/users"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusOK, path, w.Code)
+		}
+		if w.Body.String() != "users" {
+			t.Errorf("Expected %s to be served by /api/users, got %q", path, w.Body.String())
+		}
+	}
+}
+
+func TestSetupRoutesCanonicalHostExemptsHealth(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	cfg.Server.CanonicalHost = "www.example.com"
+	finalHandler := NewRouter(handlers.NewHandler()).SetupRoutes(cfg)
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{HealthPath, http.StatusOK},
+		{"/", http.StatusMovedPermanently},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Host = "10.0.0.5:8080"
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, req)
+		if w.Code != tt.expected {
+			t.Errorf("Expected status %d for %s, got %d", tt.expected, tt.path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// closeGoingAway is an unmasked WebSocket close frame with status 1001 (going away),
+// sent by the server when it is shutting down
+var closeGoingAway = []byte{0x88, 0x02, 0x03, 0xE9}
+
+// defaultCloseTimeout bounds the close frame write when the context has no deadline
+const defaultCloseTimeout = 5 * time.Second
+
+// Registry tracks hijacked WebSocket connections so they can be closed on shutdown
+// http.Server.Shutdown does not close hijacked connections, so without this they
+// would be cut off abruptly when the process exits
+type Registry struct {
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// NewRegistry creates an empty connection registry
+func NewRegistry() *Registry {
+	return &Registry{
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// Hijack takes over the connection behind w and tracks it until release is called
+// Handlers should defer release once they are done with the connection
+func (reg *Registry) Hijack(w http.ResponseWriter) (conn net.Conn, rw *bufio.ReadWriter, release func(), err error) {
+	conn, rw, err = http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to hijack connection: %w", err)
+	}
+	return conn, rw, reg.Track(conn), nil
+}
+
+// Track registers an already hijacked connection and returns a function that untracks it
+func (reg *Registry) Track(conn net.Conn) (release func()) {
+	reg.mu.Lock()
+	reg.conns[conn] = struct{}{}
+	reg.mu.Unlock()
+
+	return func() {
+		reg.mu.Lock()
+		delete(reg.conns, conn)
+		reg.mu.Unlock()
+	}
+}
+
+// Len returns the number of tracked connections
+func (reg *Registry) Len() int {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return len(reg.conns)
+}
+
+// CloseAll sends a going-away close frame to every tracked connection and closes it
+// Writes are bounded by the context deadline. It has the ShutdownHook signature so it
+// can be run during the hooks phase of shutdown
+func (reg *Registry) CloseAll(ctx context.Context) error {
+	reg.mu.Lock()
+	conns := make([]net.Conn, 0, len(reg.conns))
+	for conn := range reg.conns {
+		conns = append(conns, conn)
+	}
+	reg.conns = make(map[net.Conn]struct{})
+	reg.mu.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultCloseTimeout)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(conns))
+	for i, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn.SetWriteDeadline(deadline)
+			if _, err := conn.Write(closeGoingAway); err != nil {
+				errs[i] = fmt.Errorf("failed to send close frame to %s: %w", conn.RemoteAddr(), err)
+			}
+			conn.Close()
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRegistryCloseAll(t *testing.T) {
+	registry := NewRegistry()
+
+	// A mock long-lived connection: the server side is tracked, the client side reads
+	server, client := net.Pipe()
+	defer client.Close()
+	registry.Track(server)
+
+	if registry.Len() != 1 {
+		t.Fatalf("Expected 1 tracked connection, got %d", registry.Len())
+	}
+
+	received := make(chan []byte, 1)
+	go func() {
+		frame := make([]byte, len(closeGoingAway))
+		if _, err := io.ReadFull(client, frame); err != nil {
+			received <- nil
+			return
+		}
+		received <- frame
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := registry.CloseAll(ctx); err != nil {
+		t.Fatalf("Expected CloseAll to succeed, got %v", err)
+	}
+
+	frame := <-received
+	if !bytes.Equal(frame, closeGoingAway) {
+		t.Errorf("Expected close frame %x, got %x", closeGoingAway, frame)
+	}
+
+	// The connection is closed after the close frame is sent
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := client.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Expected connection to be closed, got %v", err)
+	}
+
+	if registry.Len() != 0 {
+		t.Errorf("Expected no tracked connections after CloseAll, got %d", registry.Len())
+	}
+}
+
+func TestRegistryRelease(t *testing.T) {
+	registry := NewRegistry()
+
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	release := registry.Track(server)
+	release()
+
+	if registry.Len() != 0 {
+		t.Errorf("Expected released connection to be untracked, got %d", registry.Len())
+	}
+}
+
+func TestRegistryCloseAllRespectsDeadline(t *testing.T) {
+	registry := NewRegistry()
+
+	// Nobody reads from the client side, so the close frame write blocks
+	server, client := net.Pipe()
+	defer client.Close()
+	registry.Track(server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := registry.CloseAll(ctx); err == nil {
+		t.Error("Expected an error when the close frame cannot be written")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected CloseAll to give up at the deadline, took %v", elapsed)
+	}
+}
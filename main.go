@@ -2,55 +2,179 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"phantom-server/internal/config"
 	"phantom-server/internal/handlers"
+	"phantom-server/internal/middleware"
 	"phantom-server/internal/routes"
 )
 
+// memoryCheckInterval is how often heap usage is sampled when a memory limit is set
+const memoryCheckInterval = time.Second
+
 func main() {
+	// Register for shutdown signals before any startup work so a signal sent while
+	// the server is still initializing is not lost
+	sigChan := notifyShutdownSignals()
+
 	// Load configuration using priority system (env > .env > json > defaults)
-	cfg, err := loadConfiguration()
+	cfg, provenance, err := loadConfiguration()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if overridden := provenance.Overridden(); len(overridden) > 0 {
+		log.Printf("Configuration sources: %s", strings.Join(overridden, ", "))
+	}
+
+	// Render log and response timestamps in one configured time zone
+	loc := timezone(cfg.Server.Timezone)
+	middleware.SetLogLocation(loc)
+	handlers.SetTimeLayout(cfg.Server.JSONTimeLayout, loc)
 
 	// Initialize handlers, router, and middleware
 	handler := handlers.NewHandler()
+	handler.SetLocation(loc)
+	handler.LoadFlags(cfg.Server.FeatureFlags)
+	handler.SetHealthFailsOnShutdown(cfg.Server.HealthFailsOnShutdown)
+	handler.SetHealthCheckTimeout(time.Duration(cfg.Server.HealthCheckTimeoutMs) * time.Millisecond)
+	retryAfter := time.Duration(cfg.Server.RetryAfterSeconds) * time.Second
+	handler.SetShutdownRetryAfter(retryAfter)
+	handler.SetWorkerPoolSize(cfg.Server.WorkerPoolSize)
+	handler.SetMultipartLimits(handlers.MultipartLimits{
+		MaxParts: cfg.Server.MaxMultipartParts,
+		MaxBytes: cfg.Server.MaxMultipartBytes,
+	})
+	handler.SetJSONBOM(cfg.Server.JSONBOM)
+	handler.SetExplicitNulls(cfg.Server.JSONExplicitNulls)
+	if cfg.Server.JSONPretty {
+		indent := cfg.Server.JSONIndent
+		if indent == "" {
+			indent = handlers.DefaultJSONIndent
+		}
+		handler.SetJSONIndent(indent)
+	}
+	handler.SetTrustForwardedHeaders(cfg.Server.TrustForwardedHeaders)
 	router := routes.NewRouter(handler)
+	live := config.NewAtomicConfig(cfg)
+	router.SetLiveConfig(live)
+	go watchReloads(context.Background(), live)
+
+	// Shed load when heap usage crosses the configured limit
+	if cfg.Server.MemoryLimitBytes > 0 {
+		guard := middleware.NewMemoryGuard(cfg.Server.MemoryLimitBytes)
+		guard.SetRetryAfter(retryAfter)
+		go guard.Run(context.Background(), memoryCheckInterval)
+		router.SetMemoryGuard(guard)
+	}
+
+	// Track connections by state for /debug/connections
+	var connStats *middleware.ConnStats
+	if cfg.Server.EnableConnectionStats {
+		connStats = middleware.NewConnStats()
+		router.SetConnStats(connStats)
+	}
 	httpHandler := router.SetupRoutes(cfg)
 
-	// Create HTTP server with configuration timeouts
-	server := createServer(cfg, httpHandler)
+	// Create HTTP and, when configured, HTTPS servers with configuration timeouts
+	servers, err := createServers(cfg, httpHandler)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+	if connStats != nil {
+		for _, server := range servers {
+			server.ConnState = connStats.ConnState
+		}
+	}
+	logStartupBanner(cfg, servers[len(servers)-1])
 
-	// Start HTTP server with graceful shutdown handling
-	if err := startServerWithGracefulShutdown(server, cfg); err != nil {
-		log.Fatalf("Server failed: %v", err)
+	// Close hijacked WebSocket connections during shutdown, which server.Shutdown does not do
+	// Subsystems are named so ShutdownOrder can place them, e.g. before the drain phase
+	subsystems := map[string]ShutdownHook{
+		"websockets": handler.WebSockets().CloseAll,
 	}
+
+	// Start HTTP server with graceful shutdown handling, exiting with a code
+	// that distinguishes startup failures, shutdown timeouts and failed hooks
+	if err := startServerWithGracefulShutdown(sigChan, servers, cfg, handler.BeginShutdown, nil, subsystems); err != nil {
+		log.Printf("Server failed: %v", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// loadConfiguration loads configuration with priority: env > .env > json > defaults
+// The JSON file is read from CONFIG_PATH when set. A JSON load error only logs a
+// warning and falls back to defaults, unless STRICT_CONFIG=true aborts startup.
+// Setting only one of tls_cert_file and tls_key_file is always an error. A config
+// failing Validate is an error in strict mode and otherwise logs a warning.
+// CONFIG_DISALLOW_UNKNOWN_FIELDS=true makes unknown keys in the file a load error
+// The returned provenance records which source set each field
+func loadConfiguration() (*config.Config, config.Provenance, error) {
+	strict, _ := strconv.ParseBool(os.Getenv("STRICT_CONFIG"))
+	return loadConfigurationWith(strict)
 }
 
-// loadConfiguration loads configuration with priority: .env > json > defaults
-func loadConfiguration() (*config.Config, error) {
+// loadConfigurationWith is loadConfiguration with an explicit strict mode
+func loadConfigurationWith(strict bool) (*config.Config, config.Provenance, error) {
 	// Start with default configuration
 	cfg := config.GetDefaultConfig()
+	provenance := config.NewProvenance()
 
-	// Load .env file configuration
-	envCfg, err := config.LoadEnvConfig()
+	// Load JSON configuration file if one is configured
+	if configPath := os.Getenv("CONFIG_PATH"); configPath != "" {
+		var opts config.LoadOptions
+		opts.DisallowUnknownFields, _ = strconv.ParseBool(os.Getenv("CONFIG_DISALLOW_UNKNOWN_FIELDS"))
+		jsonCfg, err := config.LoadConfigWithOptions(configPath, opts)
+		if err != nil {
+			if strict {
+				return nil, nil, fmt.Errorf("failed to load JSON configuration: %w", err)
+			}
+			log.Printf("Warning: failed to load JSON configuration, using defaults: %v", err)
+		} else {
+			merged := config.MergeConfigs(cfg, jsonCfg)
+			provenance.Record(cfg, merged, config.SourceJSON)
+			cfg = merged
+		}
+	}
+
+	// Apply .env file configuration
+	envCfg, err := config.ApplyEnvConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load .env configuration: %w", err)
+		return nil, nil, fmt.Errorf("failed to load .env configuration: %w", err)
 	}
+	provenance.Record(cfg, envCfg, config.SourceDotEnv)
+	cfg = envCfg
 
-	// Merge with .env configuration (highest priority)
-	cfg = config.MergeConfigs(cfg, envCfg)
+	// Apply process environment variables (highest priority)
+	processCfg := config.ApplyProcessEnvConfig(cfg)
+	provenance.Record(cfg, processCfg, config.SourceEnv)
+	cfg = processCfg
+
+	// A certificate without its key, or the reverse, would silently serve plain HTTP
+	if err := cfg.Server.ValidateTLSFiles(); err != nil {
+		return nil, nil, err
+	}
 
-	return cfg, nil
+	// Check the same rules as a SIGHUP reload, so a config accepted at startup is
+	// not rejected by the next reload of the same file
+	if err := cfg.Validate(); err != nil {
+		if strict {
+			return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+		log.Printf("Warning: invalid configuration, set STRICT_CONFIG=true to refuse it: %v", err)
+	}
+
+	return cfg, provenance, nil
 }
 
 // createServer creates an HTTP server with configuration timeouts
@@ -62,46 +186,208 @@ func createServer(cfg *config.Config, handler http.Handler) *http.Server {
 		Handler:      handler,
 		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
-		IdleTimeout:  60 * time.Second, // Standard idle timeout
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout) * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion: minTLSVersion(cfg.Server.MinTLSVersion),
+			// listen wraps the listener itself rather than using ServeTLS, which
+			// would otherwise add these, so HTTP/2 is offered explicitly
+			NextProtos: []string{"h2", "http/1.1"},
+		},
+	}
+
+	// With a handler timeout the write deadline is set per response from its first write
+	// and cleared as each request starts, so the server-wide deadline would count handler
+	// time twice
+	if cfg.Server.HandlerTimeout > 0 {
+		server.WriteTimeout = 0
 	}
 
 	return server
 }
 
-// startServerWithGracefulShutdown starts the server and handles graceful shutdown
-func startServerWithGracefulShutdown(server *http.Server, cfg *config.Config) error {
-	// Create a channel to receive OS signals
+// createServers creates the plain HTTP server and, when HTTPSPort is set, an HTTPS
+// server using the configured certificate. The HTTPS server is last. With
+// RedirectHTTPToHTTPS the plain HTTP server only redirects to HTTPS. Without
+// HTTPSPort a configured certificate makes the single server on Port serve HTTPS
+func createServers(cfg *config.Config, handler http.Handler) ([]*http.Server, error) {
+	httpServer := createServer(cfg, handler)
+	httpServer.Addr = fmt.Sprintf(":%d", cfg.Server.HTTPListenPort())
+	if cfg.Server.HTTPSPort == 0 {
+		if cfg.Server.TLSCertFile == "" || cfg.Server.TLSKeyFile == "" {
+			return []*http.Server{httpServer}, nil
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		httpServer.Addr = fmt.Sprintf(":%d", cfg.Server.Port)
+		httpServer.TLSConfig.Certificates = []tls.Certificate{cert}
+		return []*http.Server{httpServer}, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	httpsServer := createServer(cfg, handler)
+	httpsServer.Addr = fmt.Sprintf(":%d", cfg.Server.HTTPSPort)
+	httpsServer.TLSConfig.Certificates = []tls.Certificate{cert}
+
+	if cfg.Server.RedirectHTTPToHTTPS {
+		httpServer.Handler = middleware.RedirectHTTPS(cfg.Server.HTTPSPort)
+	}
+	return []*http.Server{httpServer, httpsServer}, nil
+}
+
+// listen opens the listener for server, wrapping it in TLS when the server has a
+// certificate, capping connections when MaxConnections is set and throttling
+// accepts during the SlowStartSeconds warmup
+func listen(server *http.Server, cfg *config.Config) (net.Listener, error) {
+	ln, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Server.MaxConnections > 0 {
+		ln = limitListener(ln, cfg.Server.MaxConnections)
+	}
+	if cfg.Server.SlowStartSeconds > 0 {
+		ln = slowStartListener(ln, time.Duration(cfg.Server.SlowStartSeconds)*time.Second, cfg.Server.SlowStartRate)
+	}
+	if server.TLSConfig != nil && len(server.TLSConfig.Certificates) > 0 {
+		ln = tls.NewListener(ln, server.TLSConfig)
+	}
+	return ln, nil
+}
+
+// minTLSVersion maps a configured TLS version ("1.2" or "1.3") to its tls constant
+// Anything else, including older versions, falls back to TLS 1.2
+func minTLSVersion(version string) uint16 {
+	switch strings.TrimSpace(version) {
+	case "1.3":
+		return tls.VersionTLS13
+	case "1.2":
+		return tls.VersionTLS12
+	default:
+		log.Printf("Warning: invalid min_tls_version %q, using 1.2", version)
+		return tls.VersionTLS12
+	}
+}
+
+// timezone loads the configured IANA time zone, using local time when none is set
+// Unknown zones fall back to UTC
+func timezone(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Warning: invalid timezone %q, using UTC", name)
+		return time.UTC
+	}
+	return loc
+}
+
+// logStartupBanner logs a one-line summary of the effective settings when StartupBanner is set
+// It covers what operators most often need to confirm: port, TLS, logging, CORS and middleware
+func logStartupBanner(cfg *config.Config, server *http.Server) {
+	if !cfg.Server.StartupBanner {
+		return
+	}
+
+	tlsStatus := "off"
+	if server.TLSConfig != nil && (len(server.TLSConfig.Certificates) > 0 || server.TLSConfig.GetCertificate != nil) {
+		tlsStatus = "on"
+	}
+
+	enabled := routes.EnabledMiddlewareNames(cfg)
+	middlewareList := "none"
+	if len(enabled) > 0 {
+		middlewareList = strings.Join(enabled, ",")
+	}
+
+	log.Printf("Startup settings: port=%d tls=%s logging=%t cors_origins=%d middleware=%s",
+		cfg.Server.Port,
+		tlsStatus,
+		cfg.Server.EnableLogging,
+		len(cfg.Server.AllowedOrigins),
+		middlewareList)
+}
+
+// notifyShutdownSignals returns a channel receiving SIGINT and SIGTERM
+func notifyShutdownSignals() chan os.Signal {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	return sigChan
+}
 
-	// Start server in a goroutine
-	serverErr := make(chan error, 1)
-	go func() {
-		log.Printf("Starting HTTP server on %s", server.Addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			serverErr <- fmt.Errorf("server failed to start: %w", err)
+// startServerWithGracefulShutdown starts the servers and handles graceful shutdown
+// on a signal from sigChan. A signal that arrived during startup aborts before any
+// listener opens. beginShutdown is called as soon as a signal arrives, before any
+// shutdown phase runs. Named subsystems run where ShutdownOrder lists them, or
+// with the hooks when it does not
+func startServerWithGracefulShutdown(sigChan <-chan os.Signal, servers []*http.Server, cfg *config.Config, beginShutdown func(), hooks []ShutdownHook, subsystems map[string]ShutdownHook) error {
+	select {
+	case sig := <-sigChan:
+		log.Printf("Received signal %v during startup, exiting without serving", sig)
+		return nil
+	default:
+	}
+
+	// Own the listeners so the shutdown sequence can stop accepting connections separately from draining
+	var listeners []listener
+	for _, server := range servers {
+		ln, err := listen(server, cfg)
+		if err != nil {
+			for _, l := range listeners {
+				l.ln.Close()
+			}
+			return fmt.Errorf("server failed to start: %w", err)
 		}
-	}()
+		listeners = append(listeners, listener{server: server, ln: ln})
+	}
+
+	// Start servers in goroutines
+	serverErr := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l listener) {
+			scheme := "HTTP"
+			if l.server.TLSConfig != nil && len(l.server.TLSConfig.Certificates) > 0 {
+				scheme = "HTTPS"
+			}
+			log.Printf("Starting %s server on %s", scheme, l.server.Addr)
+			if err := l.server.Serve(l.ln); err != nil && err != http.ErrServerClosed {
+				serverErr <- fmt.Errorf("server failed to start: %w", err)
+			}
+		}(l)
+	}
 
 	// Wait for either server error or shutdown signal
 	select {
 	case err := <-serverErr:
 		return err
 	case sig := <-sigChan:
-		log.Printf("Received signal %v, initiating graceful shutdown...", sig)
+		sequence := newShutdownSequence(cfg.Server.ShutdownOrder, listeners, hooks)
+		sequence.minDrain = time.Duration(cfg.Server.MinDrainSeconds) * time.Second
+		sequence.drainKeepAlives = cfg.Server.DrainKeepAlives
+		sequence.subsystems = subsystems
+		sequence.timeouts = make(map[string]time.Duration, len(cfg.Server.ShutdownPhaseTimeouts))
+		for phase, seconds := range cfg.Server.ShutdownPhaseTimeouts {
+			sequence.timeouts[phase] = time.Duration(seconds) * time.Second
+		}
+		sequence.logf("Received signal %v, initiating graceful shutdown...", sig)
+		beginShutdown()
 
 		// Create shutdown context with timeout
 		shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeout) * time.Second
 		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer cancel()
 
-		// Attempt graceful shutdown
-		if err := server.Shutdown(ctx); err != nil {
-			log.Printf("Graceful shutdown failed: %v", err)
-			return fmt.Errorf("graceful shutdown failed: %w", err)
+		// Run the configured shutdown sequence
+		if err := sequence.run(ctx); err != nil {
+			return &ShutdownError{Err: err}
 		}
 
-		log.Println("Server shutdown completed successfully")
+		sequence.logf("Server shutdown completed successfully")
 		return nil
 	}
 }
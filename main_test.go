@@ -0,0 +1,813 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"phantom-server/internal/config"
+)
+
+func TestShutdownSequenceOrder(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+
+	server := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		}),
+	}
+	go server.Serve(ln)
+
+	var events []string
+	hook := func(ctx context.Context) error {
+		events = append(events, "hook")
+
+		// Listeners must have stopped accepting before hooks run
+		if conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond); err == nil {
+			conn.Close()
+			t.Error("Expected listener to be closed when hooks run")
+		}
+		return nil
+	}
+
+	sequence := newShutdownSequence(config.GetDefaultConfig().Server.ShutdownOrder,
+		[]listener{{server: server, ln: ln}}, []ShutdownHook{hook})
+	sequence.logf = func(format string, v ...interface{}) {
+		events = append(events, fmt.Sprintf(format, v...))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sequence.run(ctx); err != nil {
+		t.Fatalf("Expected shutdown to succeed, got %v", err)
+	}
+
+	expected := []string{
+		"Shutdown phase: stop_accepting",
+		"Shutdown phase: drain",
+		"Shutdown phase: hooks",
+		"hook",
+		"Shutdown phase: close",
+	}
+	if strings.Join(events, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestShutdownSequenceCustomOrder(t *testing.T) {
+	var events []string
+	hook := func(ctx context.Context) error {
+		events = append(events, "hook")
+		return nil
+	}
+
+	order := []string{config.ShutdownPhaseHooks, "bogus", config.ShutdownPhaseClose}
+	sequence := newShutdownSequence(order, nil, []ShutdownHook{hook})
+	sequence.logf = func(format string, v ...interface{}) {
+		events = append(events, fmt.Sprintf(format, v...))
+	}
+
+	if err := sequence.run(context.Background()); err != nil {
+		t.Fatalf("Expected shutdown to succeed, got %v", err)
+	}
+
+	expected := []string{
+		"Shutdown phase: hooks",
+		"hook",
+		"Shutdown phase: bogus",
+		`Unknown shutdown phase "bogus", skipping`,
+		"Shutdown phase: close",
+	}
+	if strings.Join(events, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestShutdownSequenceSubsystems(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}
+
+	order := []string{"listener", "metrics", "database", config.ShutdownPhaseHooks}
+	sequence := newShutdownSequence(order, nil, nil)
+	sequence.logf = func(string, ...interface{}) {}
+	sequence.subsystems = map[string]ShutdownHook{
+		"listener": func(ctx context.Context) error {
+			record("listener")
+			return nil
+		},
+		// The metrics flush hangs, so its own timeout has to cut it short
+		"metrics": func(ctx context.Context) error {
+			<-ctx.Done()
+			record("metrics timed out")
+			return ctx.Err()
+		},
+		"database": func(ctx context.Context) error {
+			deadline, _ := ctx.Deadline()
+			if remaining := time.Until(deadline); remaining > 150*time.Millisecond {
+				record("database")
+			}
+			return nil
+		},
+		"cache": func(ctx context.Context) error {
+			record("cache")
+			return nil
+		},
+	}
+	sequence.timeouts = map[string]time.Duration{"metrics": 50 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := sequence.run(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the metrics phase to stop at its own timeout, shutdown took %v", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "metrics") {
+		t.Errorf("Expected the metrics phase to time out, got %v", err)
+	}
+
+	// Unordered subsystems run in the hooks phase
+	expected := []string{"listener", "metrics timed out", "database", "cache"}
+	if strings.Join(events, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	hookErr := errors.New("hook failed")
+
+	// A hook that outlives the shutdown context surfaces as a deadline error
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	sequence := newShutdownSequence([]string{config.ShutdownPhaseHooks}, nil, []ShutdownHook{
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	sequence.logf = func(string, ...interface{}) {}
+	timeoutErr := sequence.run(ctx)
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"clean shutdown", nil, exitOK},
+		{"startup failure", fmt.Errorf("server failed to start: %w", errors.New("address in use")), exitStartupFailed},
+		{"shutdown timeout", &ShutdownError{Err: timeoutErr}, exitShutdownTimeout},
+		{"hook failure", &ShutdownError{Err: fmt.Errorf("shutdown hook failed: %w", hookErr)}, exitShutdownFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := exitCode(tt.err); code != tt.expected {
+				t.Errorf("Expected exit code %d, got %d", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestLoadConfigurationStrictMode(t *testing.T) {
+	// Run from an empty directory so no .env file is picked up
+	t.Chdir(t.TempDir())
+
+	badPath := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(badPath, []byte(`{"server": {"port": `), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	t.Run("strict mode aborts on bad file", func(t *testing.T) {
+		t.Setenv("CONFIG_PATH", badPath)
+		t.Setenv("STRICT_CONFIG", "true")
+
+		if _, _, err := loadConfiguration(); err == nil {
+			t.Error("Expected an error in strict mode")
+		}
+	})
+
+	t.Run("strict mode aborts on missing file", func(t *testing.T) {
+		t.Setenv("CONFIG_PATH", filepath.Join(t.TempDir(), "missing.json"))
+		t.Setenv("STRICT_CONFIG", "true")
+
+		if _, _, err := loadConfiguration(); err == nil {
+			t.Error("Expected an error in strict mode")
+		}
+	})
+
+	t.Run("lenient mode falls back to defaults", func(t *testing.T) {
+		t.Setenv("CONFIG_PATH", badPath)
+		t.Setenv("STRICT_CONFIG", "")
+
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		defer log.SetOutput(os.Stderr)
+
+		cfg, _, err := loadConfiguration()
+		if err != nil {
+			t.Fatalf("Expected lenient mode to succeed, got %v", err)
+		}
+		if cfg.Server.Port != config.GetDefaultConfig().Server.Port {
+			t.Errorf("Expected default port, got %d", cfg.Server.Port)
+		}
+		if !strings.Contains(buf.String(), "Warning") {
+			t.Errorf("Expected a warning to be logged, got: %s", buf.String())
+		}
+	})
+
+	t.Run("valid file is applied", func(t *testing.T) {
+		goodPath := filepath.Join(t.TempDir(), "good.json")
+		if err := os.WriteFile(goodPath, []byte(`{"server": {"port": 9123}}`), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		t.Setenv("CONFIG_PATH", goodPath)
+		t.Setenv("STRICT_CONFIG", "true")
+
+		cfg, _, err := loadConfiguration()
+		if err != nil {
+			t.Fatalf("Expected valid config to load, got %v", err)
+		}
+		if cfg.Server.Port != 9123 {
+			t.Errorf("Expected port 9123, got %d", cfg.Server.Port)
+		}
+		if !cfg.Server.EnableLogging {
+			t.Error("Expected omitted enable_logging to keep its default")
+		}
+	})
+
+	t.Run("invalid values abort in strict mode", func(t *testing.T) {
+		invalidPath := filepath.Join(t.TempDir(), "invalid.json")
+		if err := os.WriteFile(invalidPath, []byte(`{"server": {"max_connections": -1}}`), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		t.Setenv("CONFIG_PATH", invalidPath)
+		t.Setenv("STRICT_CONFIG", "true")
+
+		if _, _, err := loadConfiguration(); err == nil || !strings.Contains(err.Error(), "max_connections") {
+			t.Errorf("Expected a validation error, got %v", err)
+		}
+
+		t.Setenv("STRICT_CONFIG", "")
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		defer log.SetOutput(os.Stderr)
+
+		if _, _, err := loadConfiguration(); err != nil {
+			t.Fatalf("Expected lenient mode to succeed, got %v", err)
+		}
+		if !strings.Contains(buf.String(), "max_connections") {
+			t.Errorf("Expected a validation warning, got: %s", buf.String())
+		}
+	})
+
+	t.Run("unknown fields abort when disallowed", func(t *testing.T) {
+		typoPath := filepath.Join(t.TempDir(), "typo.json")
+		if err := os.WriteFile(typoPath, []byte(`{"server": {"prot": 9123}}`), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		t.Setenv("CONFIG_PATH", typoPath)
+		t.Setenv("STRICT_CONFIG", "true")
+		t.Setenv("CONFIG_DISALLOW_UNKNOWN_FIELDS", "true")
+
+		if _, _, err := loadConfiguration(); err == nil || !strings.Contains(err.Error(), "prot") {
+			t.Errorf("Expected an unknown field error, got %v", err)
+		}
+	})
+}
+
+func TestLoadConfigurationProvenance(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	jsonPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"server": {"port": 3000, "robots_txt": "User-agent: *\n"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("PORT=3500\nENABLE_COMPRESSION=true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .env file: %v", err)
+	}
+	t.Setenv("CONFIG_PATH", jsonPath)
+	t.Setenv("STRICT_CONFIG", "true")
+	t.Setenv("PORT", "4000")
+
+	cfg, provenance, err := loadConfiguration()
+	if err != nil {
+		t.Fatalf("Expected config to load, got %v", err)
+	}
+
+	if cfg.Server.Port != 4000 {
+		t.Errorf("Expected port 4000 from env, got %d", cfg.Server.Port)
+	}
+
+	expected := map[string]string{
+		"server.port":               config.SourceEnv,
+		"server.enable_compression": config.SourceDotEnv,
+		"server.robots_txt":         config.SourceJSON,
+		"server.enable_logging":     config.SourceDefault,
+	}
+	for field, source := range expected {
+		if provenance[field] != source {
+			t.Errorf("Expected %s to come from %q, got %q", field, source, provenance[field])
+		}
+	}
+}
+
+func TestCreateServerMinTLSVersion(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	tests := []struct {
+		version  string
+		expected uint16
+	}{
+		{"1.2", tls.VersionTLS12},
+		{"1.3", tls.VersionTLS13},
+		{"1.0", tls.VersionTLS12},
+		{"bogus", tls.VersionTLS12},
+		{"", tls.VersionTLS12},
+	}
+
+	for _, tt := range tests {
+		cfg := config.GetDefaultConfig()
+		cfg.Server.MinTLSVersion = tt.version
+
+		server := createServer(cfg, http.NotFoundHandler())
+		if server.TLSConfig == nil {
+			t.Fatalf("Version %q: expected TLS config to be set", tt.version)
+		}
+		if server.TLSConfig.MinVersion != tt.expected {
+			t.Errorf("Version %q: expected min version %x, got %x", tt.version, tt.expected, server.TLSConfig.MinVersion)
+		}
+	}
+}
+
+func TestCreateServerIdleTimeout(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	if server := createServer(cfg, http.NotFoundHandler()); server.IdleTimeout != 60*time.Second {
+		t.Errorf("Expected default idle timeout 60s, got %v", server.IdleTimeout)
+	}
+
+	cfg.Server.IdleTimeout = 120
+	if server := createServer(cfg, http.NotFoundHandler()); server.IdleTimeout != 120*time.Second {
+		t.Errorf("Expected idle timeout 120s, got %v", server.IdleTimeout)
+	}
+}
+
+func TestLogStartupBanner(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	t.Run("enabled", func(t *testing.T) {
+		buf.Reset()
+		cfg := config.GetDefaultConfig()
+		cfg.Server.Port = 9123
+		cfg.Server.EnabledMiddleware = []string{"recover", "requestid"}
+
+		logStartupBanner(cfg, createServer(cfg, http.NotFoundHandler()))
+
+		output := buf.String()
+		for _, expected := range []string{"port=9123", "tls=off", "logging=true", "cors_origins=1", "middleware=recover,requestid"} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("Expected banner to contain %q, got: %s", expected, output)
+			}
+		}
+	})
+
+	t.Run("tls on", func(t *testing.T) {
+		buf.Reset()
+		cfg := config.GetDefaultConfig()
+		server := createServer(cfg, http.NotFoundHandler())
+		server.TLSConfig.Certificates = []tls.Certificate{{}}
+
+		logStartupBanner(cfg, server)
+
+		if !strings.Contains(buf.String(), "tls=on") {
+			t.Errorf("Expected banner to report TLS on, got: %s", buf.String())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		buf.Reset()
+		cfg := config.GetDefaultConfig()
+		cfg.Server.StartupBanner = false
+
+		logStartupBanner(cfg, createServer(cfg, http.NotFoundHandler()))
+
+		if buf.Len() != 0 {
+			t.Errorf("Expected no banner, got: %s", buf.String())
+		}
+	})
+}
+
+// blockingWriter stalls every write until unblock is closed, like a wedged log sink
+type blockingWriter struct {
+	unblock chan struct{}
+}
+
+func (bw *blockingWriter) Write(p []byte) (int, error) {
+	<-bw.unblock
+	return len(p), nil
+}
+
+func TestShutdownSequenceSlowLogWriter(t *testing.T) {
+	sink := &blockingWriter{unblock: make(chan struct{})}
+	log.SetOutput(sink)
+	defer log.SetOutput(os.Stderr)
+	// The stalled write holds the logger's lock, so release it before restoring output
+	defer close(sink.unblock)
+
+	hookRan := false
+	hook := func(ctx context.Context) error {
+		hookRan = true
+		return nil
+	}
+
+	order := []string{config.ShutdownPhaseHooks, config.ShutdownPhaseClose, "bogus"}
+	sequence := newShutdownSequence(order, nil, []ShutdownHook{hook})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sequence.run(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected shutdown to succeed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected shutdown to complete despite a stalled log writer")
+	}
+
+	if !hookRan {
+		t.Error("Expected shutdown hook to run")
+	}
+}
+
+func TestBoundedLogfFastSink(t *testing.T) {
+	var mu sync.Mutex
+	var lines []string
+	logf := boundedLogf(func(format string, v ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, fmt.Sprintf(format, v...))
+	}, time.Second)
+
+	// Lines logged back to back through a healthy sink are never dropped
+	const count = 100000
+	for i := 0; i < count; i++ {
+		logf("line %d", i)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lines) != count {
+		t.Errorf("Expected %d lines to be written, got %d", count, len(lines))
+	}
+}
+
+func TestShutdownSequenceMinDrain(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+
+	server := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		}),
+	}
+	go server.Serve(ln)
+
+	const minDrain = 300 * time.Millisecond
+	sequence := newShutdownSequence(config.GetDefaultConfig().Server.ShutdownOrder,
+		[]listener{{server: server, ln: ln}}, nil)
+	sequence.minDrain = minDrain
+	sequence.logf = func(format string, v ...interface{}) {}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- sequence.run(ctx)
+	}()
+
+	// No requests are in flight, yet new connections are still served during the drain window
+	time.Sleep(minDrain / 3)
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("Expected requests to be served during the minimum drain time, got %v", err)
+	}
+	resp.Body.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Expected shutdown to succeed, got %v", err)
+	}
+	if elapsed := time.Since(sequence.start); elapsed < minDrain {
+		t.Errorf("Expected shutdown to take at least %v, took %v", minDrain, elapsed)
+	}
+}
+
+func TestShutdownSequenceMinDrainTimeout(t *testing.T) {
+	sequence := newShutdownSequence(config.GetDefaultConfig().Server.ShutdownOrder, nil, nil)
+	sequence.minDrain = time.Minute
+	sequence.logf = func(format string, v ...interface{}) {}
+
+	// The shutdown deadline still bounds the wait
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	sequence.run(ctx)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the shutdown deadline to cut the drain wait short, took %v", elapsed)
+	}
+}
+
+func TestShutdownSequenceDrainKeepAlives(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				close(started)
+				<-release
+			}
+			w.Write([]byte("OK"))
+		}),
+	}
+	go server.Serve(ln)
+
+	// Leave a keep-alive connection idle after one request
+	idle, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer idle.Close()
+	if _, err := idle.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n")); err != nil {
+		t.Fatalf("Failed to write request: %v", err)
+	}
+	reader := bufio.NewReader(idle)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	// Keep one request in flight for the drain to wait on
+	transport := &http.Transport{}
+	defer transport.CloseIdleConnections()
+	slow := make(chan *http.Response, 1)
+	go func() {
+		client := &http.Client{Transport: transport}
+		resp, err := client.Get("http://" + addr + "/slow")
+		if err != nil {
+			slow <- nil
+			return
+		}
+		resp.Body.Close()
+		slow <- resp
+	}()
+	<-started
+
+	const minDrain = 300 * time.Millisecond
+	sequence := newShutdownSequence(config.GetDefaultConfig().Server.ShutdownOrder,
+		[]listener{{server: server, ln: ln}}, nil)
+	sequence.minDrain = minDrain
+	sequence.drainKeepAlives = true
+	sequence.logf = func(format string, v ...interface{}) {}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- sequence.run(ctx)
+	}()
+
+	// The idle connection is closed right away, without waiting for the drain window
+	idle.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := reader.ReadByte(); err != io.EOF {
+		t.Fatalf("Expected the idle connection to be closed, got %v", err)
+	}
+	if elapsed := time.Since(sequence.start); elapsed >= minDrain {
+		t.Errorf("Expected the idle connection to close before the drain window ended, took %v", elapsed)
+	}
+
+	close(release)
+	resp = <-slow
+	if resp == nil {
+		t.Fatal("Expected the in-flight request to complete")
+	}
+	if !resp.Close {
+		t.Error("Expected the in-flight response to carry Connection: close")
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("Expected shutdown to succeed, got %v", err)
+	}
+}
+
+func TestStartServerSignalDuringStartup(t *testing.T) {
+	// Register first, as main does, then signal before the server starts
+	sigChan := notifyShutdownSignals()
+	defer signal.Stop(sigChan)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("Failed to send signal: %v", err)
+	}
+
+	// Give the runtime time to deliver the signal, as slow startup work would
+	select {
+	case sig := <-sigChan:
+		sigChan <- sig
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the signal to be delivered")
+	}
+
+	cfg := config.GetDefaultConfig()
+	server := &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()}
+	began := false
+
+	done := make(chan error, 1)
+	go func() {
+		done <- startServerWithGracefulShutdown(sigChan, []*http.Server{server}, cfg, func() { began = true }, nil, nil)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected a clean exit, got %v", err)
+		}
+		if exitCode(err) != exitOK {
+			t.Errorf("Expected exit code %d, got %d", exitOK, exitCode(err))
+		}
+		if began {
+			t.Error("Expected startup to abort before shutdown began")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected startup to abort after an early signal")
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	if loc := timezone(""); loc != time.Local {
+		t.Errorf("Expected local time when no timezone is set, got %v", loc)
+	}
+
+	loc := timezone("Asia/Tokyo")
+	if loc.String() != "Asia/Tokyo" {
+		t.Errorf("Expected Asia/Tokyo, got %v", loc)
+	}
+	if got := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC).In(loc).Format(time.RFC3339); got != "2024-01-01T09:00:00+09:00" {
+		t.Errorf("Expected timestamp rendered in Asia/Tokyo, got %s", got)
+	}
+
+	if loc := timezone("Mars/Olympus_Mons"); loc != time.UTC {
+		t.Errorf("Expected UTC fallback for an invalid timezone, got %v", loc)
+	}
+	if !strings.Contains(buf.String(), "invalid timezone") {
+		t.Errorf("Expected a warning for an invalid timezone, got: %s", buf.String())
+	}
+}
+
+func TestLoadConfigurationTLSFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("CONFIG_PATH", "")
+
+	t.Run("certificate without key", func(t *testing.T) {
+		t.Setenv("TLS_CERT_FILE", "cert.pem")
+		t.Setenv("TLS_KEY_FILE", "")
+
+		if _, _, err := loadConfiguration(); err == nil || !strings.Contains(err.Error(), "tls_cert_file and tls_key_file") {
+			t.Errorf("Expected a TLS file pair error, got %v", err)
+		}
+	})
+
+	t.Run("key without certificate", func(t *testing.T) {
+		t.Setenv("TLS_CERT_FILE", "")
+		t.Setenv("TLS_KEY_FILE", "key.pem")
+
+		if _, _, err := loadConfiguration(); err == nil {
+			t.Error("Expected a TLS file pair error")
+		}
+	})
+
+	t.Run("both set", func(t *testing.T) {
+		t.Setenv("TLS_CERT_FILE", "cert.pem")
+		t.Setenv("TLS_KEY_FILE", "key.pem")
+
+		cfg, _, err := loadConfiguration()
+		if err != nil {
+			t.Fatalf("Expected config to load, got %v", err)
+		}
+		if cfg.Server.TLSCertFile != "cert.pem" || cfg.Server.TLSKeyFile != "key.pem" {
+			t.Errorf("Expected TLS files from env, got %q and %q", cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		}
+	})
+}
+
+func TestStartServerTLSOnMainPort(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := config.GetDefaultConfig()
+	cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile = writeSelfSignedCert(t)
+	cfg.Server.ShutdownTimeout = 5
+
+	servers, err := createServers(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create servers: %v", err)
+	}
+	if len(servers) != 1 {
+		t.Fatalf("Expected a single server without https_port, got %d", len(servers))
+	}
+	if servers[0].Addr != fmt.Sprintf(":%d", cfg.Server.Port) {
+		t.Errorf("Expected HTTPS on port %d, got %s", cfg.Server.Port, servers[0].Addr)
+	}
+
+	// Serve on a free port instead of the configured one
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	servers[0].Addr = probe.Addr().String()
+	probe.Close()
+
+	sigChan := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- startServerWithGracefulShutdown(sigChan, servers, cfg, func() {}, nil, nil)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, ForceAttemptHTTP2: true}}
+	var resp *http.Response
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if resp, err = client.Get("https://" + servers[0].Addr + "/"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("Expected an HTTPS response, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.TLS == nil {
+		t.Error("Expected the response to be served over TLS")
+	}
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2 to be negotiated, got %s", resp.Proto)
+	}
+
+	// Graceful shutdown works the same as for plain HTTP
+	sigChan <- syscall.SIGTERM
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the TLS server to shut down")
+	}
+}
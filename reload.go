@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"phantom-server/internal/config"
+)
+
+// reloadConfiguration loads the configuration again and swaps it into live
+// The new config is loaded in strict mode, so it must load without errors and
+// pass Validate, otherwise the error is returned and the running config is left untouched. Only settings
+// read through the live config, such as rate limits, take effect without a restart
+func reloadConfiguration(live *config.AtomicConfig) error {
+	// A reload never falls back to defaults, which would silently drop the running settings
+	cfg, _, err := loadConfigurationWith(true)
+	if err != nil {
+		return fmt.Errorf("failed to reload configuration, keeping current settings: %w", err)
+	}
+
+	live.Store(cfg)
+	return nil
+}
+
+// watchReloads reloads the configuration on every SIGHUP until ctx is done
+func watchReloads(ctx context.Context, live *config.AtomicConfig) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigChan:
+			if err := reloadConfiguration(live); err != nil {
+				log.Printf("Configuration reload failed: %v", err)
+				continue
+			}
+			log.Println("Configuration reloaded")
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"phantom-server/internal/config"
+	"phantom-server/internal/handlers"
+	"phantom-server/internal/routes"
+)
+
+func TestReloadConfigurationRejectsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	jsonPath := filepath.Join(dir, "config.json")
+	t.Setenv("CONFIG_PATH", jsonPath)
+
+	cfg := config.GetDefaultConfig()
+	cfg.Server.EnableLogging = false
+	live := config.NewAtomicConfig(cfg)
+
+	router := routes.NewRouter(handlers.NewHandler())
+	router.SetLiveConfig(live)
+	finalHandler := router.SetupRoutes(cfg)
+
+	send := func() int {
+		w := httptest.NewRecorder()
+		finalHandler.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+		return w.Code
+	}
+
+	writeConfig := func(content string) {
+		if err := os.WriteFile(jsonPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+	}
+
+	// An invalid port fails validation, so the rate limit in the same file must not apply
+	writeConfig(`{"server": {"port": 70000, "rate_limit_requests": 1}}`)
+	if err := reloadConfiguration(live); err == nil {
+		t.Fatal("Expected reload of invalid config to fail")
+	}
+	if live.Load() != cfg {
+		t.Error("Expected live config to be unchanged after a rejected reload")
+	}
+	for i := 0; i < 3; i++ {
+		if code := send(); code != http.StatusOK {
+			t.Fatalf("Expected old settings to keep serving, got status %d", code)
+		}
+	}
+
+	// Malformed JSON is rejected rather than replaced with defaults
+	writeConfig(`{"server": `)
+	if err := reloadConfiguration(live); err == nil {
+		t.Fatal("Expected reload of malformed config to fail")
+	}
+
+	// A valid config is swapped in
+	writeConfig(`{"server": {"rate_limit_requests": 1}}`)
+	if err := reloadConfiguration(live); err != nil {
+		t.Fatalf("Expected valid reload to succeed, got %v", err)
+	}
+	if code := send(); code != http.StatusOK {
+		t.Errorf("Expected first request after reload to pass, got %d", code)
+	}
+	if code := send(); code != http.StatusTooManyRequests {
+		t.Errorf("Expected reloaded rate limit to apply, got %d", code)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"phantom-server/internal/config"
+)
+
+// writeSelfSignedCert writes a self-signed certificate for 127.0.0.1 and returns the cert and key paths
+func writeSelfSignedCert(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+// serveAll starts every server on a random local port and returns their base URLs
+func serveAll(t *testing.T, cfg *config.Config, servers []*http.Server) []string {
+	t.Helper()
+
+	var urls []string
+	for _, server := range servers {
+		server.Addr = "127.0.0.1:0"
+		ln, err := listen(server, cfg)
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		go server.Serve(ln)
+		t.Cleanup(func() { server.Close() })
+
+		scheme := "http"
+		if len(server.TLSConfig.Certificates) > 0 {
+			scheme = "https"
+		}
+		urls = append(urls, scheme+"://"+ln.Addr().String())
+	}
+	return urls
+}
+
+func TestCreateServersHTTPAndHTTPS(t *testing.T) {
+	certPath, keyPath := writeSelfSignedCert(t)
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	newConfig := func(redirect bool) *config.Config {
+		cfg := config.GetDefaultConfig()
+		cfg.Server.HTTPSPort = 8443
+		cfg.Server.TLSCertFile = certPath
+		cfg.Server.TLSKeyFile = keyPath
+		cfg.Server.RedirectHTTPToHTTPS = redirect
+		return cfg
+	}
+
+	t.Run("both listeners respond", func(t *testing.T) {
+		cfg := newConfig(false)
+		servers, err := createServers(cfg, ok)
+		if err != nil {
+			t.Fatalf("Failed to create servers: %v", err)
+		}
+		if len(servers) != 2 {
+			t.Fatalf("Expected 2 servers, got %d", len(servers))
+		}
+
+		for _, url := range serveAll(t, cfg, servers) {
+			resp, err := client.Get(url + "/")
+			if err != nil {
+				t.Fatalf("Request to %s failed: %v", url, err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected status %d from %s, got %d", http.StatusOK, url, resp.StatusCode)
+			}
+			if strings.HasPrefix(url, "https") != (resp.TLS != nil) {
+				t.Errorf("Expected TLS only on the HTTPS listener, %s had TLS %v", url, resp.TLS != nil)
+			}
+		}
+	})
+
+	t.Run("HTTP redirects to HTTPS", func(t *testing.T) {
+		cfg := newConfig(true)
+		servers, err := createServers(cfg, ok)
+		if err != nil {
+			t.Fatalf("Failed to create servers: %v", err)
+		}
+		urls := serveAll(t, cfg, servers)
+
+		resp, err := client.Get(urls[0] + "/path?q=1")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusPermanentRedirect {
+			t.Errorf("Expected status %d, got %d", http.StatusPermanentRedirect, resp.StatusCode)
+		}
+		if location := resp.Header.Get("Location"); location != "https://127.0.0.1:8443/path?q=1" {
+			t.Errorf("Expected redirect to the HTTPS port, got %q", location)
+		}
+	})
+
+	t.Run("HTTP only by default", func(t *testing.T) {
+		servers, err := createServers(config.GetDefaultConfig(), ok)
+		if err != nil {
+			t.Fatalf("Failed to create servers: %v", err)
+		}
+		if len(servers) != 1 || servers[0].Addr != ":8080" {
+			t.Errorf("Expected a single HTTP server on :8080, got %d servers", len(servers))
+		}
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		cfg := newConfig(false)
+		cfg.Server.TLSCertFile = filepath.Join(t.TempDir(), "missing.pem")
+		if _, err := createServers(cfg, ok); err == nil {
+			t.Error("Expected an error for a missing certificate")
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"phantom-server/internal/config"
+)
+
+// Process exit codes reported to the orchestrator
+const (
+	exitOK              = 0 // Clean graceful shutdown
+	exitStartupFailed   = 1 // Server failed to start or serve
+	exitShutdownTimeout = 2 // Shutdown did not finish within ShutdownTimeout
+	exitShutdownFailed  = 3 // A shutdown phase or hook failed
+)
+
+// ShutdownError reports that the server started but did not shut down cleanly
+type ShutdownError struct {
+	Err error
+}
+
+func (e *ShutdownError) Error() string {
+	return "graceful shutdown failed: " + e.Err.Error()
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// exitCode maps the error returned by startServerWithGracefulShutdown to a process exit code
+func exitCode(err error) int {
+	var shutdownErr *ShutdownError
+	switch {
+	case err == nil:
+		return exitOK
+	case !errors.As(err, &shutdownErr):
+		return exitStartupFailed
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitShutdownTimeout
+	default:
+		return exitShutdownFailed
+	}
+}
+
+// ShutdownHook is a function run during the hooks phase of shutdown
+type ShutdownHook func(ctx context.Context) error
+
+// listener pairs an HTTP server with the network listener it serves on
+type listener struct {
+	server *http.Server
+	ln     net.Listener
+}
+
+// shutdownSequence runs the shutdown phases for a set of listeners and hooks
+// in the order configured by ServerConfig.ShutdownOrder
+type shutdownSequence struct {
+	listeners []listener
+	hooks     []ShutdownHook
+	order     []string
+	logf      func(format string, v ...interface{})
+
+	// subsystems are named hooks, such as "database", that ShutdownOrder can list
+	// as phases of their own. Subsystems it does not list run in the hooks phase
+	subsystems map[string]ShutdownHook
+
+	// timeouts bounds individual phases by name, each a slice of the overall
+	// shutdown budget. Phases without one share whatever budget remains
+	timeouts map[string]time.Duration
+
+	// minDrain keeps the listeners serving for at least this long after the
+	// sequence is created, so connections a load balancer still routes here
+	// after the signal are not refused. Readiness is already failing by then
+	minDrain time.Duration
+	start    time.Time
+
+	// drainKeepAlives disables keep-alives before the first phase, so idle
+	// connections are closed right away and responses carry Connection: close.
+	// The drain then only waits on requests that are actually in flight
+	drainKeepAlives bool
+}
+
+// shutdownLogTimeout bounds how long shutdown waits on a single log line
+const shutdownLogTimeout = 100 * time.Millisecond
+
+// newShutdownSequence creates a shutdown sequence that logs through the standard logger
+// Logging is bounded by shutdownLogTimeout so a stalled log sink cannot hold up shutdown
+func newShutdownSequence(order []string, listeners []listener, hooks []ShutdownHook) *shutdownSequence {
+	return &shutdownSequence{
+		listeners: listeners,
+		hooks:     hooks,
+		order:     order,
+		logf:      boundedLogf(log.Printf, shutdownLogTimeout),
+		start:     time.Now(),
+	}
+}
+
+// boundedLogf wraps logf so each call waits at most timeout for the write to finish
+// While a write is still stalled, further lines are dropped rather than queued
+// behind it, so a blocked sink costs at most one timeout in total
+func boundedLogf(logf func(format string, v ...interface{}), timeout time.Duration) func(format string, v ...interface{}) {
+	var stalled atomic.Bool
+	return func(format string, v ...interface{}) {
+		if !stalled.CompareAndSwap(false, true) {
+			return
+		}
+
+		done := make(chan struct{})
+		go func() {
+			// Clear stalled before signalling done, so the next line is never dropped
+			defer func() {
+				stalled.Store(false)
+				close(done)
+			}()
+			logf(format, v...)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(timeout):
+		}
+	}
+}
+
+// run executes each configured phase in order, logging at the start of every phase
+// Each phase is bounded by its own timeout when one is set, within ctx's deadline.
+// Errors are collected so later phases still run, and are returned joined together
+func (s *shutdownSequence) run(ctx context.Context) error {
+	var errs []error
+	waited := false
+
+	if s.drainKeepAlives {
+		s.disableKeepAlives()
+	}
+
+	for _, phase := range s.order {
+		s.logf("Shutdown phase: %s", phase)
+
+		// Keep serving for the minimum drain time before connections are first refused
+		if !waited && (phase == config.ShutdownPhaseStopAccepting || phase == config.ShutdownPhaseDrain || phase == config.ShutdownPhaseClose) {
+			waited = true
+			s.waitMinDrain(ctx)
+		}
+
+		phaseCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout := s.timeouts[phase]; timeout > 0 {
+			phaseCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		errs = append(errs, s.runPhase(phaseCtx, phase)...)
+		cancel()
+	}
+
+	return errors.Join(errs...)
+}
+
+// runPhase runs a single phase and returns the errors it produced
+func (s *shutdownSequence) runPhase(ctx context.Context, phase string) []error {
+	var errs []error
+
+	switch phase {
+	case config.ShutdownPhaseStopAccepting:
+		for _, l := range s.listeners {
+			if err := l.ln.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				errs = append(errs, fmt.Errorf("failed to close listener %s: %w", l.ln.Addr(), err))
+			}
+		}
+	case config.ShutdownPhaseDrain:
+		for _, l := range s.listeners {
+			// The listener may already be closed by the stop_accepting phase
+			if err := l.server.Shutdown(ctx); err != nil && !errors.Is(err, net.ErrClosed) {
+				errs = append(errs, fmt.Errorf("failed to drain %s: %w", l.server.Addr, err))
+			}
+		}
+	case config.ShutdownPhaseHooks:
+		for _, hook := range s.hooks {
+			if err := hook(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("shutdown hook failed: %w", err))
+			}
+		}
+		for _, name := range s.unorderedSubsystems() {
+			if err := s.subsystems[name](ctx); err != nil {
+				errs = append(errs, fmt.Errorf("shutdown of %s failed: %w", name, err))
+			}
+		}
+	case config.ShutdownPhaseClose:
+		for _, l := range s.listeners {
+			if err := l.server.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				errs = append(errs, fmt.Errorf("failed to close %s: %w", l.server.Addr, err))
+			}
+		}
+	default:
+		stop, exists := s.subsystems[phase]
+		if !exists {
+			s.logf("Unknown shutdown phase %q, skipping", phase)
+			break
+		}
+		if err := stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown of %s failed: %w", phase, err))
+		}
+	}
+
+	return errs
+}
+
+// disableKeepAlives stops every server from reusing connections and closes the idle ones
+// Connections with a request in flight are closed once their response is written
+func (s *shutdownSequence) disableKeepAlives() {
+	s.logf("Disabling keep-alives, closing idle connections")
+	for _, l := range s.listeners {
+		l.server.SetKeepAlivesEnabled(false)
+	}
+}
+
+// unorderedSubsystems returns the subsystems ShutdownOrder does not list, sorted by name
+func (s *shutdownSequence) unorderedSubsystems() []string {
+	ordered := make(map[string]bool, len(s.order))
+	for _, phase := range s.order {
+		ordered[phase] = true
+	}
+
+	var names []string
+	for name := range s.subsystems {
+		if !ordered[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// waitMinDrain blocks until the minimum drain time has passed since the sequence
+// started, returning early if ctx is done
+func (s *shutdownSequence) waitMinDrain(ctx context.Context) {
+	remaining := s.minDrain - time.Since(s.start)
+	if remaining <= 0 {
+		return
+	}
+
+	s.logf("Waiting %v before stopping, minimum drain time is %v", remaining.Round(time.Millisecond), s.minDrain)
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// defaultSlowStartRate is the initial accept rate, in connections per second, when SlowStartRate is unset
+const defaultSlowStartRate = 10
+
+// slowStartListener returns a listener that throttles Accept during a warmup period
+// It starts by accepting rate connections per second and shortens the gap between
+// accepts linearly until it reaches zero once period has passed since startup
+// Connections waiting their turn stay in the kernel's accept queue
+func slowStartListener(ln net.Listener, period time.Duration, rate int) net.Listener {
+	if rate <= 0 {
+		rate = defaultSlowStartRate
+	}
+	return &rampListener{
+		Listener: ln,
+		start:    time.Now(),
+		period:   period,
+		interval: time.Second / time.Duration(rate),
+		done:     make(chan struct{}),
+	}
+}
+
+// rampListener spaces out accepted connections until its warmup period is over
+type rampListener struct {
+	net.Listener
+	start     time.Time
+	period    time.Duration
+	interval  time.Duration // Gap between accepts at the start of the warmup
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu   sync.Mutex
+	next time.Time // Earliest time the next connection may be accepted
+}
+
+// gap returns the time to wait between accepts at now, zero once the warmup is over
+func (l *rampListener) gap(now time.Time) time.Duration {
+	elapsed := now.Sub(l.start)
+	if elapsed >= l.period {
+		return 0
+	}
+	remaining := float64(l.period-elapsed) / float64(l.period)
+	return time.Duration(float64(l.interval) * remaining)
+}
+
+func (l *rampListener) Accept() (net.Conn, error) {
+	// Wait for this connection's turn, giving up once the listener is closed
+	l.mu.Lock()
+	wait := time.Until(l.next)
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-l.done:
+			timer.Stop()
+			l.mu.Unlock()
+			return nil, net.ErrClosed
+		}
+	}
+	l.mu.Unlock()
+
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	l.next = now.Add(l.gap(now))
+	l.mu.Unlock()
+	return conn, nil
+}
+
+func (l *rampListener) Close() error {
+	err := l.Listener.Close()
+	l.closeOnce.Do(func() { close(l.done) })
+	return err
+}
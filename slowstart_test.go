@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// acceptN dials n connections to ln and returns how long it took to accept them all
+func acceptN(t *testing.T, ln net.Listener, n int) time.Duration {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		client, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("Failed to dial connection %d: %v", i+1, err)
+		}
+		defer client.Close()
+	}
+
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Fatalf("Failed to accept connection %d: %v", i+1, err)
+		}
+		conn.Close()
+	}
+	return time.Since(start)
+}
+
+func TestSlowStartListener(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	const period = 400 * time.Millisecond
+	ln := slowStartListener(inner, period, 10)
+	defer ln.Close()
+
+	// At 10 connections per second the first accepts are spaced about 100ms apart
+	if elapsed := acceptN(t, ln, 3); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected early connections to be throttled, accepted 3 in %v", elapsed)
+	}
+
+	// Once the warmup is over connections are accepted without delay
+	time.Sleep(period)
+	acceptN(t, ln, 1)
+	if elapsed := acceptN(t, ln, 5); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected connections after warmup to be accepted immediately, took %v", elapsed)
+	}
+}
+
+func TestSlowStartListenerClose(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ln := slowStartListener(inner, time.Minute, 1)
+	acceptN(t, ln, 1)
+
+	// An Accept waiting for its turn returns once the listener is closed
+	result := make(chan error, 1)
+	go func() {
+		_, err := ln.Accept()
+		result <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ln.Close()
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Error("Expected Accept to fail after Close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Accept to return after Close")
+	}
+}